@@ -0,0 +1,130 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/fatih/structs"
+	"github.com/shopspring/decimal"
+)
+
+// A cancel-replace that stopped at one of its legs. When Leg is LEG_VALIDATE
+// the replacement was rejected before anything was sent and the original order
+// is still resting; when it's LEG_CANCEL nothing was placed and the original
+// order may still be resting; when it's LEG_PLACE the original order is gone
+// and no replacement is resting.
+type CancelReplaceError struct {
+	Leg     string // LEG_VALIDATE, LEG_CANCEL or LEG_PLACE
+	OrderId string // the order being replaced
+	Err     error
+}
+
+func (e *CancelReplaceError) Error() string {
+	return fmt.Sprintf("bybit: cancel-replace of order %v failed at %v: %v", e.OrderId, e.Leg, e.Err)
+}
+
+func (e *CancelReplaceError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Amends an active perp order in place, keeping its queue position when only
+	the quantity goes down. Orders are looked up by OrderId, or by OrderLinkId
+	when it's empty. With WithOrderValidation the new values are rounded first.
+
+	Requires:
+		params AmendPerpOrderParams
+
+	Returns:
+		orderId string
+		err error - wraps ErrOrderNotFound when the order was filled or cancelled
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-replaceactive
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-replaceactive
+*/
+func (bybit *BybitExchange) AmendPerpOrder(params AmendPerpOrderParams) (orderId string, err error) {
+	return bybit.AmendPerpOrderCtx(context.Background(), params)
+}
+
+// Same as AmendPerpOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) AmendPerpOrderCtx(ctx context.Context, params AmendPerpOrderParams) (orderId string, err error) {
+	functionName := "AmendPerpOrder"
+	if params.OrderId == "" && params.OrderLinkId == "" {
+		return orderId, fmt.Errorf("%v failed: no order id or order link id", functionName)
+	}
+	if bybit.orderValidation != nil {
+		if params, err = bybit.validatePerpAmend(ctx, params); err != nil {
+			return orderId, err
+		}
+	}
+	isLinear, err := bybit.isLinear(ctx, params.Symbol)
+	if err != nil {
+		return orderId, err
+	}
+	endpoint := REPLACE_PERP_ORDER
+	if isLinear {
+		endpoint = REPLACE_LINEAR_ORDER
+	}
+	params_map := structs.Map(&params)
+
+	// create request
+	req := newSignedRequest(http.MethodPost, endpoint, params_map)
+
+	var response = new(ResponseForAmendPerpOrder)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return orderId, err
+	}
+
+	return response.Result.OrderId, err
+}
+
+/*
+	Replaces a resting spot order, which bybit can't amend. The replacement is
+	only placed once the cancel is confirmed, so both orders are never resting
+	at once. Quantity the original order filled before the cancel is reported
+	rather than taken off the replacement.
+
+	Requires:
+		orderId string - the order to replace
+		params PlaceSpotOrderParams - the replacement
+
+	Returns:
+		result CancelReplaceResult
+		err error - a *CancelReplaceError naming the leg that failed
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-cancelactive
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-placeactive
+*/
+func (bybit *BybitExchange) CancelReplaceSpotOrder(orderId string, params PlaceSpotOrderParams) (result CancelReplaceResult, err error) {
+	return bybit.CancelReplaceSpotOrderCtx(context.Background(), orderId, params)
+}
+
+// Same as CancelReplaceSpotOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) CancelReplaceSpotOrderCtx(ctx context.Context, orderId string, params PlaceSpotOrderParams) (result CancelReplaceResult, err error) {
+	functionName := "CancelReplaceSpotOrder"
+	// reject an invalid replacement before giving up the original order
+	if bybit.orderValidation != nil {
+		if params, err = bybit.ValidateSpotOrderCtx(ctx, params); err != nil {
+			return result, &CancelReplaceError{Leg: LEG_VALIDATE, OrderId: orderId, Err: err}
+		}
+	}
+
+	cancelParams := map[string]interface{}{}
+	cancelParams["orderId"] = orderId
+
+	// create request
+	req := newSignedRequest(http.MethodDelete, SPOT_ORDER, cancelParams)
+
+	var response = new(ResponseForCancelSpotOrder)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return result, &CancelReplaceError{Leg: LEG_CANCEL, OrderId: orderId, Err: err}
+	}
+	result.CancelledOrderId = orderId
+	result.ExecutedQty, _ = decimal.NewFromString(response.Result.ExecutedQty)
+
+	if result.OrderId, err = bybit.PlaceSpotOrderCtx(ctx, params); err != nil {
+		return result, &CancelReplaceError{Leg: LEG_PLACE, OrderId: orderId, Err: err}
+	}
+	return result, nil
+}
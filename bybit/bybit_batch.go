@@ -0,0 +1,267 @@
+package bybit_exchange
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+	Places spot orders concurrently, BATCH_ORDER_CONCURRENCY at a time, each
+	the way PlaceSpotOrder does. One order failing doesn't stop the others.
+
+	Requires:
+		params []PlaceSpotOrderParams
+
+	Returns:
+		results []BatchOrderResult - in the order of params, OrderLinkId is always set
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-placeactive
+*/
+func (bybit *BybitExchange) PlaceSpotOrders(params []PlaceSpotOrderParams) (results []BatchOrderResult) {
+	return bybit.PlaceSpotOrdersCtx(context.Background(), params)
+}
+
+// Same as PlaceSpotOrders, cancelled or timed out through ctx
+func (bybit *BybitExchange) PlaceSpotOrdersCtx(ctx context.Context, params []PlaceSpotOrderParams) (results []BatchOrderResult) {
+	params = append([]PlaceSpotOrderParams(nil), params...)
+	results = make([]BatchOrderResult, len(params))
+	for i := range params {
+		if params[i].OrderLinkId == "" {
+			params[i].OrderLinkId = newOrderLinkId()
+		}
+		results[i].OrderLinkId = params[i].OrderLinkId
+	}
+
+	fanOut(len(params), func(i int) {
+		results[i].OrderId, results[i].Err = bybit.PlaceSpotOrderCtx(ctx, params[i])
+	})
+	return results
+}
+
+/*
+	Places perp orders concurrently, BATCH_ORDER_CONCURRENCY at a time, each
+	the way PlacePerpOrder does. One order failing doesn't stop the others.
+
+	Requires:
+		params []PlacePerpOrderParams
+
+	Returns:
+		results []BatchOrderResult - in the order of params, OrderLinkId is always set
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-activeorders
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-placeactive
+*/
+func (bybit *BybitExchange) PlacePerpOrders(params []PlacePerpOrderParams) (results []BatchOrderResult) {
+	return bybit.PlacePerpOrdersCtx(context.Background(), params)
+}
+
+// Same as PlacePerpOrders, cancelled or timed out through ctx
+func (bybit *BybitExchange) PlacePerpOrdersCtx(ctx context.Context, params []PlacePerpOrderParams) (results []BatchOrderResult) {
+	params = append([]PlacePerpOrderParams(nil), params...)
+	results = make([]BatchOrderResult, len(params))
+	for i := range params {
+		if params[i].OrderLinkId == "" {
+			params[i].OrderLinkId = newOrderLinkId()
+		}
+		results[i].OrderLinkId = params[i].OrderLinkId
+	}
+
+	fanOut(len(params), func(i int) {
+		results[i].OrderId, results[i].Err = bybit.PlacePerpOrderCtx(ctx, params[i])
+	})
+	return results
+}
+
+/*
+	Cancels every active order of a perp. Conditional orders are left alone.
+
+	Requires:
+		symbol string
+
+	Returns:
+		orderIds []string - the cancelled orders
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-cancelallactive
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-cancelallactive
+*/
+func (bybit *BybitExchange) CancelAllPerpOrders(symbol string) (orderIds []string, err error) {
+	return bybit.CancelAllPerpOrdersCtx(context.Background(), symbol)
+}
+
+// Same as CancelAllPerpOrders, cancelled or timed out through ctx
+func (bybit *BybitExchange) CancelAllPerpOrdersCtx(ctx context.Context, symbol string) (orderIds []string, err error) {
+	functionName := "CancelAllPerpOrders"
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return orderIds, err
+	}
+	if isLinear {
+		req := newSignedRequest(http.MethodPost, CANCEL_ALL_LINEAR_ORDERS, params)
+		// cancelling everything twice is harmless
+		req.idempotent = true
+		var response = new(ResponseForCancelAllLinearOrders)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return orderIds, err
+		}
+		return response.Result, nil
+	}
+
+	// create request
+	req := newSignedRequest(http.MethodPost, CANCEL_ALL_PERP_ORDERS, params)
+	req.idempotent = true
+
+	var response = new(ResponseForCancelAllPerpOrders)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return orderIds, err
+	}
+
+	for _, order := range response.Result {
+		orderIds = append(orderIds, order.OrderId)
+	}
+	return orderIds, nil
+}
+
+/*
+	Cancels every active order of a spot pair
+
+	Requires:
+		symbol string
+
+	Returns:
+		status bool
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-batchcancelactiveorder
+*/
+func (bybit *BybitExchange) CancelAllSpotOrders(symbol string) (status bool, err error) {
+	return bybit.CancelAllSpotOrdersCtx(context.Background(), symbol)
+}
+
+// Same as CancelAllSpotOrders, cancelled or timed out through ctx
+func (bybit *BybitExchange) CancelAllSpotOrdersCtx(ctx context.Context, symbol string) (status bool, err error) {
+	return bybit.CancelSpotOrdersCtx(ctx, BatchCancelSpotParams{Symbol: symbol})
+}
+
+/*
+	Cancels the active orders of a spot pair matching a side and order types
+
+	Requires:
+		params BatchCancelSpotParams
+
+	Returns:
+		status bool
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-batchcancelactiveorder
+*/
+func (bybit *BybitExchange) CancelSpotOrders(params BatchCancelSpotParams) (status bool, err error) {
+	return bybit.CancelSpotOrdersCtx(context.Background(), params)
+}
+
+// Same as CancelSpotOrders, cancelled or timed out through ctx
+func (bybit *BybitExchange) CancelSpotOrdersCtx(ctx context.Context, params BatchCancelSpotParams) (status bool, err error) {
+	functionName := "CancelSpotOrders"
+	requestParams := map[string]interface{}{}
+	requestParams["symbolId"] = params.Symbol
+	if params.Side != "" {
+		requestParams["side"] = params.Side
+	}
+	if len(params.OrderTypes) > 0 {
+		requestParams["orderTypes"] = strings.Join(params.OrderTypes, ",")
+	}
+
+	// create request
+	req := newSignedRequest(http.MethodDelete, BATCH_CANCEL_SPOT_ORDERS, requestParams)
+	req.idempotent = true
+
+	var response = new(ResponseForBatchCancelSpotOrders)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return false, err
+	}
+
+	return response.Result.Success, nil
+}
+
+/*
+	Cancels spot orders by id, SPOT_BATCH_CANCEL_MAX_IDS per request
+
+	Requires:
+		orderIds []string
+
+	Returns:
+		results []BatchOrderResult - in the order of orderIds, with the error of each order that wasn't cancelled
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-batchcancelactiveorderbyids
+*/
+func (bybit *BybitExchange) CancelSpotOrdersByIds(orderIds []string) (results []BatchOrderResult) {
+	return bybit.CancelSpotOrdersByIdsCtx(context.Background(), orderIds)
+}
+
+// Same as CancelSpotOrdersByIds, cancelled or timed out through ctx
+func (bybit *BybitExchange) CancelSpotOrdersByIdsCtx(ctx context.Context, orderIds []string) (results []BatchOrderResult) {
+	functionName := "CancelSpotOrdersByIds"
+	results = make([]BatchOrderResult, len(orderIds))
+	for i, orderId := range orderIds {
+		results[i].OrderId = orderId
+	}
+
+	for start := 0; start < len(orderIds); start += SPOT_BATCH_CANCEL_MAX_IDS {
+		end := start + SPOT_BATCH_CANCEL_MAX_IDS
+		if end > len(orderIds) {
+			end = len(orderIds)
+		}
+		chunk := results[start:end]
+
+		params := map[string]interface{}{}
+		params["orderIds"] = strings.Join(orderIds[start:end], ",")
+
+		// create request
+		req := newSignedRequest(http.MethodDelete, BATCH_CANCEL_SPOT_BY_IDS, params)
+		req.idempotent = true
+
+		var response = new(ResponseForBatchCancelSpotOrdersByIds)
+		if err := bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			for i := range chunk {
+				chunk[i].Err = err
+			}
+			continue
+		}
+
+		failures := map[string]error{}
+		for _, failure := range response.Result {
+			apiErr := &APIError{RetMsg: failure.Code, HTTPStatus: http.StatusOK, Endpoint: BATCH_CANCEL_SPOT_BY_IDS}
+			apiErr.RetCode, _ = strconv.Atoi(failure.Code)
+			apiErr.category = classifyApiError(apiErr)
+			failures[failure.OrderId] = apiErr
+		}
+		for i := range chunk {
+			chunk[i].Err = failures[chunk[i].OrderId]
+		}
+	}
+	return results
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// calls do for 0..n-1, BATCH_ORDER_CONCURRENCY at a time, and waits for all of them
+func fanOut(n int, do func(i int)) {
+	slots := make(chan struct{}, BATCH_ORDER_CONCURRENCY)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			do(i)
+		}(i)
+	}
+	wg.Wait()
+}
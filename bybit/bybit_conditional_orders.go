@@ -0,0 +1,331 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/fatih/structs"
+)
+
+/*
+	Places a stop or stop-limit perp order. It rests as a conditional order
+	until the TriggerBy price crosses StopPx, then it's placed as an active
+	order. USDT settled symbols are placed on the linear endpoints, USDC
+	settled ones aren't supported.
+
+	Requires:
+		params PlaceConditionalOrderParams
+
+	Returns:
+		stopOrderId string
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-placecond
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-placecond
+*/
+func (bybit *BybitExchange) PlaceConditionalOrder(params PlaceConditionalOrderParams) (stopOrderId string, err error) {
+	return bybit.PlaceConditionalOrderCtx(context.Background(), params)
+}
+
+// Same as PlaceConditionalOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) PlaceConditionalOrderCtx(ctx context.Context, params PlaceConditionalOrderParams) (stopOrderId string, err error) {
+	functionName := "PlaceConditionalOrder"
+	if bybit.orderValidation != nil {
+		if params, err = bybit.ValidateConditionalOrderCtx(ctx, params); err != nil {
+			return stopOrderId, err
+		}
+	}
+	if params.OrderLinkId == "" {
+		params.OrderLinkId = newOrderLinkId()
+	}
+	isLinear, err := bybit.isLinear(ctx, params.Symbol)
+	if err != nil {
+		return stopOrderId, err
+	}
+
+	params_map := structs.Map(&params)
+	endpoint := PLACE_LINEAR_STOP_ORDER
+	if !isLinear {
+		// inverse conditional orders have no reduce_only, close_on_trigger reduces them instead
+		delete(params_map, "reduce_only")
+		endpoint = PLACE_PERP_STOP_ORDER
+	}
+
+	// create request
+	req := newSignedRequest(http.MethodPost, endpoint, params_map)
+
+	var response = new(ResponseForPlaceConditionalOrder)
+	return bybit.placeOrder(ctx, functionName, params.OrderLinkId, req, response,
+		func() string { return response.Result.StopOrderId },
+		func(ctx context.Context) (string, error) {
+			order, err := bybit.GetConditionalOrderByLinkIdCtx(ctx, params.Symbol, params.OrderLinkId)
+			return order.StopOrderId, err
+		})
+}
+
+/*
+	Lists the conditional orders of a perp, newest first.
+
+	Requires:
+		params ConditionalListParams
+
+	Returns:
+		orders *Iterator[ConditionalOrder]
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-getcond
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-getcond
+*/
+func (bybit *BybitExchange) GetConditionalOrders(params ConditionalListParams) (orders *Iterator[ConditionalOrder]) {
+	return newIterator(bybit.conditionalOrdersPage("GetConditionalOrders", params))
+}
+
+/*
+	Gets a conditional order by its stop order id
+
+	Requires:
+		symbol string
+		stopOrderId string
+
+	Returns:
+		order ConditionalOrder
+		err error - wraps ErrOrderNotFound when no conditional order has this id
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-querycond
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-querycond
+*/
+func (bybit *BybitExchange) GetConditionalOrder(symbol, stopOrderId string) (order ConditionalOrder, err error) {
+	return bybit.GetConditionalOrderCtx(context.Background(), symbol, stopOrderId)
+}
+
+// Same as GetConditionalOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetConditionalOrderCtx(ctx context.Context, symbol, stopOrderId string) (order ConditionalOrder, err error) {
+	return bybit.queryConditionalOrder(ctx, "GetConditionalOrder", symbol, "stop_order_id", stopOrderId)
+}
+
+/*
+	Gets a conditional order by the order link id it was placed with
+
+	Requires:
+		symbol string
+		orderLinkId string
+
+	Returns:
+		order ConditionalOrder
+		err error - wraps ErrOrderNotFound when no conditional order has this link id
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-querycond
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-querycond
+*/
+func (bybit *BybitExchange) GetConditionalOrderByLinkId(symbol, orderLinkId string) (order ConditionalOrder, err error) {
+	return bybit.GetConditionalOrderByLinkIdCtx(context.Background(), symbol, orderLinkId)
+}
+
+// Same as GetConditionalOrderByLinkId, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetConditionalOrderByLinkIdCtx(ctx context.Context, symbol, orderLinkId string) (order ConditionalOrder, err error) {
+	return bybit.queryConditionalOrder(ctx, "GetConditionalOrderByLinkId", symbol, "order_link_id", orderLinkId)
+}
+
+/*
+	Changes the quantity, price, trigger price, take profit or stop loss of a
+	conditional order that hasn't triggered yet.
+
+	Requires:
+		params AmendConditionalOrderParams
+
+	Returns:
+		stopOrderId string
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-replacecond
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-replacecond
+*/
+func (bybit *BybitExchange) AmendConditionalOrder(params AmendConditionalOrderParams) (stopOrderId string, err error) {
+	return bybit.AmendConditionalOrderCtx(context.Background(), params)
+}
+
+// Same as AmendConditionalOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) AmendConditionalOrderCtx(ctx context.Context, params AmendConditionalOrderParams) (stopOrderId string, err error) {
+	functionName := "AmendConditionalOrder"
+	isLinear, err := bybit.isLinear(ctx, params.Symbol)
+	if err != nil {
+		return stopOrderId, err
+	}
+	endpoint := REPLACE_PERP_STOP_ORDER
+	if isLinear {
+		endpoint = REPLACE_LINEAR_STOP_ORDER
+	}
+	params_map := structs.Map(&params)
+
+	// create request
+	req := newSignedRequest(http.MethodPost, endpoint, params_map)
+
+	var response = new(ResponseForPlaceConditionalOrder)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return stopOrderId, err
+	}
+
+	return response.Result.StopOrderId, err
+}
+
+/*
+	Cancels a conditional order
+
+	Requires:
+		symbol string
+		stopOrderId string
+
+	Returns:
+		status bool
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-cancelcond
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-cancelcond
+*/
+func (bybit *BybitExchange) CancelConditionalOrder(symbol, stopOrderId string) (status bool, err error) {
+	return bybit.CancelConditionalOrderCtx(context.Background(), symbol, stopOrderId)
+}
+
+// Same as CancelConditionalOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) CancelConditionalOrderCtx(ctx context.Context, symbol, stopOrderId string) (status bool, err error) {
+	functionName := "CancelConditionalOrder"
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+	params["stop_order_id"] = stopOrderId
+
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return false, err
+	}
+	endpoint := CANCEL_PERP_STOP_ORDER
+	if isLinear {
+		endpoint = CANCEL_LINEAR_STOP_ORDER
+	}
+
+	// create request
+	req := newSignedRequest(http.MethodPost, endpoint, params)
+
+	var response = new(ResponseForPlaceConditionalOrder)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return false, err
+	}
+
+	return true, err
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// looks a conditional order up by stop_order_id or order_link_id
+func (bybit *BybitExchange) queryConditionalOrder(ctx context.Context, functionName, symbol, key, value string) (order ConditionalOrder, err error) {
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+	params[key] = value
+
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return order, err
+	}
+	if isLinear {
+		req := newSignedRequest(http.MethodGet, QUERY_LINEAR_STOP_ORDER, params)
+		var response = new(ResponseForQueryLinearConditionalOrder)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return order, err
+		}
+		if response.Result == nil || response.Result.StopOrderId == "" {
+			return order, fmt.Errorf("%v found no conditional order for %v %v: %w", functionName, key, value, ErrOrderNotFound)
+		}
+		return linearConditionalToConditionalOrder(*response.Result), nil
+	}
+
+	// create request
+	req := newSignedRequest(http.MethodGet, QUERY_PERP_STOP_ORDER, params)
+
+	var response = new(ResponseForQueryConditionalOrder)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return order, err
+	}
+
+	// bybit answers an unknown id with an empty result rather than an error
+	if response.Result == nil || response.Result.StopOrderId == "" {
+		return order, fmt.Errorf("%v found no conditional order for %v %v: %w", functionName, key, value, ErrOrderNotFound)
+	}
+	return *response.Result, nil
+}
+
+// fetches a page of conditional orders, the cursor is bybit's cursor for inverse and the page number for linear
+func (bybit *BybitExchange) conditionalOrdersPage(functionName string, listParams ConditionalListParams) func(ctx context.Context, cursor string) ([]ConditionalOrder, string, error) {
+	return func(ctx context.Context, cursor string) (orders []ConditionalOrder, nextCursor string, err error) {
+		isLinear, err := bybit.isLinear(ctx, listParams.Symbol)
+		if err != nil {
+			return orders, nextCursor, err
+		}
+
+		params := map[string]interface{}{}
+		params["symbol"] = listParams.Symbol
+		params["limit"] = CONDITIONAL_ORDERS_PAGE_SIZE
+		if listParams.Status != "" {
+			params["stop_order_status"] = listParams.Status
+		}
+
+		if isLinear {
+			page := 1
+			if cursor != "" {
+				page, _ = strconv.Atoi(cursor)
+			}
+			params["page"] = page
+
+			req := newSignedRequest(http.MethodGet, GET_LINEAR_STOP_ORDER, params)
+			var response = new(ResponseForGetLinearConditionalOrders)
+			if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+				return orders, nextCursor, err
+			}
+			for _, linearOrder := range response.Result.LinearConditionalOrders {
+				orders = append(orders, linearConditionalToConditionalOrder(linearOrder))
+			}
+			if page < response.Result.LastPage {
+				nextCursor = strconv.Itoa(page + 1)
+			}
+			return orders, nextCursor, nil
+		}
+
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		req := newSignedRequest(http.MethodGet, GET_PERP_STOP_ORDER, params)
+		var response = new(ResponseForGetConditionalOrders)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return orders, nextCursor, err
+		}
+		if len(response.Result.ConditionalOrders) == CONDITIONAL_ORDERS_PAGE_SIZE {
+			nextCursor = response.Result.Cursor
+		}
+		return response.Result.ConditionalOrders, nextCursor, nil
+	}
+}
+
+func linearConditionalToConditionalOrder(order LinearConditionalOrder) ConditionalOrder {
+	return ConditionalOrder{
+		StopOrderId:    order.StopOrderId,
+		UserId:         order.UserId,
+		Symbol:         order.Symbol,
+		Side:           order.Side,
+		OrderType:      order.OrderType,
+		Price:          order.Price,
+		Qty:            order.Qty,
+		TimeInForce:    order.TimeInForce,
+		OrderStatus:    order.OrderStatus,
+		TriggerBy:      order.TriggerBy,
+		BasePrice:      order.BasePrice,
+		StopPx:         order.TriggerPrice,
+		ReduceOnly:     order.ReduceOnly,
+		CloseOnTrigger: order.CloseOnTrigger,
+		OrderLinkId:    order.OrderLinkId,
+		CreatedAt:      order.CreatedTime,
+		UpdatedAt:      order.UpdatedTime,
+		TakeProfit:     order.TakeProfit,
+		StopLoss:       order.StopLoss,
+		TpTriggerBy:    order.TpTriggerBy,
+		SlTriggerBy:    order.SlTriggerBy,
+	}
+}
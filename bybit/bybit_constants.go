@@ -1,18 +1,159 @@
 package bybit_exchange
 
+import "time"
+
 const (
 	TESTNET_URL   = "https://api-testnet.bybit.com"
 	MAINNET_URL   = "https://api.bybit.com"
 	MAINNET_URL_2 = "https://api.bytick.com"
 )
 
+// ENVIRONMENTS
+const (
+	ENV_TESTNET Environment = "testnet"
+	ENV_MAINNET Environment = "mainnet"
+	ENV_BYTICK  Environment = "bytick" // mainnet through the api.bytick.com host
+)
+
+// default recv_window (in milliseconds) a signed request stays valid for
+const DEFAULT_RECV_WINDOW = 5000
+
+// default timeout for requests whose context carries no deadline
+const DEFAULT_REQUEST_TIMEOUT = 30 * time.Second
+
+// TIME SYNC
+const (
+	DEFAULT_TIME_SYNC_INTERVAL = time.Minute
+	TIME_SYNC_SAMPLES          = 3
+	TIMESTAMP_SAFETY_MARGIN    = 100 * time.Millisecond // keeps signed timestamps behind server time
+)
+
+// HOST FAILOVER
+const (
+	DEFAULT_HOST_PROBE_INTERVAL = 30 * time.Second
+	HOST_FAILURE_THRESHOLD      = 3                     // consecutive failures that open a host's circuit
+	HOST_OPEN_DURATION          = 30 * time.Second      // how long an open circuit skips the host
+	HOST_LATENCY_TOLERANCE      = 20 * time.Millisecond // latency gap needed to move off the active host
+	HOST_LATENCY_SMOOTHING      = 0.3                   // weight of the newest probe in the latency average
+)
+
+// INSTRUMENTS
+const (
+	DEFAULT_INSTRUMENT_TTL   = time.Hour   // how long symbol info is served before it's reloaded
+	V5_INSTRUMENTS_PAGE_SIZE = 1000        // instruments per v5 page, the most bybit allows
+	INSTRUMENT_LOAD_TIMEOUT  = time.Minute // bounds a shared load, which no single caller can cancel
+)
+
+// PAGINATION
+const (
+	SPOT_ORDERS_PAGE_SIZE = 500 // spot orders per page, the most bybit allows
+	SPOT_TRADES_PAGE_SIZE = 50  // spot trades per page, the most bybit allows
+
+	PERP_EXECUTIONS_PAGE_SIZE = 200 // perp executions per page, the most bybit allows
+	PERP_CLOSED_PNL_PAGE_SIZE = 50  // perp closed pnl records per page, the most bybit allows
+	PERP_MAX_PAGE             = 50  // last page bybit serves, narrow the time range to reach older records
+
+	FUNDING_HISTORY_PAGE_SIZE = 200 // funding rates per page, the most bybit allows
+
+	KLINES_PAGE_SIZE = 1000 // klines per page, the most bybit allows
+
+	SPOT_RECENT_TRADES_LIMIT   = 60   // the most recent spot trades bybit serves
+	PERP_RECENT_TRADES_LIMIT   = 1000 // the most recent perp trades bybit serves
+	OPEN_INTEREST_PAGE_SIZE    = 200  // open interest records per page, the most bybit allows
+	LONG_SHORT_RATIO_PAGE_SIZE = 500  // long/short ratios per page, the most bybit allows
+
+	CONDITIONAL_ORDERS_PAGE_SIZE = 50 // conditional orders per page, the most bybit allows
+)
+
+// ORDER BOOK
+const (
+	DEFAULT_ORDERBOOK_DEPTH  = 50
+	SPOT_ORDERBOOK_MAX_DEPTH = 200 // levels per side, the most bybit allows
+	PERP_ORDERBOOK_MAX_DEPTH = 500
+)
+
+// BATCH ORDERS
+const (
+	BATCH_ORDER_CONCURRENCY   = 10  // orders of a batch in flight at once, the order rate limits still apply
+	SPOT_BATCH_CANCEL_MAX_IDS = 100 // order ids per spot batch cancel, the most bybit allows
+)
+
+// KLINES
+const KLINE_FETCH_CONCURRENCY = 4 // pages of a kline range in flight at once, the public rate limit still applies
+
+// FUNDING
+const (
+	FUNDING_INTERVAL = 8 * time.Hour // perps settle funding at 00:00, 08:00 and 16:00 UTC
+	DAYS_PER_YEAR    = 365
+)
+
+// Perp execution types
+const (
+	EXEC_TYPE_TRADE      = "Trade"
+	EXEC_TYPE_ADL_TRADE  = "AdlTrade"
+	EXEC_TYPE_FUNDING    = "Funding"
+	EXEC_TYPE_BUST_TRADE = "BustTrade"
+)
+
+// Instrument contract type
+const (
+	CONTRACT_TYPE_SPOT              = "Spot"
+	CONTRACT_TYPE_INVERSE_PERPETUAL = "InversePerpetual"
+	CONTRACT_TYPE_LINEAR_PERPETUAL  = "LinearPerpetual"
+	CONTRACT_TYPE_INVERSE_FUTURES   = "InverseFutures"
+	CONTRACT_TYPE_LINEAR_FUTURES    = "LinearFutures"
+)
+
+// Instrument status
+const (
+	INSTRUMENT_STATUS_TRADING = "Trading"
+	INSTRUMENT_STATUS_CLOSED  = "Closed"
+)
+
+// RETRIES
+const (
+	DEFAULT_RETRY_ATTEMPTS   = 3 // attempts per request, including the first
+	DEFAULT_RETRY_BASE_DELAY = 200 * time.Millisecond
+	DEFAULT_RETRY_MAX_DELAY  = 5 * time.Second
+	ORDER_LINK_ID_BYTES      = 16 // random bytes of generated order link ids, hex encoded to stay below bybit's 36 chars
+)
+
+// RATE LIMIT
+const (
+	RATE_LIMIT_BACKOFF       = 5 * time.Second       // group pause after bybit rate limited us
+	RATE_LIMIT_RESERVE       = 0.1                   // share of a group's tokens only cancels may use
+	RATE_LIMIT_POLL_INTERVAL = 10 * time.Millisecond // shortest wait between token attempts
+)
+
+// Rate limit groups
+const (
+	RATE_LIMIT_PUBLIC           = "public"
+	RATE_LIMIT_PRIVATE          = "private"
+	RATE_LIMIT_PERP_ORDER       = "perp_order"
+	RATE_LIMIT_PERP_ORDER_QUERY = "perp_order_query"
+	RATE_LIMIT_PERP_POSITION    = "perp_position"
+	RATE_LIMIT_PERP_WALLET      = "perp_wallet"
+	RATE_LIMIT_LINEAR_ORDER     = "linear_order"
+	RATE_LIMIT_LINEAR_QUERY     = "linear_order_query"
+	RATE_LIMIT_LINEAR_POSITION  = "linear_position"
+	RATE_LIMIT_SPOT_ORDER       = "spot_order"
+	RATE_LIMIT_SPOT_QUERY       = "spot_query"
+	RATE_LIMIT_ASSET            = "asset"
+	RATE_LIMIT_V5_ORDER         = "v5_order"
+	RATE_LIMIT_V5_ORDER_QUERY   = "v5_order_query"
+	RATE_LIMIT_V5_POSITION      = "v5_position"
+	RATE_LIMIT_V5_ACCOUNT       = "v5_account"
+)
+
 // API ENDPOINTS
 const (
 	TICKER                    = "/v2/public/tickers"
 	SERVER_TIME               = "/v2/public/time"
 	GET_PERP_SYMBOLS_INFO     = "/v2/public/symbols"
+	GET_SPOT_SYMBOLS_INFO     = "/spot/v1/symbols"
 	PLACE_PERP_ORDER          = "/v2/private/order/create"
 	GET_PERP_ORDER            = "/v2/private/order/list"
+	QUERY_PERP_ORDER          = "/v2/private/order"
 	GET_PERP_POSITION         = "/v2/private/position/list"
 	SPOT_MARKET_PRICE         = "/spot/quote/v1/ticker/book_ticker"
 	SPOT_ORDER                = "/spot/v1/order"
@@ -21,6 +162,106 @@ const (
 	GET_DEPOSIT_ADDRESS       = "/asset/v1/private/deposit/address"
 	CANCEL_PERP_ORDER         = "/v2/private/order/cancel"
 	WITHDRAW_FROM_SPOT_WALLET = "/asset/v1/private/withdraw"
+	PLACE_LINEAR_ORDER        = "/private/linear/order/create"
+	GET_LINEAR_ORDER          = "/private/linear/order/list"
+	QUERY_LINEAR_ORDER        = "/private/linear/order/search"
+	CANCEL_LINEAR_ORDER       = "/private/linear/order/cancel"
+	GET_LINEAR_POSITION       = "/private/linear/position/list"
+	GET_SPOT_OPEN_ORDERS      = "/spot/v1/open-orders"
+	GET_SPOT_ORDER_HISTORY    = "/spot/v1/history-orders"
+	GET_SPOT_TRADE_HISTORY    = "/spot/v1/myTrades"
+	GET_PERP_EXECUTIONS       = "/v2/private/execution/list"
+	GET_PERP_CLOSED_PNL       = "/v2/private/trade/closed-pnl/list"
+	GET_LINEAR_EXECUTIONS     = "/private/linear/trade/execution/list"
+	GET_LINEAR_CLOSED_PNL     = "/private/linear/trade/closed-pnl/list"
+	SPOT_MERGED_DEPTH         = "/spot/quote/v1/depth/merged"
+	REPLACE_PERP_ORDER        = "/v2/private/order/replace"
+	CANCEL_ALL_PERP_ORDERS    = "/v2/private/order/cancelAll"
+	CANCEL_ALL_LINEAR_ORDERS  = "/private/linear/order/cancel-all"
+	BATCH_CANCEL_SPOT_ORDERS  = "/spot/order/batch-cancel"
+	BATCH_CANCEL_SPOT_BY_IDS  = "/spot/order/batch-cancel-by-ids"
+	REPLACE_LINEAR_ORDER      = "/private/linear/order/replace"
+	PLACE_PERP_STOP_ORDER     = "/v2/private/stop-order/create"
+	GET_PERP_STOP_ORDER       = "/v2/private/stop-order/list"
+	QUERY_PERP_STOP_ORDER     = "/v2/private/stop-order"
+	REPLACE_PERP_STOP_ORDER   = "/v2/private/stop-order/replace"
+	CANCEL_PERP_STOP_ORDER    = "/v2/private/stop-order/cancel"
+	PLACE_LINEAR_STOP_ORDER   = "/private/linear/stop-order/create"
+	GET_LINEAR_STOP_ORDER     = "/private/linear/stop-order/list"
+	QUERY_LINEAR_STOP_ORDER   = "/private/linear/stop-order/search"
+	REPLACE_LINEAR_STOP_ORDER = "/private/linear/stop-order/replace"
+	CANCEL_LINEAR_STOP_ORDER  = "/private/linear/stop-order/cancel"
+	SET_PERP_LEVERAGE         = "/v2/private/position/leverage/save"
+	SET_LINEAR_LEVERAGE       = "/private/linear/position/set-leverage"
+	SWITCH_PERP_ISOLATED      = "/v2/private/position/switch-isolated"
+	SWITCH_LINEAR_ISOLATED    = "/private/linear/position/switch-isolated"
+	SWITCH_PERP_POSITION_MODE = "/v2/private/position/switch-mode"
+	SWITCH_LINEAR_MODE        = "/private/linear/position/switch-mode"
+	SWITCH_PERP_TP_SL_MODE    = "/v2/private/tpsl/switch-mode"
+	SWITCH_LINEAR_TP_SL_MODE  = "/private/linear/tpsl/switch-mode"
+	CHANGE_PERP_MARGIN        = "/v2/private/position/change-position-margin"
+	CHANGE_LINEAR_MARGIN      = "/private/linear/position/add-margin"
+	SET_PERP_TRADING_STOP     = "/v2/private/position/trading-stop"
+	SET_LINEAR_TRADING_STOP   = "/private/linear/position/trading-stop"
+)
+
+// V5 API ENDPOINTS
+const (
+	V5_SERVER_TIME      = "/v5/market/time"
+	V5_TICKERS          = "/v5/market/tickers"
+	V5_INSTRUMENTS_INFO = "/v5/market/instruments-info"
+	V5_PLACE_ORDER      = "/v5/order/create"
+	V5_CANCEL_ORDER     = "/v5/order/cancel"
+	V5_OPEN_ORDERS      = "/v5/order/realtime"
+	V5_ORDER_HISTORY    = "/v5/order/history"
+	V5_POSITIONS        = "/v5/position/list"
+	V5_WALLET_BALANCE   = "/v5/account/wallet-balance"
+	V5_DEPOSIT_ADDRESS  = "/v5/asset/deposit/query-address"
+	V5_WITHDRAW         = "/v5/asset/withdraw/create"
+	V5_FUNDING_HISTORY  = "/v5/market/funding/history"
+	V5_KLINE            = "/v5/market/kline"
+	V5_MARK_PRICE_KLINE = "/v5/market/mark-price-kline"
+	V5_INDEX_KLINE      = "/v5/market/index-price-kline"
+	V5_PREMIUM_KLINE    = "/v5/market/premium-index-price-kline"
+	V5_ORDERBOOK        = "/v5/market/orderbook"
+	V5_RECENT_TRADES    = "/v5/market/recent-trade"
+	V5_OPEN_INTEREST    = "/v5/market/open-interest"
+	V5_ACCOUNT_RATIO    = "/v5/market/account-ratio"
+)
+
+// V5 product category (category)
+const (
+	CATEGORY_SPOT    = "spot"
+	CATEGORY_LINEAR  = "linear"
+	CATEGORY_INVERSE = "inverse"
+	CATEGORY_OPTION  = "option"
+)
+
+// V5 open interest and long/short ratio period (intervalTime, period)
+const (
+	PERIOD_5MIN  = "5min"
+	PERIOD_15MIN = "15min"
+	PERIOD_30MIN = "30min"
+	PERIOD_1HOUR = "1h"
+	PERIOD_4HOUR = "4h"
+	PERIOD_1DAY  = "1d"
+)
+
+// V5 wallet account type (accountType)
+const (
+	ACCOUNT_TYPE_UNIFIED  = "UNIFIED"
+	ACCOUNT_TYPE_CONTRACT = "CONTRACT"
+	ACCOUNT_TYPE_SPOT     = "SPOT"
+)
+
+// V5 order type (orderType) and time in force (timeInForce)
+const (
+	V5_ORDER_TYPE_LIMIT  = "Limit"
+	V5_ORDER_TYPE_MARKET = "Market"
+	V5_GTC               = "GTC"
+	V5_IOC               = "IOC"
+	V5_FOK               = "FOK"
+	V5_POST_ONLY         = "PostOnly"
 )
 
 // ORDERS
@@ -47,3 +288,60 @@ const (
 	PLACE_PERP_FILL_OR_KILL        = "FillOrKill"
 	PLACE_PERP_POST_ONLY           = "PostOnly"
 )
+
+// Position index (position_idx), hedge mode holds a position per side
+const (
+	POSITION_IDX_ONE_WAY    = 0
+	POSITION_IDX_HEDGE_BUY  = 1 // the long leg
+	POSITION_IDX_HEDGE_SELL = 2 // the short leg
+)
+
+// Legs of a cancel-replace
+const (
+	LEG_VALIDATE = "validate"
+	LEG_CANCEL   = "cancel"
+	LEG_PLACE    = "place"
+)
+
+// Conditional order trigger price (trigger_by, tp_trigger_by, sl_trigger_by)
+const (
+	TRIGGER_BY_LAST_PRICE  = "LastPrice"
+	TRIGGER_BY_MARK_PRICE  = "MarkPrice"
+	TRIGGER_BY_INDEX_PRICE = "IndexPrice"
+)
+
+// Conditional order status (stop_order_status)
+const (
+	STOP_ORDER_STATUS_UNTRIGGERED = "Untriggered"
+	STOP_ORDER_STATUS_TRIGGERED   = "Triggered"
+	STOP_ORDER_STATUS_ACTIVE      = "Active" // linear, triggered and placed as an active order
+	STOP_ORDER_STATUS_CANCELLED   = "Cancelled"
+	STOP_ORDER_STATUS_REJECTED    = "Rejected"
+	STOP_ORDER_STATUS_DEACTIVATED = "Deactivated"
+)
+
+// Position mode (mode), one position per symbol or one per side
+const (
+	POSITION_MODE_ONE_WAY = "MergedSingle"
+	POSITION_MODE_HEDGE   = "BothSide"
+)
+
+// Take profit and stop loss mode (tp_sl_mode)
+const (
+	TP_SL_MODE_FULL    = "Full"    // one tp/sl for the whole position
+	TP_SL_MODE_PARTIAL = "Partial" // tp/sl orders for part of the position
+)
+
+// Spot order type (type)
+const (
+	SPOT_ORDER_TYPE_LIMIT       = "LIMIT"
+	SPOT_ORDER_TYPE_MARKET      = "MARKET"
+	SPOT_ORDER_TYPE_LIMIT_MAKER = "LIMIT_MAKER"
+)
+
+// Spot time in force (timeInForce)
+const (
+	SPOT_GTC = "GTC"
+	SPOT_FOK = "FOK"
+	SPOT_IOC = "IOC"
+)
@@ -0,0 +1,180 @@
+package bybit_exchange
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error categories, match them with errors.Is(err, ErrRateLimited)
+var (
+	ErrRateLimited           = errors.New("bybit: rate limited")
+	ErrInvalidSignature      = errors.New("bybit: invalid signature")
+	ErrInvalidTimestamp      = errors.New("bybit: invalid timestamp")
+	ErrInsufficientBalance   = errors.New("bybit: insufficient balance")
+	ErrOrderNotFound         = errors.New("bybit: order not found")
+	ErrReduceOnlyViolation   = errors.New("bybit: reduce-only violation")
+	ErrAddressNotWhitelisted = errors.New("bybit: withdrawal address not whitelisted")
+	ErrServerError           = errors.New("bybit: server error")
+	ErrInstrumentNotFound    = errors.New("bybit: instrument not found")
+	ErrInvalidOrder          = errors.New("bybit: invalid order")
+	ErrDuplicateOrderLinkId  = errors.New("bybit: duplicate order link id")
+	ErrNotModified           = errors.New("bybit: not modified")            // the setting already had the requested value
+	ErrHedgedPosition        = errors.New("bybit: both position legs open") // a single position was asked for in hedge mode
+)
+
+// Bybit ret_code to error category, from the inverse, linear, spot, account asset and v5 error tables
+var retCodeCategories = map[int]error{
+	// common
+	10002: ErrInvalidTimestamp, // request expired, check timestamp and recv_window
+	10004: ErrInvalidSignature, // invalid sign
+	10006: ErrRateLimited,      // too many visits
+	10016: ErrServerError,      // internal server error
+	10018: ErrRateLimited,      // exceeded ip rate limit
+
+	// inverse
+	20001: ErrOrderNotFound,       // order not exists or too late to cancel
+	30031: ErrInsufficientBalance, // insufficient available balance for order cost
+	30032: ErrOrderNotFound,       // order has been filled or cancelled
+	30042: ErrInsufficientBalance, // insufficient wallet balance
+	30049: ErrInsufficientBalance, // insufficient available balance
+	30063: ErrReduceOnlyViolation, // reduce-only rule not satisfied
+	30067: ErrInsufficientBalance, // insufficient available balance
+	30083: ErrNotModified,         // position mode not modified
+	30084: ErrNotModified,         // isolated not modified
+	34036: ErrNotModified,         // leverage not modified
+
+	// linear
+	130010: ErrOrderNotFound,       // order not exists or too late to cancel
+	130021: ErrInsufficientBalance, // order cost not available
+	130125: ErrReduceOnlyViolation, // current position is zero, cannot fix reduce-only order qty
+
+	// spot
+	-1131: ErrInsufficientBalance, // balance insufficient
+	-2013: ErrOrderNotFound,       // order does not exist
+
+	// account asset
+	131200: ErrServerError,           // service error
+	131215: ErrAddressNotWhitelisted, // withdraw address not in whitelist
+
+	// v5
+	110001: ErrOrderNotFound,        // order does not exist
+	110004: ErrInsufficientBalance,  // wallet balance is insufficient
+	110007: ErrInsufficientBalance,  // available balance is insufficient
+	110012: ErrInsufficientBalance,  // insufficient available balance
+	110017: ErrReduceOnlyViolation,  // reduce-only rule not satisfied
+	110072: ErrDuplicateOrderLinkId, // order link id is duplicate
+	110025: ErrNotModified,          // position mode is not modified
+	110026: ErrNotModified,          // cross/isolated margin mode is not modified
+	110043: ErrNotModified,          // set leverage not modified
+}
+
+// ret_msg fragments used when a ret_code is missing from the tables above
+var retMsgCategories = []struct {
+	fragment string
+	category error
+}{
+	{"too many visits", ErrRateLimited},
+	{"rate limit", ErrRateLimited},
+	{"invalid sign", ErrInvalidSignature},
+	{"error sign", ErrInvalidSignature},
+	{"timestamp", ErrInvalidTimestamp},
+	{"recv_window", ErrInvalidTimestamp},
+	{"insufficient", ErrInsufficientBalance},
+	{"order not exist", ErrOrderNotFound},
+	{"order does not exist", ErrOrderNotFound},
+	{"reduce-only", ErrReduceOnlyViolation},
+	{"reduce only", ErrReduceOnlyViolation},
+	{"whitelist", ErrAddressNotWhitelisted},
+	{"not modified", ErrNotModified},
+	{"duplicate", ErrDuplicateOrderLinkId},
+	{"repeated", ErrDuplicateOrderLinkId},
+}
+
+// Error returned when bybit rejects a request, either through a non-zero
+// ret_code or a non-2xx http status without a parsable body.
+type APIError struct {
+	RetCode    int
+	RetMsg     string
+	ExtCode    string
+	ExtInfo    string
+	HTTPStatus int
+	Endpoint   string
+
+	category error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bybit %v failed: ret_code=%v ret_msg=%q ext_code=%q ext_info=%q http_status=%v",
+		e.Endpoint, e.RetCode, e.RetMsg, e.ExtCode, e.ExtInfo, e.HTTPStatus)
+}
+
+// Returns the error category so errors.Is matches the sentinel errors
+func (e *APIError) Unwrap() error {
+	return e.category
+}
+
+// Returns the error category, nil when the error is not classified
+func (e *APIError) Category() error {
+	return e.category
+}
+
+// Returns the *APIError wrapped in err, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+func checkApiResponse(endpoint string, statusCode int, response ApiResponse) error {
+	if response.RetCode == 0 && statusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	apiErr := &APIError{
+		RetCode:    response.RetCode,
+		RetMsg:     response.RetMsg,
+		ExtCode:    response.ExtCode,
+		ExtInfo:    response.ExtInfo,
+		HTTPStatus: statusCode,
+		Endpoint:   endpoint,
+	}
+	apiErr.category = classifyApiError(apiErr)
+	return apiErr
+}
+
+func newHTTPError(endpoint string, statusCode int) error {
+	apiErr := &APIError{
+		RetMsg:     http.StatusText(statusCode),
+		HTTPStatus: statusCode,
+		Endpoint:   endpoint,
+	}
+	apiErr.category = classifyApiError(apiErr)
+	return apiErr
+}
+
+func classifyApiError(apiErr *APIError) error {
+	if category, ok := retCodeCategories[apiErr.RetCode]; ok {
+		return category
+	}
+
+	msg := strings.ToLower(apiErr.RetMsg)
+	for _, retMsgCategory := range retMsgCategories {
+		if msg != "" && strings.Contains(msg, retMsgCategory.fragment) {
+			return retMsgCategory.category
+		}
+	}
+
+	// cloudfront answers 403 when the ip is banned for exceeding rate limits
+	switch {
+	case apiErr.HTTPStatus == http.StatusForbidden, apiErr.HTTPStatus == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case apiErr.HTTPStatus >= http.StatusInternalServerError:
+		return ErrServerError
+	}
+
+	return nil
+}
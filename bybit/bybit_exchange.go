@@ -1,56 +1,137 @@
 package bybit_exchange
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-	"sort"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
 	"github.com/fatih/structs"
 	"github.com/pingcap/log"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
 )
 
+// Bybit exchange rest client. Configuration is fixed once constructed,
+// so a single instance is safe for concurrent use by many goroutines.
 type BybitExchange struct {
 	Client    *http.Client
 	SecretKey string
 	ApiKey    string
-}
-
-// runtime bybit exchange client instance
-var bybitExchange_ *BybitExchange
 
-// -------- Runtime State
-var isInitRest_ bool
+	hostURLs          []string
+	hostPool          *HostPool
+	hostProbeInterval time.Duration
+	serverTimePath    string
+	recvWindow        int
+	requestTimeout    time.Duration
+	timeSyncInterval  time.Duration
+	timeSync          *TimeSync
+	rateLimitMode     RateLimitMode
+	rateLimiter       *RateLimiter
+	instrumentTTL     time.Duration
+	instruments       *InstrumentRegistry
+	orderValidation   *OrderValidation
+	unifiedAccount    bool
+	retryPolicy       RetryPolicy
+	logger            *zap.Logger
+}
 
-var isTestnet = true
+// runtime bybit exchange client instance, only kept for GetBybitExchangeRestService
+var (
+	bybitExchange_   *BybitExchange
+	bybitExchangeMu_ sync.RWMutex
+)
 
-// Generates new bybit exchange client.
+// Generates new bybit exchange client, defaults to testnet.
 // Requires: Secret and Api keys
-func NewBybitExchangeClient(secretKey, apiKey string) {
-	bybitExchange_ = &BybitExchange{
-		Client:    &http.Client{},
-		SecretKey: secretKey,
-		ApiKey:    apiKey,
+// Optional: WithEnvironment, WithBaseURL, WithHosts, WithHostProbeInterval, WithHTTPClient, WithRecvWindow, WithRequestTimeout, WithTimeSyncInterval, WithRateLimitMode, WithRetryPolicy, WithInstrumentTTL, WithOrderValidation, WithUnifiedAccount, WithLogger
+func NewBybitExchange(secretKey, apiKey string, opts ...Option) *BybitExchange {
+	bybit := &BybitExchange{
+		Client:            &http.Client{},
+		SecretKey:         secretKey,
+		ApiKey:            apiKey,
+		hostURLs:          []string{TESTNET_URL},
+		hostProbeInterval: DEFAULT_HOST_PROBE_INTERVAL,
+		serverTimePath:    SERVER_TIME,
+		recvWindow:        DEFAULT_RECV_WINDOW,
+		requestTimeout:    DEFAULT_REQUEST_TIMEOUT,
+		timeSyncInterval:  DEFAULT_TIME_SYNC_INTERVAL,
+		retryPolicy:       DefaultRetryPolicy(),
+		instrumentTTL:     DEFAULT_INSTRUMENT_TTL,
+		logger:            log.L(),
 	}
-	isInitRest_ = true
+	for _, opt := range opts {
+		opt(bybit)
+	}
+	bybit.timeSync = newTimeSync(bybit.GetServerTimeCtx, bybit.timeSyncInterval, bybit.logger)
+	bybit.rateLimiter = newRateLimiter(bybit.rateLimitMode)
+	bybit.hostPool = newHostPool(bybit.hostURLs, bybit.probeHost, bybit.hostProbeInterval, bybit.logger)
+	bybit.instruments = newInstrumentRegistry(bybit.loadInstruments, bybit.instrumentTTL, bybit.logger)
+	return bybit
+}
+
+// Generates new bybit exchange client and registers it as the runtime instance.
+// Requires: Secret and Api keys
+//
+// Deprecated: use NewBybitExchange and pass the returned client around instead.
+func NewBybitExchangeClient(secretKey, apiKey string, opts ...Option) {
+	bybit := NewBybitExchange(secretKey, apiKey, opts...)
+
+	bybitExchangeMu_.Lock()
+	defer bybitExchangeMu_.Unlock()
+	bybitExchange_ = bybit
 }
 
 // Returns initiated bybit exchange client instance with access to methods
+//
+// Deprecated: use NewBybitExchange and pass the returned client around instead.
 func GetBybitExchangeRestService() (*BybitExchange, error) {
+	bybitExchangeMu_.RLock()
+	defer bybitExchangeMu_.RUnlock()
+
 	// check if initialised
-	if isInitRest_ {
-		return bybitExchange_, nil
+	if bybitExchange_ == nil {
+		return bybitExchange_, errors.New("uninitialised exchange rest client")
 	}
-	err := errors.New("uninitialised exchange rest client")
-	return bybitExchange_, err
+	return bybitExchange_, nil
+}
+
+// Returns the clock synchronizer used to timestamp signed requests.
+// Call Start on it to refresh the offset in the background.
+func (bybit *BybitExchange) TimeSync() *TimeSync {
+	return bybit.timeSync
+}
+
+// Returns the rate limiter requests wait on, e.g. to inspect a group's Status
+func (bybit *BybitExchange) RateLimiter() *RateLimiter {
+	return bybit.rateLimiter
+}
+
+// Returns the pool of hosts requests are routed across.
+// Call Start on it to health check and latency probe them in the background.
+func (bybit *BybitExchange) HostPool() *HostPool {
+	return bybit.hostPool
+}
+
+// Returns the host requests are currently sent to
+func (bybit *BybitExchange) ActiveHost() string {
+	return bybit.hostPool.Active()
+}
+
+// Returns the cached spot and perp instruments, loaded on first use.
+// Call Start on it to refresh them in the background.
+func (bybit *BybitExchange) Instruments() *InstrumentRegistry {
+	return bybit.instruments
+}
+
+// Returns the base url requests are sent to, the active host
+func (bybit *BybitExchange) BaseURL() string {
+	return bybit.ActiveHost()
 }
 
 // ---------------------------- GETTERS ----------------------------
@@ -68,71 +149,276 @@ func GetBybitExchangeRestService() (*BybitExchange, error) {
 	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-getactive
 */
 func (bybit *BybitExchange) GetSpotOrder(orderId string) (spotOrder SpotOrder, err error) {
+	return bybit.GetSpotOrderCtx(context.Background(), orderId)
+}
+
+// Same as GetSpotOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotOrderCtx(ctx context.Context, orderId string) (spotOrder SpotOrder, err error) {
 	functionName := "GetSpotOrder"
 	params := map[string]interface{}{}
 	params["orderId"] = orderId
 
 	// create request
-	req := bybit.signRequest(http.MethodGet, SPOT_ORDER, params)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newSignedRequest(http.MethodGet, SPOT_ORDER, params)
 
 	var response = new(ResponseForGetSpotOrder)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return spotOrder, err
 	}
 
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	return response.SpotOrder, err
+}
+
+/*
+	Gets a spot order by the order link id it was placed with
+
+	Requires:
+		orderLinkId string
+
+	Returns:
+		spotOrder SpotOrder
+		err error - wraps ErrOrderNotFound when no order has this link id
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-getactive
+*/
+func (bybit *BybitExchange) GetSpotOrderByLinkId(orderLinkId string) (spotOrder SpotOrder, err error) {
+	return bybit.GetSpotOrderByLinkIdCtx(context.Background(), orderLinkId)
+}
+
+// Same as GetSpotOrderByLinkId, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotOrderByLinkIdCtx(ctx context.Context, orderLinkId string) (spotOrder SpotOrder, err error) {
+	functionName := "GetSpotOrderByLinkId"
+	params := map[string]interface{}{}
+	params["orderLinkId"] = orderLinkId
+
+	// create request
+	req := newSignedRequest(http.MethodGet, SPOT_ORDER, params)
+
+	var response = new(ResponseForGetSpotOrder)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return spotOrder, err
 	}
 
+	if response.SpotOrder.OrderId == "" {
+		return spotOrder, fmt.Errorf("%v found no order for order link id %v: %w", functionName, orderLinkId, ErrOrderNotFound)
+	}
 	return response.SpotOrder, err
 }
 
 /*
-	Gets a list of perp orders.
+	Lists open spot orders, newest first. Bybit can't filter them by time, so
+	the time range is applied to the orders received.
+
+	Requires:
+		params SpotListParams
+
+	Returns:
+		orders *Iterator[SpotOrder]
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-openorders
+*/
+func (bybit *BybitExchange) GetSpotOpenOrders(params SpotListParams) (orders *Iterator[SpotOrder]) {
+	return newIterator(bybit.spotOrdersPage("GetSpotOpenOrders", GET_SPOT_OPEN_ORDERS, params))
+}
+
+/*
+	Lists filled, cancelled and rejected spot orders, newest first.
+
+	Requires:
+		params SpotListParams
+
+	Returns:
+		orders *Iterator[SpotOrder]
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-orderhistory
+*/
+func (bybit *BybitExchange) GetSpotOrderHistory(params SpotListParams) (orders *Iterator[SpotOrder]) {
+	return newIterator(bybit.spotOrdersPage("GetSpotOrderHistory", GET_SPOT_ORDER_HISTORY, params))
+}
+
+/*
+	Lists our spot trades, newest first.
+
+	Requires:
+		params SpotListParams
+
+	Returns:
+		trades *Iterator[SpotTrade]
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-tradehistory
+*/
+func (bybit *BybitExchange) GetSpotTradeHistory(params SpotListParams) (trades *Iterator[SpotTrade]) {
+	return newIterator(bybit.spotTradesPage("GetSpotTradeHistory", params))
+}
+
+/*
+	Gets a list of perp orders. USDT settled symbols are queried on the linear
+	endpoints, the rest on the inverse ones. USDC settled ones aren't supported.
 
 	Requires: 
-		symbol string
+		symbol string - e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
 
 	Returns:
 		perpOrders []PerpOrder
 		err error
 
 	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-getactive
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-getactive
 */
 func (bybit *BybitExchange) GetPerpOrder(symbol string) (perpOrders []PerpOrder, err error) {
+	return bybit.GetPerpOrderCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpOrderCtx(ctx context.Context, symbol string) (perpOrders []PerpOrder, err error) {
 	functionName := "GetPerpOrder"
 	params := map[string]interface{}{}
 	params["symbol"] = symbol
 
-	// create request
-	req := bybit.signRequest(http.MethodGet, GET_PERP_ORDER, params)
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return perpOrders, err
+	}
+	if isLinear {
+		req := newSignedRequest(http.MethodGet, GET_LINEAR_ORDER, params)
+		var response = new(ResponseForGetLinearOrders)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return perpOrders, err
+		}
+		for _, linearOrder := range response.Result.LinearOrders {
+			perpOrders = append(perpOrders, linearOrderToPerpOrder(linearOrder))
+		}
+		return perpOrders, nil
+	}
 
-	body, err := bybit.getResponseBody(functionName, req)
+	// create request
+	req := newSignedRequest(http.MethodGet, GET_PERP_ORDER, params)
 
 	var response = new(ResponseForGetPerpOrder)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return perpOrders, err
 	}
 
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return perpOrders, err
+	return response.Result.PerpOrders, err
+}
+
+/*
+	Gets a perp order by the order link id it was placed with
+
+	Requires:
+		symbol string
+		orderLinkId string
+
+	Returns:
+		perpOrder PerpOrder
+		err error - wraps ErrOrderNotFound when no order has this link id
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-queryactive
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-queryactive
+*/
+func (bybit *BybitExchange) GetPerpOrderByLinkId(symbol, orderLinkId string) (perpOrder PerpOrder, err error) {
+	return bybit.GetPerpOrderByLinkIdCtx(context.Background(), symbol, orderLinkId)
+}
+
+// Same as GetPerpOrderByLinkId, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpOrderByLinkIdCtx(ctx context.Context, symbol, orderLinkId string) (perpOrder PerpOrder, err error) {
+	return bybit.queryPerpOrder(ctx, "GetPerpOrderByLinkId", symbol, "order_link_id", orderLinkId)
+}
+
+/*
+	Gets a perp order by its id, whether active or closed
+
+	Requires:
+		symbol string
+		orderId string
+
+	Returns:
+		perpOrder PerpOrder
+		err error - wraps ErrOrderNotFound when no order has this id
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-queryactive
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-queryactive
+*/
+func (bybit *BybitExchange) GetPerpOrderById(symbol, orderId string) (perpOrder PerpOrder, err error) {
+	return bybit.GetPerpOrderByIdCtx(context.Background(), symbol, orderId)
+}
+
+// Same as GetPerpOrderById, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpOrderByIdCtx(ctx context.Context, symbol, orderId string) (perpOrder PerpOrder, err error) {
+	return bybit.queryPerpOrder(ctx, "GetPerpOrderById", symbol, "order_id", orderId)
+}
+
+// queries a single perp order by "order_id" or "order_link_id"
+func (bybit *BybitExchange) queryPerpOrder(ctx context.Context, functionName, symbol, key, value string) (perpOrder PerpOrder, err error) {
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+	params[key] = value
+
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return perpOrder, err
+	}
+	if isLinear {
+		req := newSignedRequest(http.MethodGet, QUERY_LINEAR_ORDER, params)
+		var response = new(ResponseForQueryLinearOrder)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return perpOrder, err
+		}
+		if response.Result == nil || response.Result.OrderId == "" {
+			return perpOrder, fmt.Errorf("%v found no order for %v %v: %w", functionName, key, value, ErrOrderNotFound)
+		}
+		return linearOrderToPerpOrder(*response.Result), nil
 	}
 
-	return response.Result.PerpOrders, err
+	// create request
+	req := newSignedRequest(http.MethodGet, QUERY_PERP_ORDER, params)
+
+	var response = new(ResponseForQueryPerpOrder)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return perpOrder, err
+	}
+
+	// bybit answers an unknown id with an empty result rather than an error
+	if response.Result == nil || response.Result.OrderId == "" {
+		return perpOrder, fmt.Errorf("%v found no order for %v %v: %w", functionName, key, value, ErrOrderNotFound)
+	}
+	return *response.Result, err
+}
+
+/*
+	Lists the fills of a perp, newest first. USDT settled symbols are listed on
+	the linear endpoints, the rest on the inverse ones. Bybit serves
+	at most PERP_MAX_PAGE pages, narrow the time range to reach older fills.
+
+	Requires:
+		params PerpListParams
+
+	Returns:
+		executions *Iterator[PerpExecution]
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-usertraderecords
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-usertraderecords
+*/
+func (bybit *BybitExchange) GetPerpExecutions(params PerpListParams) (executions *Iterator[PerpExecution]) {
+	return newIterator(bybit.perpExecutionsPage("GetPerpExecutions", params))
+}
+
+/*
+	Lists the realized pnl of the orders that reduced or closed a perp
+	position, newest first. Bybit serves at most PERP_MAX_PAGE pages, narrow
+	the time range to reach older records.
+
+	Requires:
+		params PerpListParams
+
+	Returns:
+		closedPnls *Iterator[PerpClosedPnl]
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-closedprofitandloss
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-closedprofitandloss
+*/
+func (bybit *BybitExchange) GetPerpClosedPnl(params PerpListParams) (closedPnls *Iterator[PerpClosedPnl]) {
+	return newIterator(bybit.perpClosedPnlPage("GetPerpClosedPnl", params))
 }
 
 /*
@@ -148,12 +434,27 @@ func (bybit *BybitExchange) GetPerpOrder(symbol string) (perpOrders []PerpOrder,
 	Refs: https://bybit-exchange.github.io/docs/spot/v1/#t-balance
 */
 func (bybit *BybitExchange) GetSpotWalletBalanceForSymbol(symbol string) (balance float64, err error) {
-	balances, err := bybit.GetSpotWalletBalance()
+	return bybit.GetSpotWalletBalanceForSymbolCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotWalletBalanceForSymbol, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotWalletBalanceForSymbolCtx(ctx context.Context, symbol string) (balance float64, err error) {
+	free, err := bybit.GetSpotWalletBalanceForSymbolDecimalCtx(ctx, symbol)
+	return free.InexactFloat64(), err
+}
+
+// Same as GetSpotWalletBalanceForSymbol, returning the exact balance
+func (bybit *BybitExchange) GetSpotWalletBalanceForSymbolDecimal(symbol string) (balance decimal.Decimal, err error) {
+	return bybit.GetSpotWalletBalanceForSymbolDecimalCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotWalletBalanceForSymbolDecimal, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotWalletBalanceForSymbolDecimalCtx(ctx context.Context, symbol string) (balance decimal.Decimal, err error) {
+	balances, err := bybit.GetSpotWalletBalanceCtx(ctx)
 
 	for _, coin := range balances {
 		if coin.Coin == symbol {
-			balance, err := strconv.ParseFloat(coin.Free, 64)
-			return balance, err
+			return coin.Free, err
 		}
 	}
 
@@ -173,24 +474,17 @@ func (bybit *BybitExchange) GetSpotWalletBalanceForSymbol(symbol string) (balanc
 	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-balance
 */
 func (bybit *BybitExchange) GetSpotWalletBalance() (balances []SpotBalance, err error) {
+	return bybit.GetSpotWalletBalanceCtx(context.Background())
+}
+
+// Same as GetSpotWalletBalance, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotWalletBalanceCtx(ctx context.Context) (balances []SpotBalance, err error) {
 	functionName := "GetSpotWalletBalance"
 	// create request
-	req := bybit.signRequest(http.MethodGet, GET_SPOT_BALANCE, nil)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newSignedRequest(http.MethodGet, GET_SPOT_BALANCE, nil)
 
 	var response = new(ResponseForGetSpotWalletBalance)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return balances, err
-	}
-
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return balances, err
 	}
 
@@ -198,7 +492,8 @@ func (bybit *BybitExchange) GetSpotWalletBalance() (balances []SpotBalance, err
 }
 
 /*
-	Gets the entire perp wallet balance.
+	Gets the entire perp wallet balance. Linear contracts are margined from the
+	"USDT" wallet, inverse ones from the wallet of their base coin.
 
 	Requires:
 		-
@@ -210,25 +505,18 @@ func (bybit *BybitExchange) GetSpotWalletBalance() (balances []SpotBalance, err
 	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-balance
 */
 func (bybit *BybitExchange) GetPerpWalletBalance() (balances map[string]PerpBalance, err error) {
+	return bybit.GetPerpWalletBalanceCtx(context.Background())
+}
+
+// Same as GetPerpWalletBalance, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpWalletBalanceCtx(ctx context.Context) (balances map[string]PerpBalance, err error) {
 	functionName := "GetPerpWalletBalance"
 
 	// create request
-	req := bybit.signRequest(http.MethodGet, GET_PERP_BALANCE, nil)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newSignedRequest(http.MethodGet, GET_PERP_BALANCE, nil)
 
 	var response = new(ResponseForGetPerpWalletBalance)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return balances, err
-	}
-
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return balances, err
 	}
 
@@ -243,75 +531,115 @@ func (bybit *BybitExchange) GetPerpWalletBalance() (balances map[string]PerpBala
 
 	Returns:
 		balance float64
-		err error
+		err error - a coin whose price can't be fetched fails the total rather than counting as zero
 
 	Refs: https://bybit-exchange.github.io/docs/spot/v1/#t-wallet
 */
 func (bybit *BybitExchange) GetTotalAcctUsdValue() (balance float64, err error) {
-	spotBalances, err := bybit.GetSpotWalletBalance()
+	return bybit.GetTotalAcctUsdValueCtx(context.Background())
+}
 
-	var price float64
-	for _, coin := range spotBalances {
-		if amount, err := strconv.ParseFloat(coin.Total, 32); err == nil {
-			if coin.Coin == "USDT" {
-				price = 1
-			} else {
-				price, _ = bybit.GetMarketPrice(coin.Coin, "USDT", "spot")
-			}
-			balance += amount * price
-		}
-	}
+// Same as GetTotalAcctUsdValue, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetTotalAcctUsdValueCtx(ctx context.Context) (balance float64, err error) {
+	total, err := bybit.GetTotalAcctUsdValueDecimalCtx(ctx)
+	return total.InexactFloat64(), err
+}
 
-	perpBalances, err := bybit.GetPerpWalletBalance()
-	for coinName, coin := range perpBalances {
-		if coin.WalletBalance > 0 {
-			price, _ = bybit.GetMarketPrice(coinName, "USDT", "perp")
-			balance += coin.WalletBalance * price
-		}
-	}
+// Same as GetTotalAcctUsdValue, returning the exact value
+func (bybit *BybitExchange) GetTotalAcctUsdValueDecimal() (balance decimal.Decimal, err error) {
+	return bybit.GetTotalAcctUsdValueDecimalCtx(context.Background())
+}
 
-	return balance, err
+// Same as GetTotalAcctUsdValueDecimal, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetTotalAcctUsdValueDecimalCtx(ctx context.Context) (balance decimal.Decimal, err error) {
+	return totalUsdValue(ctx, bybit.GetSpotWalletBalanceCtx, bybit.GetPerpWalletBalanceCtx, bybit.GetMarketPriceDecimalCtx)
 }
 
 /*
-	Gets perp position of symbol.
+	Gets perp position of symbol. Linear positions are listed per side, the open
+	one is returned, or a "None" side position when flat. In hedge mode both
+	sides can be open, GetPerpPositions returns them both.
 
 	Requires:
-		symbol string
+		symbol string - e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
 
 	Returns:
 		position PerpPosition
-		err error
+		err error - wraps ErrHedgedPosition when both hedge legs are open
 
 	Refs: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-myposition
+	Refs: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-myposition
 */
 func (bybit *BybitExchange) GetPerpPosition(symbol string) (position PerpPosition, err error) {
+	return bybit.GetPerpPositionCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpPosition, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpPositionCtx(ctx context.Context, symbol string) (position PerpPosition, err error) {
 	functionName := "GetPerpPosition"
 	params := map[string]interface{}{}
 	params["symbol"] = symbol
-	// create request
-	req := bybit.signRequest(http.MethodGet, GET_PERP_POSITION, params)
 
-	body, err := bybit.getResponseBody(functionName, req)
-
-	var response = new(ResponseForGetPerpPositions)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
 		return position, err
 	}
+	if isLinear {
+		positions, err := bybit.getLinearPositions(ctx, functionName, symbol)
+		if err != nil {
+			return position, err
+		}
+		return openLinearPosition(functionName, symbol, positions)
+	}
+
+	// create request
+	req := newSignedRequest(http.MethodGet, GET_PERP_POSITION, params)
 
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	var response = new(ResponseForGetPerpPositions)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return position, err
 	}
 
 	return response.Result, err
 }
 
+/*
+	Gets every leg of a perp position. Linear positions have a leg per side,
+	flat legs included, told apart in hedge mode by PositionIdx. Inverse
+	positions have a single leg.
+
+	Requires:
+		symbol string - e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+
+	Returns:
+		positions []PerpPosition
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-myposition
+	Refs: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-myposition
+*/
+func (bybit *BybitExchange) GetPerpPositions(symbol string) (positions []PerpPosition, err error) {
+	return bybit.GetPerpPositionsCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpPositions, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpPositionsCtx(ctx context.Context, symbol string) (positions []PerpPosition, err error) {
+	functionName := "GetPerpPositions"
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return positions, err
+	}
+	if isLinear {
+		return bybit.getLinearPositions(ctx, functionName, symbol)
+	}
+
+	position, err := bybit.GetPerpPositionCtx(ctx, symbol)
+	if err != nil {
+		return positions, err
+	}
+	return []PerpPosition{position}, nil
+}
+
 /*
 	Gets deposit address for symbol.
 
@@ -326,27 +654,20 @@ func (bybit *BybitExchange) GetPerpPosition(symbol string) (position PerpPositio
 	Refs: https://bybit-exchange.github.io/docs/account_asset/v1/#t-deposit_addr_info
 */
 func (bybit *BybitExchange) GetDepositAddress(symbol, network string) (address string, err error) {
+	return bybit.GetDepositAddressCtx(context.Background(), symbol, network)
+}
+
+// Same as GetDepositAddress, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetDepositAddressCtx(ctx context.Context, symbol, network string) (address string, err error) {
 	functionName := "GetDepositAddress"
 	params := map[string]interface{}{}
 	params["coin"] = symbol
 
 	// create request
-	req := bybit.signRequest(http.MethodGet, GET_DEPOSIT_ADDRESS, params)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newSignedRequest(http.MethodGet, GET_DEPOSIT_ADDRESS, params)
 
 	var response = new(ResponseForGetDepositAddress)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return address, err
-	}
-
-	if response.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.RetMsg)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return address, err
 	}
 
@@ -357,6 +678,12 @@ func (bybit *BybitExchange) GetDepositAddress(symbol, network string) (address s
 	// 	}
 	// }
 
+	if len(response.Result.Chains) == 0 {
+		err = fmt.Errorf("%v failed: no deposit address for %v", functionName, symbol)
+		bybit.logger.Error(err.Error())
+		return address, err
+	}
+
 	return response.Result.Chains[0].AddressDeposit, err
 }
 
@@ -373,12 +700,28 @@ func (bybit *BybitExchange) GetDepositAddress(symbol, network string) (address s
 		err error
 */
 func (bybit *BybitExchange) GetMarketPrice(baseCurr, quoteCurr, marketType string) (marketPrice float64, err error) {
+	return bybit.GetMarketPriceCtx(context.Background(), baseCurr, quoteCurr, marketType)
+}
+
+// Same as GetMarketPrice, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetMarketPriceCtx(ctx context.Context, baseCurr, quoteCurr, marketType string) (marketPrice float64, err error) {
+	price, err := bybit.GetMarketPriceDecimalCtx(ctx, baseCurr, quoteCurr, marketType)
+	return price.InexactFloat64(), err
+}
+
+// Same as GetMarketPrice, returning the exact price
+func (bybit *BybitExchange) GetMarketPriceDecimal(baseCurr, quoteCurr, marketType string) (marketPrice decimal.Decimal, err error) {
+	return bybit.GetMarketPriceDecimalCtx(context.Background(), baseCurr, quoteCurr, marketType)
+}
+
+// Same as GetMarketPriceDecimal, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetMarketPriceDecimalCtx(ctx context.Context, baseCurr, quoteCurr, marketType string) (marketPrice decimal.Decimal, err error) {
 	symbol := baseCurr + quoteCurr
 	switch marketType {
 	case "perp":
-		return bybit.GetPerpMarketPrice(symbol)
+		return bybit.GetPerpMarketPriceDecimalCtx(ctx, symbol)
 	case "spot":
-		return bybit.GetSpotMarketPrice(symbol)
+		return bybit.GetSpotMarketPriceDecimalCtx(ctx, symbol)
 	}
 	return marketPrice, err
 }
@@ -396,34 +739,67 @@ func (bybit *BybitExchange) GetMarketPrice(baseCurr, quoteCurr, marketType strin
 	Refs: https://bybit-exchange.github.io/docs/spot/v1/#t-bestbidask
 */
 func (bybit *BybitExchange) GetSpotMarketPrice(symbol string) (marketPrice float64, err error) {
-	functionName := "GetSpotMarketPrice"
+	return bybit.GetSpotMarketPriceCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotMarketPrice, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotMarketPriceCtx(ctx context.Context, symbol string) (marketPrice float64, err error) {
+	price, err := bybit.GetSpotMarketPriceDecimalCtx(ctx, symbol)
+	return price.InexactFloat64(), err
+}
+
+// Same as GetSpotMarketPrice, returning the exact price
+func (bybit *BybitExchange) GetSpotMarketPriceDecimal(symbol string) (marketPrice decimal.Decimal, err error) {
+	return bybit.GetSpotMarketPriceDecimalCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotMarketPriceDecimal, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotMarketPriceDecimalCtx(ctx context.Context, symbol string) (marketPrice decimal.Decimal, err error) {
+	bookTicker, err := bybit.GetSpotBookTickerCtx(ctx, symbol)
+	if err != nil {
+		return marketPrice, err
+	}
+
+	return decimal.NewFromString(bookTicker.BidPrice)
+}
+
+/*
+	Gets spot best bid and ask price and quantity.
+
+	Requires:
+		symbol (baseCurr + quoteCurr) string
+
+	Returns:
+		bookTicker SpotMarketPrice
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/spot/v1/#t-bestbidask
+*/
+func (bybit *BybitExchange) GetSpotBookTicker(symbol string) (bookTicker SpotMarketPrice, err error) {
+	return bybit.GetSpotBookTickerCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotBookTicker, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotBookTickerCtx(ctx context.Context, symbol string) (bookTicker SpotMarketPrice, err error) {
+	functionName := "GetSpotBookTicker"
 	params := map[string]interface{}{}
 	params["symbol"] = symbol
 
 	// create request
-	req := bybit.createRequest(http.MethodGet, SPOT_MARKET_PRICE, params)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newPublicRequest(http.MethodGet, SPOT_MARKET_PRICE, params)
 
-	// parse response
 	var response = new(ResponseForGetSpotMarketPrice)
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return marketPrice, err
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return bookTicker, err
 	}
 
 	if response.Result.BidPrice == "" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return marketPrice, err
+		err = fmt.Errorf("%v failed: empty order book for %v", functionName, symbol)
+		bybit.logger.Error(err.Error())
+		return bookTicker, err
 	}
 
-	price, err := strconv.ParseFloat(response.Result.BidPrice, 64)
-	return price, err
+	return response.Result, err
 }
 
 /*
@@ -439,35 +815,36 @@ func (bybit *BybitExchange) GetSpotMarketPrice(symbol string) (marketPrice float
 	Refs: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-latestsymbolinfo
 */
 func (bybit *BybitExchange) GetPerpMarketPrice(symbol string) (marketPrice float64, err error) {
+	return bybit.GetPerpMarketPriceCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpMarketPrice, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpMarketPriceCtx(ctx context.Context, symbol string) (marketPrice float64, err error) {
+	price, err := bybit.GetPerpMarketPriceDecimalCtx(ctx, symbol)
+	return price.InexactFloat64(), err
+}
+
+// Same as GetPerpMarketPrice, returning the exact price
+func (bybit *BybitExchange) GetPerpMarketPriceDecimal(symbol string) (marketPrice decimal.Decimal, err error) {
+	return bybit.GetPerpMarketPriceDecimalCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpMarketPriceDecimal, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpMarketPriceDecimalCtx(ctx context.Context, symbol string) (marketPrice decimal.Decimal, err error) {
 	// runtime variables
 	functionName := "GetPerpMarketPrice"
 	params := map[string]interface{}{}
 	params["symbol"] = symbol
 
 	// create request
-	req := bybit.createRequest(http.MethodGet, TICKER, params)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newPublicRequest(http.MethodGet, TICKER, params)
 
-	// parse response
 	var response = new(ResponseForGetPerpMarketPrice)
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return marketPrice, err
 	}
 
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return marketPrice, err
-	}
-
-	price, err := strconv.ParseFloat(response.Result[0].MarkPrice, 64)
-	return price, err
+	return decimal.NewFromString(response.Result[0].MarkPrice)
 }
 
 /*
@@ -484,39 +861,94 @@ func (bybit *BybitExchange) GetPerpMarketPrice(symbol string) (marketPrice float
 	Refs: https://bybit-exchange.github.io/docs/testnet/futuresV2/inverse/#t-querysymbol
 */
 func (bybit *BybitExchange) GetPerpTickSize(symbol string) (maxPrice, tickSize float64, err error) {
-	functionName := "GetPerpTickSize"
+	return bybit.GetPerpTickSizeCtx(context.Background(), symbol)
+}
 
-	// create request
-	req := bybit.createRequest(http.MethodGet, GET_PERP_SYMBOLS_INFO, nil)
+// Same as GetPerpTickSize, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpTickSizeCtx(ctx context.Context, symbol string) (maxPrice, tickSize float64, err error) {
+	instrument, err := bybit.instruments.BySymbol(ctx, exchange.MARKET_TYPE_PERP, symbol)
+	if err != nil {
+		return maxPrice, tickSize, err
+	}
 
-	body, err := bybit.getResponseBody(functionName, req)
+	return instrument.MaxPrice.InexactFloat64(), instrument.TickSize.InexactFloat64(), nil
+}
 
-	// parse response
-	var response = new(ResponseForGetPerpSymbolsInfo)
+/*
+	Tells an inverse perp, margined in its base coin and sized in USD, from a
+	linear one by the contract type in the instrument registry, so dated
+	contracts such as "BTCUSDZ22" are told apart too.
 
-	if err := json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return maxPrice, tickSize, err
+	Requires:
+		symbol string - e.g. "BTCUSD"
+
+	Returns:
+		isInverse bool
+		err error
+*/
+func (bybit *BybitExchange) IsInversePerp(symbol string) (isInverse bool, err error) {
+	return bybit.IsInversePerpCtx(context.Background(), symbol)
+}
+
+// Same as IsInversePerp, cancelled or timed out through ctx
+func (bybit *BybitExchange) IsInversePerpCtx(ctx context.Context, symbol string) (isInverse bool, err error) {
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return isInverse, err
 	}
+	return !isLinear, nil
+}
 
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return maxPrice, tickSize, err
+/*
+	Gets the trading rules of every spot pair. Prefer Instruments() which caches them.
+
+	Returns:
+		spotSymbols []SpotSymbolInfo
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/spot/v1/#t-querysymbol
+*/
+func (bybit *BybitExchange) GetSpotSymbols() (spotSymbols []SpotSymbolInfo, err error) {
+	return bybit.GetSpotSymbolsCtx(context.Background())
+}
+
+// Same as GetSpotSymbols, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetSpotSymbolsCtx(ctx context.Context) (spotSymbols []SpotSymbolInfo, err error) {
+	functionName := "GetSpotSymbols"
+
+	req := newPublicRequest(http.MethodGet, GET_SPOT_SYMBOLS_INFO, nil)
+	var response = new(ResponseForGetSpotSymbolsInfo)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return spotSymbols, err
 	}
 
-	for _, perpSymbolInfo := range response.Result {
-		if perpSymbolInfo.Name == symbol {
-			maxPrice, err = strconv.ParseFloat(perpSymbolInfo.PriceFilter.MaxPrice, 64)
-			tickSize, err = strconv.ParseFloat(perpSymbolInfo.PriceFilter.TickSize, 64)
-			return maxPrice, tickSize, err
-		}
+	return response.Result, nil
+}
+
+/*
+	Gets the trading rules of every derivatives contract. Prefer Instruments() which caches them.
+
+	Returns:
+		perpSymbols []PerpSymbolInfo
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-querysymbol
+*/
+func (bybit *BybitExchange) GetPerpSymbols() (perpSymbols []PerpSymbolInfo, err error) {
+	return bybit.GetPerpSymbolsCtx(context.Background())
+}
+
+// Same as GetPerpSymbols, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpSymbolsCtx(ctx context.Context) (perpSymbols []PerpSymbolInfo, err error) {
+	functionName := "GetPerpSymbols"
+
+	req := newPublicRequest(http.MethodGet, GET_PERP_SYMBOLS_INFO, nil)
+	var response = new(ResponseForGetPerpSymbolsInfo)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return perpSymbols, err
 	}
 
-	return maxPrice, tickSize, err
+	return response.Result, nil
 }
 
 /*
@@ -530,25 +962,17 @@ func (bybit *BybitExchange) GetPerpTickSize(symbol string) (maxPrice, tickSize f
 	Refs: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-servertime
 */
 func (bybit *BybitExchange) GetServerTime() (serverTime float64, err error) {
+	return bybit.GetServerTimeCtx(context.Background())
+}
+
+// Same as GetServerTime, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetServerTimeCtx(ctx context.Context) (serverTime float64, err error) {
 	functionName := "GetServerTime"
 	url := SERVER_TIME
 
-	req := bybit.createRequest(http.MethodGet, url, nil)
-	body, err := bybit.getResponseBody(functionName, req)
-
-	// parse response
+	req := newPublicRequest(http.MethodGet, url, nil)
 	var response = new(ResponseForGetServerTime)
-	if err := json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("get server time failed to parse response body: %v", err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return serverTime, err
-	}
-
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("get server time failed: %v", response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return serverTime, err
 	}
 
@@ -559,7 +983,10 @@ func (bybit *BybitExchange) GetServerTime() (serverTime float64, err error) {
 // ---------------------------- POST CALLS ----------------------------
 
 /*
-	Creates a spot order.
+	Creates a spot order at most once. Generates an OrderLinkId when none is set,
+	and looks the order up by it before resending an ambiguous placement.
+	With WithOrderValidation the order is first checked against the instrument
+	filters and a *ValidationError returned without sending it.
 
 	Requires:
 		params PlaceSpotOrderParams
@@ -571,35 +998,40 @@ func (bybit *BybitExchange) GetServerTime() (serverTime float64, err error) {
 	Ref : https://bybit-exchange.github.io/docs/spot/v1/#t-placeactive
 */
 func (bybit *BybitExchange) PlaceSpotOrder(params PlaceSpotOrderParams) (orderId string, err error) {
+	return bybit.PlaceSpotOrderCtx(context.Background(), params)
+}
+
+// Same as PlaceSpotOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) PlaceSpotOrderCtx(ctx context.Context, params PlaceSpotOrderParams) (orderId string, err error) {
 	functionName := "PlaceSpotOrder"
+	if bybit.orderValidation != nil {
+		if params, err = bybit.ValidateSpotOrderCtx(ctx, params); err != nil {
+			return orderId, err
+		}
+	}
+	if params.OrderLinkId == "" {
+		params.OrderLinkId = newOrderLinkId()
+	}
 	// prepare request body by turning exchange.PlaceSpotOrderParams into map[string]interface{}:
 	params_map := structs.Map(&params)
 
 	// create request
-	req := bybit.signRequest(http.MethodPost, SPOT_ORDER, params_map)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newSignedRequest(http.MethodPost, SPOT_ORDER, params_map)
 
 	var response = new(ResponseForPlaceSpotOrder)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return orderId, err
-	}
-
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return orderId, err
-	}
-
-	return response.Result.OrderId, err
+	return bybit.placeOrder(ctx, functionName, params.OrderLinkId, req, response,
+		func() string { return response.Result.OrderId },
+		func(ctx context.Context) (string, error) {
+			spotOrder, err := bybit.GetSpotOrderByLinkIdCtx(ctx, params.OrderLinkId)
+			return spotOrder.OrderId, err
+		})
 }
 
 /*
-	Creates a perp order
+	Creates a perp order at most once. Generates an OrderLinkId when none is set,
+	and looks the order up by it before resending an ambiguous placement.
+	With WithOrderValidation the order is first checked against the instrument
+	filters and a *ValidationError returned without sending it.
 
 	Requires:
 		params PlacePerpOrderParams
@@ -608,34 +1040,48 @@ func (bybit *BybitExchange) PlaceSpotOrder(params PlaceSpotOrderParams) (orderId
 		orderId string
 		err error
 
+	Qty is in USD for inverse contracts and in the base coin for linear ones,
+	which are placed on the linear endpoints.
+
 	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-activeorders
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-placeactive
 */
 func (bybit *BybitExchange) PlacePerpOrder(params PlacePerpOrderParams) (orderId string, err error) {
+	return bybit.PlacePerpOrderCtx(context.Background(), params)
+}
+
+// Same as PlacePerpOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) PlacePerpOrderCtx(ctx context.Context, params PlacePerpOrderParams) (orderId string, err error) {
 	functionName := "PlacePerpOrder"
+	if bybit.orderValidation != nil {
+		if params, err = bybit.ValidatePerpOrderCtx(ctx, params); err != nil {
+			return orderId, err
+		}
+	}
+	if params.OrderLinkId == "" {
+		params.OrderLinkId = newOrderLinkId()
+	}
+	isLinear, err := bybit.isLinear(ctx, params.Symbol)
+	if err != nil {
+		return orderId, err
+	}
+	endpoint := PLACE_PERP_ORDER
+	if isLinear {
+		endpoint = PLACE_LINEAR_ORDER
+	}
 	// prepare request body by turning exchange.PlacePerpOrderParams into map[string]interface{}:
 	params_map := structs.Map(&params)
 
 	// create request
-	req := bybit.signRequest(http.MethodPost, PLACE_PERP_ORDER, params_map)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newSignedRequest(http.MethodPost, endpoint, params_map)
 
 	var response = new(ResponseForPlacePerpOrder)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return orderId, err
-	}
-
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return orderId, err
-	}
-
-	return response.Result.OrderId, err
+	return bybit.placeOrder(ctx, functionName, params.OrderLinkId, req, response,
+		func() string { return response.Result.OrderId },
+		func(ctx context.Context) (string, error) {
+			perpOrder, err := bybit.GetPerpOrderByLinkIdCtx(ctx, params.Symbol, params.OrderLinkId)
+			return perpOrder.OrderId, err
+		})
 }
 
 /*
@@ -654,31 +1100,34 @@ func (bybit *BybitExchange) PlacePerpOrder(params PlacePerpOrderParams) (orderId
 	Refs: https://bybit-exchange.github.io/docs/account_asset/v1/#t-withdraw_info
 */
 func (bybit *BybitExchange) WithdrawFromExchange(coin string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error) {
+	return bybit.WithdrawFromExchangeCtx(context.Background(), coin, amount, destinationAddress, network)
+}
+
+// Same as WithdrawFromExchange, cancelled or timed out through ctx
+func (bybit *BybitExchange) WithdrawFromExchangeCtx(ctx context.Context, coin string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error) {
+	return bybit.WithdrawFromExchangeDecimalCtx(ctx, coin, decimal.NewFromFloat(amount), destinationAddress, network)
+}
+
+// Same as WithdrawFromExchange, with an exact amount
+func (bybit *BybitExchange) WithdrawFromExchangeDecimal(coin string, amount decimal.Decimal, destinationAddress, network string) (withdrawOrderId string, err error) {
+	return bybit.WithdrawFromExchangeDecimalCtx(context.Background(), coin, amount, destinationAddress, network)
+}
+
+// Same as WithdrawFromExchangeDecimal, cancelled or timed out through ctx
+func (bybit *BybitExchange) WithdrawFromExchangeDecimalCtx(ctx context.Context, coin string, amount decimal.Decimal, destinationAddress, network string) (withdrawOrderId string, err error) {
 	functionName := "WithdrawFromExchange"
 
 	params := map[string]interface{}{}
 	params["coin"] = coin
-	params["amount"] = fmt.Sprintf("%f", amount)
+	params["amount"] = amount.String()
 	params["chain"] = network
 	params["address"] = destinationAddress
 
 	// create request
-	req := bybit.signRequestWithSignAsABodyParam(http.MethodPost, WITHDRAW_FROM_SPOT_WALLET, params)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newSignedBodyRequest(http.MethodPost, WITHDRAW_FROM_SPOT_WALLET, params)
 
 	var response = new(ResponseForWithdrawlFromSpotWallet)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return withdrawOrderId, err
-	}
-
-	if response.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.RetMsg)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return withdrawOrderId, err
 	}
 
@@ -700,27 +1149,20 @@ func (bybit *BybitExchange) WithdrawFromExchange(coin string, amount float64, de
 	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-cancelactive
 */
 func (bybit *BybitExchange) CancelSpotOrder(orderId string) (status bool, err error) {
+	return bybit.CancelSpotOrderCtx(context.Background(), orderId)
+}
+
+// Same as CancelSpotOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) CancelSpotOrderCtx(ctx context.Context, orderId string) (status bool, err error) {
 	functionName := "CancelSpotOrder"
 	params := map[string]interface{}{}
 	params["orderId"] = orderId
 
 	// create request
-	req := bybit.signRequest(http.MethodDelete, SPOT_ORDER, params)
-
-	body, err := bybit.getResponseBody(functionName, req)
+	req := newSignedRequest(http.MethodDelete, SPOT_ORDER, params)
 
 	var response = new(ResponseForCancelSpotOrder)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return false, err
-	}
-
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return false, err
 	}
 
@@ -739,30 +1181,33 @@ func (bybit *BybitExchange) CancelSpotOrder(orderId string) (status bool, err er
 		err error
 
 	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-cancelactive
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-cancelactive
 */
 func (bybit *BybitExchange) CancelPerpOrder(symbol, orderId string) (status bool, err error) {
+	return bybit.CancelPerpOrderCtx(context.Background(), symbol, orderId)
+}
+
+// Same as CancelPerpOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) CancelPerpOrderCtx(ctx context.Context, symbol, orderId string) (status bool, err error) {
 	functionName := "CancelPerpOrder"
 	params := map[string]interface{}{}
 	params["symbol"] = symbol
 	params["order_id"] = orderId
 
-	// create request
-	req := bybit.signRequest(http.MethodPost, CANCEL_PERP_ORDER, params)
-
-	body, err := bybit.getResponseBody(functionName, req)
-
-	var response = new(ResponseForCancelPerpOrder)
-	if err = json.Unmarshal(body, &response); err != nil {
-		err_msg := fmt.Sprintf("%v failed to parse response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
 		return false, err
 	}
+	endpoint := CANCEL_PERP_ORDER
+	if isLinear {
+		endpoint = CANCEL_LINEAR_ORDER
+	}
 
-	if response.ApiResponse.RetMsg != "OK" {
-		err_msg := fmt.Sprintf("%v failed: %v", functionName, response.ApiResponse)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
+	// create request
+	req := newSignedRequest(http.MethodPost, endpoint, params)
+
+	var response = new(ResponseForCancelPerpOrder)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
 		return false, err
 	}
 
@@ -771,134 +1216,328 @@ func (bybit *BybitExchange) CancelPerpOrder(symbol, orderId string) (status bool
 
 // ---------------------------- HELPERS ----------------------------
 
-func (bybit *BybitExchange) getResponseBody(functionName string, req *http.Request) (body []byte, err error) {
-	// create request
+// values the spot and perp wallets in USD through the balance and price getters of either client.
+// A coin left unpriced would undervalue the account, so a failed price fails the total.
+func totalUsdValue(ctx context.Context, getSpotBalances func(context.Context) ([]SpotBalance, error), getPerpBalances func(context.Context) (map[string]PerpBalance, error), getPrice func(ctx context.Context, baseCurr, quoteCurr, marketType string) (decimal.Decimal, error)) (balance decimal.Decimal, err error) {
+	spotBalances, err := getSpotBalances(ctx)
 	if err != nil {
-		err_msg := fmt.Sprintf("%v failed to create request object: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return body, err
+		return balance, err
 	}
 
-	// make request
-	res, err := bybit.Client.Do(req)
-	if err != nil {
-		err_msg := fmt.Sprintf("%v failed to make request: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return body, err
+	var price decimal.Decimal
+	for _, coin := range spotBalances {
+		if !coin.Total.IsPositive() {
+			continue
+		}
+		if coin.Coin == "USDT" {
+			price = decimal.NewFromInt(1)
+		} else if price, err = getPrice(ctx, coin.Coin, "USDT", "spot"); err != nil {
+			return decimal.Zero, err
+		}
+		balance = balance.Add(coin.Total.Mul(price))
 	}
-	defer res.Body.Close()
 
-	// read response
-	body, err = ioutil.ReadAll(res.Body)
+	perpBalances, err := getPerpBalances(ctx)
 	if err != nil {
-		err_msg := fmt.Sprintf("%v failed to read response body: %v", functionName, err)
-		err = errors.New(err_msg)
-		log.Error(err.Error())
-		return body, err
+		return decimal.Zero, err
+	}
+	for coinName, coin := range perpBalances {
+		if !coin.WalletBalance.IsPositive() {
+			continue
+		}
+		// the USDT wallet backs the linear contracts and has no perp of its own to price it
+		if isStablecoin(coinName) {
+			price = decimal.NewFromInt(1)
+		} else if price, err = getPrice(ctx, coinName, "USDT", "perp"); err != nil {
+			return decimal.Zero, err
+		}
+		balance = balance.Add(coin.WalletBalance.Mul(price))
 	}
 
-	return body, err
+	return balance, nil
 }
 
-func (bybit *BybitExchange) signRequest(method string, path string, params map[string]interface{}) *http.Request {
-	if params == nil {
-		params = map[string]interface{}{}
+// fetches a page of spot orders older than the cursor order id
+func (bybit *BybitExchange) spotOrdersPage(functionName, path string, listParams SpotListParams) func(ctx context.Context, cursor string) ([]SpotOrder, string, error) {
+	limit := listParams.Limit
+	if limit <= 0 || limit > SPOT_ORDERS_PAGE_SIZE {
+		limit = SPOT_ORDERS_PAGE_SIZE
 	}
-	server_time, _ := bybit.GetServerTime()
-	// timestamp must be (in milliseconds): serverTime - recvWindow (default 5,000) <= timestamp < serverTime + 1000
-	timestamp := int(server_time*1000 - 100)
-
-	params["api_key"] = bybit.ApiKey
-	params["timestamp"] = fmt.Sprintf("%d", timestamp)
+	isTimeFiltered := path == GET_SPOT_ORDER_HISTORY
 
-	param := bybit.makeParamString(params)
+	return func(ctx context.Context, cursor string) (spotOrders []SpotOrder, nextCursor string, err error) {
+		params := map[string]interface{}{}
+		params["limit"] = limit
+		if listParams.Symbol != "" {
+			params["symbol"] = listParams.Symbol
+		}
+		if cursor != "" {
+			params["orderId"] = cursor
+		}
+		if isTimeFiltered {
+			addTimeRange(params, listParams.StartTime, listParams.EndTime)
+		}
 
-	signature := bybit.sign(param)
-	param += "&sign=" + signature
+		req := newSignedRequest(http.MethodGet, path, params)
+		var response = new(ResponseForGetSpotOrders)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return spotOrders, nextCursor, err
+		}
 
-	var fullURL string
-	if isTestnet {
-		fullURL = TESTNET_URL + path + "?" + param
-	} else {
-		fullURL = MAINNET_URL + path + "?" + param
+		page := response.Result
+		// orderId is exclusive, the next page starts below the oldest order of this one
+		if oldest, ok := oldestId(page, func(spotOrder SpotOrder) string { return spotOrder.OrderId }); ok && len(page) == limit {
+			nextCursor = strconv.FormatInt(oldest, 10)
+		}
+		for _, spotOrder := range page {
+			if isTimeFiltered || isInTimeRange(parseMillis(spotOrder.Time), listParams.StartTime, listParams.EndTime) {
+				spotOrders = append(spotOrders, spotOrder)
+			}
+		}
+		return spotOrders, nextCursor, nil
 	}
-
-	req, _ := http.NewRequest(method, fullURL, bytes.NewReader(make([]byte, 0)))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	return req
 }
 
-func (bybit *BybitExchange) signRequestWithSignAsABodyParam(method string, path string, params map[string]interface{}) *http.Request {
-	if params == nil {
-		params = map[string]interface{}{}
+// fetches a page of spot trades older than the cursor ticket id
+func (bybit *BybitExchange) spotTradesPage(functionName string, listParams SpotListParams) func(ctx context.Context, cursor string) ([]SpotTrade, string, error) {
+	limit := listParams.Limit
+	if limit <= 0 || limit > SPOT_TRADES_PAGE_SIZE {
+		limit = SPOT_TRADES_PAGE_SIZE
 	}
-	server_time, _ := bybit.GetServerTime()
-	// timestamp must be (in milliseconds): serverTime - recvWindow (default 5,000) <= timestamp < serverTime + 1000
-	timestamp := int(server_time*1000 - 100)
 
-	params["api_key"] = bybit.ApiKey
-	params["timestamp"] = fmt.Sprintf("%d", timestamp)
+	return func(ctx context.Context, cursor string) (spotTrades []SpotTrade, nextCursor string, err error) {
+		params := map[string]interface{}{}
+		params["limit"] = limit
+		if listParams.Symbol != "" {
+			params["symbol"] = listParams.Symbol
+		}
+		if cursor != "" {
+			params["toTicketId"] = cursor
+		}
+		addTimeRange(params, listParams.StartTime, listParams.EndTime)
 
-	param := bybit.makeParamString(params)
+		req := newSignedRequest(http.MethodGet, GET_SPOT_TRADE_HISTORY, params)
+		var response = new(ResponseForGetSpotTrades)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return spotTrades, nextCursor, err
+		}
 
-	signature := bybit.sign(param)
-	params["sign"] = signature
+		// toTicketId is inclusive
+		if oldest, ok := oldestId(response.Result, func(spotTrade SpotTrade) string { return spotTrade.TicketId }); ok && len(response.Result) == limit && oldest > 1 {
+			nextCursor = strconv.FormatInt(oldest-1, 10)
+		}
+		return response.Result, nextCursor, nil
+	}
+}
 
-	postBody, _ := json.Marshal(params)
+// whether a perp settles in USDT and so trades on the linear endpoints
+func (bybit *BybitExchange) isLinear(ctx context.Context, symbol string) (bool, error) {
+	instrument, err := bybit.instruments.BySymbol(ctx, exchange.MARKET_TYPE_PERP, symbol)
+	if err != nil {
+		return false, err
+	}
+	return instrument.ContractType == CONTRACT_TYPE_LINEAR_PERPETUAL || instrument.ContractType == CONTRACT_TYPE_LINEAR_FUTURES, nil
+}
 
-	var fullURL string
-	if isTestnet {
-		fullURL = TESTNET_URL + path
-	} else {
-		fullURL = MAINNET_URL + path
+// lists both sides of a linear position
+func (bybit *BybitExchange) getLinearPositions(ctx context.Context, functionName, symbol string) (positions []PerpPosition, err error) {
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+
+	req := newSignedRequest(http.MethodGet, GET_LINEAR_POSITION, params)
+	var response = new(ResponseForGetLinearPositions)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return positions, err
 	}
+	return response.Result, nil
+}
 
-	req, _ := http.NewRequest(method, fullURL, bytes.NewBuffer(postBody))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	return req
+// picks the open side of a linear position, or a flat "None" side one like the inverse endpoint returns.
+// With both hedge legs open neither is picked, a single position would hide half the exposure.
+func openLinearPosition(functionName, symbol string, positions []PerpPosition) (position PerpPosition, err error) {
+	openLegs := 0
+	for _, leg := range positions {
+		if leg.Size.IsPositive() {
+			position = leg
+			openLegs++
+		}
+	}
+	switch {
+	case openLegs > 1:
+		return PerpPosition{}, fmt.Errorf("%v failed: both %v legs are open, use GetPerpPositions: %w", functionName, symbol, ErrHedgedPosition)
+	case openLegs == 1:
+		return position, nil
+	case len(positions) == 0:
+		return PerpPosition{Symbol: symbol, Side: "None"}, nil
+	}
+	position = positions[0]
+	position.Side = "None"
+	return position, nil
 }
 
-func (bybit *BybitExchange) makeParamString(params map[string]interface{}) (param string) {
-	var keys []string
-	for k := range params {
-		keys = append(keys, k)
+func linearOrderToPerpOrder(order LinearOrder) PerpOrder {
+	return PerpOrder{
+		UserId:      order.UserId,
+		PositionIdx: order.PositionIdx,
+		OrderStatus: order.OrderStatus,
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		OrderType:   order.OrderType,
+		Price:       order.Price.String(),
+		Qty:         order.Qty.String(),
+		TimeInForce: order.TimeInForce,
+		OrderLinkId: order.OrderLinkId,
+		OrderId:     order.OrderId,
+		CreatedAt:   order.CreatedTime,
+		UpdatedAt:   order.UpdatedTime,
+		LeavesQty:   order.LeavesQty.String(),
+		CumExecQty:  order.CumExecQty.String(),
+		CumExecFee:  order.CumExecFee.String(),
+		TakeProfit:  order.TakeProfit.String(),
+		StopLoss:    order.StopLoss.String(),
+		TpTriggerBy: order.TpTriggerBy,
+		SlTriggerBy: order.SlTriggerBy,
 	}
-	// keys must be sorted in alphabetical order when signed
-	sort.Strings(keys)
+}
 
-	var p []string
-	for _, k := range keys {
-		p = append(p, fmt.Sprintf("%v=%v", k, params[k]))
+// fetches a numbered page of perp executions, the cursor is the page number
+func (bybit *BybitExchange) perpExecutionsPage(functionName string, listParams PerpListParams) func(ctx context.Context, cursor string) ([]PerpExecution, string, error) {
+	limit := listParams.Limit
+	if limit <= 0 || limit > PERP_EXECUTIONS_PAGE_SIZE {
+		limit = PERP_EXECUTIONS_PAGE_SIZE
 	}
 
-	param = strings.Join(p, "&")
-	return param
+	return func(ctx context.Context, cursor string) (executions []PerpExecution, nextCursor string, err error) {
+		isLinear, err := bybit.isLinear(ctx, listParams.Symbol)
+		if err != nil {
+			return executions, nextCursor, err
+		}
+		page := 1
+		if cursor != "" {
+			page, _ = strconv.Atoi(cursor)
+		}
+
+		params := map[string]interface{}{}
+		params["symbol"] = listParams.Symbol
+		params["limit"] = limit
+		params["page"] = page
+
+		var received []PerpExecution
+		if isLinear {
+			if listParams.ExecType != "" {
+				params["exec_type"] = listParams.ExecType
+			}
+			// linear takes milliseconds
+			if !listParams.StartTime.IsZero() {
+				params["start_time"] = listParams.StartTime.UnixMilli()
+			}
+			if !listParams.EndTime.IsZero() {
+				params["end_time"] = listParams.EndTime.UnixMilli()
+			}
+			req := newSignedRequest(http.MethodGet, GET_LINEAR_EXECUTIONS, params)
+			var response = new(ResponseForGetLinearExecutions)
+			if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+				return executions, nextCursor, err
+			}
+			received = response.Result.Data
+		} else {
+			// inverse takes seconds and has no end time or exec type, they're applied to the executions received
+			if !listParams.StartTime.IsZero() {
+				params["start_time"] = listParams.StartTime.Unix()
+			}
+			req := newSignedRequest(http.MethodGet, GET_PERP_EXECUTIONS, params)
+			var response = new(ResponseForGetPerpExecutions)
+			if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+				return executions, nextCursor, err
+			}
+			received = response.Result.TradeList
+		}
+
+		if len(received) == limit && page < PERP_MAX_PAGE {
+			nextCursor = strconv.Itoa(page + 1)
+		}
+		for _, execution := range received {
+			if listParams.ExecType != "" && execution.ExecType != listParams.ExecType {
+				continue
+			}
+			if isInTimeRange(time.UnixMilli(execution.TradeTimeMs), listParams.StartTime, listParams.EndTime) {
+				execution.Inverse = !isLinear
+				executions = append(executions, execution)
+			}
+		}
+		return executions, nextCursor, nil
+	}
 }
 
-func (bybit *BybitExchange) createRequest(method, path string, params map[string]interface{}) *http.Request {
-	if params == nil {
-		params = map[string]interface{}{}
+// fetches a numbered page of perp closed pnl records, the cursor is the page number
+func (bybit *BybitExchange) perpClosedPnlPage(functionName string, listParams PerpListParams) func(ctx context.Context, cursor string) ([]PerpClosedPnl, string, error) {
+	limit := listParams.Limit
+	if limit <= 0 || limit > PERP_CLOSED_PNL_PAGE_SIZE {
+		limit = PERP_CLOSED_PNL_PAGE_SIZE
 	}
 
-	param := bybit.makeParamString(params)
+	return func(ctx context.Context, cursor string) (closedPnls []PerpClosedPnl, nextCursor string, err error) {
+		isLinear, err := bybit.isLinear(ctx, listParams.Symbol)
+		if err != nil {
+			return closedPnls, nextCursor, err
+		}
+		page := 1
+		if cursor != "" {
+			page, _ = strconv.Atoi(cursor)
+		}
 
-	var fullURL string
-	if isTestnet {
-		fullURL = TESTNET_URL + path + "?" + param
-	} else {
-		fullURL = MAINNET_URL + path + "?" + param
+		params := map[string]interface{}{}
+		params["symbol"] = listParams.Symbol
+		params["limit"] = limit
+		params["page"] = page
+		// both take seconds
+		if !listParams.StartTime.IsZero() {
+			params["start_time"] = listParams.StartTime.Unix()
+		}
+		if !listParams.EndTime.IsZero() {
+			params["end_time"] = listParams.EndTime.Unix()
+		}
+
+		endpoint := GET_PERP_CLOSED_PNL
+		if isLinear {
+			endpoint = GET_LINEAR_CLOSED_PNL
+		}
+		req := newSignedRequest(http.MethodGet, endpoint, params)
+		var response = new(ResponseForGetPerpClosedPnl)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return closedPnls, nextCursor, err
+		}
+
+		if len(response.Result.Data) == limit && page < PERP_MAX_PAGE {
+			nextCursor = strconv.Itoa(page + 1)
+		}
+		return response.Result.Data, nextCursor, nil
 	}
+}
 
-	req, _ := http.NewRequest(method, fullURL, bytes.NewReader(make([]byte, 0)))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	return req
+// returns the smallest id in page, false when the page is empty or an id isn't numeric
+func oldestId[T any](page []T, id func(T) string) (oldest int64, ok bool) {
+	for i, item := range page {
+		itemId, err := strconv.ParseInt(id(item), 10, 64)
+		if err != nil {
+			return oldest, false
+		}
+		if i == 0 || itemId < oldest {
+			oldest = itemId
+		}
+	}
+	return oldest, len(page) > 0
+}
+
+func addTimeRange(params map[string]interface{}, startTime, endTime time.Time) {
+	if !startTime.IsZero() {
+		params["startTime"] = startTime.UnixMilli()
+	}
+	if !endTime.IsZero() {
+		params["endTime"] = endTime.UnixMilli()
+	}
 }
 
-func (bybit *BybitExchange) sign(signaturePayload string) string {
-	mac := hmac.New(sha256.New, []byte(bybit.SecretKey))
-	mac.Write([]byte(signaturePayload))
-	return hex.EncodeToString(mac.Sum(nil))
+func isInTimeRange(t, startTime, endTime time.Time) bool {
+	return (startTime.IsZero() || !t.Before(startTime)) && (endTime.IsZero() || !t.After(endTime))
 }
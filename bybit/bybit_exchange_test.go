@@ -2,11 +2,26 @@ package bybit_exchange
 
 import (
 	// "encoding/json"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
+	"github.com/fatih/structs"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -28,8 +43,28 @@ func (suite *BybitTestSuite) SetupSuite() {
 	}
 	apiKey := viper.Get("BYBIT_API_KEY").(string)
 	secretKey := viper.Get("BYBIT_API_SECRET").(string)
-	NewBybitExchangeClient(secretKey, apiKey)
-	suite.Exchange, err = GetBybitExchangeRestService()
+	suite.Exchange = NewBybitExchange(secretKey, apiKey, WithEnvironment(ENV_TESTNET))
+}
+
+func TestNewBybitExchangeOptions(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestNewBybitExchangeOptions")
+
+	// Run test
+	testnet := NewBybitExchange("secret", "key")
+	mainnet := NewBybitExchange("secret", "key", WithEnvironment(ENV_MAINNET), WithRecvWindow(10000))
+	bytick := NewBybitExchange("secret", "key", WithEnvironment(ENV_BYTICK))
+	custom := NewBybitExchange("secret", "key", WithBaseURL("http://localhost:8080"))
+
+	fmt.Printf("Base urls: %v %v %v %v\n", testnet.BaseURL(), mainnet.BaseURL(), bytick.BaseURL(), custom.BaseURL())
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.Equal(TESTNET_URL, testnet.BaseURL())
+	assert.Equal(MAINNET_URL, mainnet.BaseURL())
+	assert.Equal(10000, mainnet.recvWindow)
+	assert.Equal(MAINNET_URL_2, bytick.BaseURL())
+	assert.Equal("http://localhost:8080", custom.BaseURL())
 }
 
 func (suite *BybitTestSuite) TestGetMarketPriceSpot() {
@@ -78,7 +113,7 @@ func (suite *BybitTestSuite) TestPlaceSpotOrder() {
 		Symbol: "BTCUSDT",
 		Side:   ORDER_SIDE_BUY,
 		Type:   ORDER_TYPE_MARKET,
-		Qty:    1.000,
+		Qty:    decimal.NewFromFloat(1.000),
 	}
 
 	// Run test
@@ -100,7 +135,7 @@ func (suite *BybitTestSuite) TestGetSpotOrder() {
 		Symbol: "BTCUSDT",
 		Side:   ORDER_SIDE_BUY,
 		Type:   ORDER_TYPE_MARKET,
-		Qty:    1.000,
+		Qty:    decimal.NewFromFloat(1.000),
 	}
 
 	// Run test
@@ -127,7 +162,7 @@ func (suite *BybitTestSuite) TestPlacePerpOrder() {
 		Side:        PLACE_PERP_BUY,
 		Symbol:      "BTCUSD",
 		OrderType:   PLACE_PERP_MARKET,
-		Qty:         1,
+		Qty:         decimal.NewFromInt(1),
 		TimeInForce: PLACE_PERP_GTC,
 	}
 	orderId, err := suite.Exchange.PlacePerpOrder(orderParams)
@@ -140,6 +175,30 @@ func (suite *BybitTestSuite) TestPlacePerpOrder() {
 	suite.NotZero(orderId, "Returned order Id is zero")
 }
 
+func (suite *BybitTestSuite) TestPlaceLinearPerpOrder() {
+	fmt.Println(">>> From TestPlaceLinearPerpOrder")
+
+	// Run test
+	orderParams := PlacePerpOrderParams{
+		Side:        PLACE_PERP_BUY,
+		Symbol:      "BTCUSDT",
+		OrderType:   PLACE_PERP_MARKET,
+		Qty:         decimal.NewFromFloat(0.001),
+		TimeInForce: PLACE_PERP_GTC,
+	}
+	orderId, err := suite.Exchange.PlacePerpOrder(orderParams)
+	position, positionErr := suite.Exchange.GetPerpPosition("BTCUSDT")
+
+	fmt.Printf("Order Id: %v\nPosition: %+v\n", orderId, position)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(err, "Couldn't place linear perp order.")
+	suite.NotZero(orderId, "Returned order Id is zero")
+	suite.NoError(positionErr, "Couldn't get linear perp position.")
+	suite.Equal("BTCUSDT", position.Symbol)
+}
+
 func (suite *BybitTestSuite) TestGetPerpOrder() {
 	fmt.Println(">>> From TestGetPerpOrder")
 
@@ -151,7 +210,7 @@ func (suite *BybitTestSuite) TestGetPerpOrder() {
 		Side:        PLACE_PERP_BUY,
 		Symbol:      symbol,
 		OrderType:   PLACE_PERP_MARKET,
-		Qty:         1,
+		Qty:         decimal.NewFromInt(1),
 		TimeInForce: PLACE_PERP_GTC,
 	}
 	_, err := suite.Exchange.PlacePerpOrder(orderParams)
@@ -224,7 +283,7 @@ func (suite *BybitTestSuite) TestGetPerpPosition() {
 		Side:        PLACE_PERP_BUY,
 		Symbol:      symbol,
 		OrderType:   PLACE_PERP_MARKET,
-		Qty:         1,
+		Qty:         decimal.NewFromInt(1),
 		TimeInForce: PLACE_PERP_GTC,
 	}
 	_, err := suite.Exchange.PlacePerpOrder(orderParams)
@@ -266,8 +325,8 @@ func (suite *BybitTestSuite) TestCancelSpotOrder() {
 		Symbol: "BTCUSDT",
 		Side:   ORDER_SIDE_BUY,
 		Type:   ORDER_TYPE_LIMIT,
-		Price:  1, // a low price so it won't get fulfilled and we can then make the cancel request
-		Qty:    1.000,
+		Price:  decimal.NewFromInt(1), // a low price so it won't get fulfilled and we can then make the cancel request
+		Qty:    decimal.NewFromFloat(1.000),
 	}
 	orderId, err := suite.Exchange.PlaceSpotOrder(orderParams)
 
@@ -295,9 +354,9 @@ func (suite *BybitTestSuite) TestCancelPerpOrder() {
 		Side:        PLACE_PERP_BUY,
 		Symbol:      symbol,
 		OrderType:   PLACE_PERP_LIMIT,
-		Qty:         1,
+		Qty:         decimal.NewFromInt(1),
 		TimeInForce: PLACE_PERP_GTC,
-		Price:       price,
+		Price:       decimal.NewFromFloat(price),
 	}
 	orderId, err := suite.Exchange.PlacePerpOrder(orderParams)
 
@@ -330,6 +389,2038 @@ func (suite *BybitTestSuite) TestWithdrawFromExchange() {
 	suite.NotZero(WithdrawlId, "Returned withdrawl id is zero.")
 }
 
+func (suite *BybitTestSuite) TestRestClientGetMarketPrice() {
+	fmt.Println(">>> From TestRestClientGetMarketPrice")
+
+	// Set up test
+	client := NewBybitRestClient(suite.Exchange)
+
+	// Run test
+	marketPrice, err := client.GetMarketPrice("BTC", "USDT", exchange.MARKET_TYPE_SPOT)
+
+	fmt.Printf("Unified Spot Market Price for: BTC/USDT is: %v\n", marketPrice)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(err, "Couldn't get unified spot market price.")
+	suite.NotZero(marketPrice, "Returned unified spot market price is zero")
+}
+
+func (suite *BybitTestSuite) TestRestClientPlaceAndCancelPerpOrder() {
+	fmt.Println(">>> From TestRestClientPlaceAndCancelPerpOrder")
+
+	// Set up test
+	client := NewBybitRestClient(suite.Exchange)
+	orderParams := exchange.PlaceOrderParams{
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USD",
+		OrderSide:     exchange.ORDER_SIDE_BUY,
+		OrderType:     exchange.ORDER_TYPE_LIMIT,
+		Price:         decimal.NewFromInt(5000), // way below market so it doesn't get fulfilled
+		Size:          decimal.NewFromFloat(0.001),
+	}
+
+	// Run test
+	orderId, err := client.PlacePerpOrder(orderParams)
+	suite.NoError(err, "Couldn't place unified perp order.")
+
+	order, err := client.GetOrder(exchange.GetOrderParams{
+		ExchangeOrderId: orderId,
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USD",
+		MarketType:      exchange.MARKET_TYPE_PERP,
+	})
+
+	orderJson, _ := json.MarshalIndent(order, "", "\t")
+	fmt.Println(string(orderJson))
+
+	status, cancelErr := client.CancelOrder(exchange.CancelOrderParams{
+		ExchangeOrderId: orderId,
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USD",
+		MarketType:      exchange.MARKET_TYPE_PERP,
+	})
+
+	fmt.Printf("Status of unified perp order cancellation: %v\n", status)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(err, "Couldn't get unified perp order.")
+	suite.Equal(orderId, order.ExchangeOrderId)
+	suite.Equal(exchange.ORDER_SIDE_BUY, order.Side)
+	suite.NoError(cancelErr, "Couldn't cancel unified perp order.")
+	suite.True(status, "Cancel unified perp order request failed.")
+}
+
+func TestGetSpotOrderCtxCancelled(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestGetSpotOrderCtxCancelled")
+
+	// Set up test
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Run test
+	_, err := NewBybitExchange("secret", "key").GetSpotOrderCtx(ctx, "1")
+
+	fmt.Printf("Error of cancelled request: %v\n", err)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.ErrorIs(err, context.Canceled, "Cancelled context should abort the request.")
+}
+
+func TestAPIErrorClassification(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestAPIErrorClassification")
+
+	// Run test
+	rateLimitErr := checkApiResponse(SPOT_ORDER, http.StatusOK, ApiResponse{RetCode: 10006, RetMsg: "too many visits!"})
+	notFoundErr := checkApiResponse(CANCEL_PERP_ORDER, http.StatusOK, ApiResponse{RetCode: 20001, RetMsg: "order not exists or too late to cancel"})
+	bannedErr := newHTTPError(PLACE_PERP_ORDER, http.StatusForbidden)
+	okErr := checkApiResponse(SPOT_ORDER, http.StatusOK, ApiResponse{RetCode: 0, RetMsg: "OK"})
+
+	fmt.Printf("Errors: %v | %v | %v\n", rateLimitErr, notFoundErr, bannedErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.ErrorIs(rateLimitErr, ErrRateLimited)
+	assert.ErrorIs(notFoundErr, ErrOrderNotFound)
+	assert.ErrorIs(bannedErr, ErrRateLimited)
+	assert.NoError(okErr)
+
+	apiErr, ok := AsAPIError(notFoundErr)
+	assert.True(ok, "Error should be an *APIError")
+	assert.Equal(20001, apiErr.RetCode)
+	assert.Equal(CANCEL_PERP_ORDER, apiErr.Endpoint)
+}
+
+func TestTimeSyncOffset(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestTimeSyncOffset")
+
+	// Set up test, a server clock running 2 seconds ahead
+	serverAhead := 2 * time.Second
+	fetch := func(ctx context.Context) (float64, error) {
+		return float64(time.Now().Add(serverAhead).UnixNano()) / float64(time.Second), nil
+	}
+	timeSync := newTimeSync(fetch, time.Minute, NewBybitExchange("secret", "key").logger)
+
+	// Run test
+	timestamp, err := timeSync.Timestamp(context.Background())
+	stats := timeSync.Stats()
+
+	fmt.Printf("Time sync stats: %+v\n", stats)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(err, "Couldn't sync time.")
+	assert.InDelta(serverAhead.Milliseconds(), stats.Offset.Milliseconds(), 50)
+	assert.InDelta(time.Now().Add(serverAhead).UnixMilli(), timestamp, 200)
+	assert.Equal(1, stats.Syncs)
+}
+
+func (suite *BybitTestSuite) TestServerTimeSync() {
+	fmt.Println(">>> From TestServerTimeSync")
+
+	// Run test
+	err := suite.Exchange.TimeSync().Sync(context.Background())
+	stats := suite.Exchange.TimeSync().Stats()
+
+	fmt.Printf("Server time offset: %v rtt: %v\n", stats.Offset, stats.RTT)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(err, "Couldn't sync with server time.")
+	suite.NotZero(stats.RTT, "Round trip of the time sync is zero")
+}
+
+func TestRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestRateLimiter")
+
+	// Set up test, a group bybit reported exhausted
+	rateLimiter := newRateLimiter(RATE_LIMIT_FAIL_FAST)
+	rateLimiter.Update(RATE_LIMIT_PERP_ORDER, 100, 0, time.Now().Add(200*time.Millisecond))
+
+	// Run test
+	errExhausted := rateLimiter.Wait(context.Background(), RATE_LIMIT_PERP_ORDER, PRIORITY_HIGH)
+	time.Sleep(250 * time.Millisecond)
+	errReset := rateLimiter.Wait(context.Background(), RATE_LIMIT_PERP_ORDER, PRIORITY_NORMAL)
+	status := rateLimiter.Status(RATE_LIMIT_PERP_ORDER)
+
+	fmt.Printf("Rate limit status: %+v\n", status)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.ErrorIs(errExhausted, ErrRateLimited)
+	assert.NoError(errReset, "Rate limit group wasn't released after reset.")
+	assert.Equal(100, status.Limit)
+}
+
+func TestRateLimiterCancelPriority(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestRateLimiterCancelPriority")
+
+	// Set up test, only the reserve is left
+	rateLimiter := newRateLimiter(RATE_LIMIT_FAIL_FAST)
+	rateLimiter.Update(RATE_LIMIT_PERP_ORDER, 100, 5, time.Time{})
+	placeGroup, placePriority := endpointRateLimit(newSignedRequest(http.MethodPost, PLACE_PERP_ORDER, nil))
+	cancelGroup, cancelPriority := endpointRateLimit(newSignedRequest(http.MethodPost, CANCEL_PERP_ORDER, nil))
+
+	// Run test
+	errPlace := rateLimiter.Wait(context.Background(), placeGroup, placePriority)
+	errCancel := rateLimiter.Wait(context.Background(), cancelGroup, cancelPriority)
+
+	fmt.Printf("Place: %v, cancel: %v\n", errPlace, errCancel)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.Equal(PRIORITY_HIGH, cancelPriority)
+	assert.ErrorIs(errPlace, ErrRateLimited)
+	assert.NoError(errCancel, "Cancel couldn't use the reserved tokens.")
+}
+
+func TestRateLimiterMissingStatus(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestRateLimiterMissingStatus")
+
+	// Set up test, limit headers without a readable status
+	rateLimiter := newRateLimiter(RATE_LIMIT_FAIL_FAST)
+	missing := http.Header{}
+	missing.Set("X-Bapi-Limit", "100")
+	garbled := http.Header{}
+	garbled.Set("X-Bapi-Limit", "100")
+	garbled.Set("X-Bapi-Limit-Status", "n/a")
+
+	// Run test
+	rateLimiter.observe(RATE_LIMIT_PERP_ORDER, missing, []byte(`{"retCode":0}`))
+	rateLimiter.observe(RATE_LIMIT_PERP_ORDER, garbled, []byte(`{"retCode":0}`))
+	err := rateLimiter.Wait(context.Background(), RATE_LIMIT_PERP_ORDER, PRIORITY_NORMAL)
+
+	fmt.Printf("Rate limit status: %+v\n", rateLimiter.Status(RATE_LIMIT_PERP_ORDER))
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(err, "A missing limit status shouldn't empty the bucket.")
+}
+
+func (suite *BybitTestSuite) TestPlacePerpOrderByLinkId() {
+	fmt.Println(">>> From TestPlacePerpOrderByLinkId")
+
+	// Set up test
+	symbol := "BTCUSD"
+	orderLinkId := newOrderLinkId()
+
+	// Run test
+	orderParams := PlacePerpOrderParams{
+		Side:        PLACE_PERP_BUY,
+		Symbol:      symbol,
+		OrderType:   PLACE_PERP_MARKET,
+		Qty:         decimal.NewFromInt(1),
+		TimeInForce: PLACE_PERP_GTC,
+		OrderLinkId: orderLinkId,
+	}
+	orderId, err := suite.Exchange.PlacePerpOrder(orderParams)
+	perpOrder, lookupErr := suite.Exchange.GetPerpOrderByLinkId(symbol, orderLinkId)
+	_, unknownErr := suite.Exchange.GetPerpOrderByLinkId(symbol, newOrderLinkId())
+
+	fmt.Printf("Order Id: %v, by link id: %v\n", orderId, perpOrder.OrderId)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(err, "Couldn't place perp order.")
+	suite.NoError(lookupErr, "Couldn't get perp order by link id.")
+	suite.Equal(orderId, perpOrder.OrderId)
+	suite.ErrorIs(unknownErr, ErrOrderNotFound)
+}
+
+func TestRetryClassification(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestRetryClassification")
+
+	// Run test
+	rateLimitErr := checkApiResponse(PLACE_PERP_ORDER, http.StatusOK, ApiResponse{RetCode: 10006, RetMsg: "too many visits!"})
+	serverErr := newHTTPError(PLACE_PERP_ORDER, http.StatusBadGateway)
+	transportErr := fmt.Errorf("PlacePerpOrder failed to make request: %w", &transportError{context.DeadlineExceeded})
+	notSentErr := fmt.Errorf("PlacePerpOrder failed to create request object: %w", &notSentError{transportErr})
+	balanceErr := checkApiResponse(PLACE_PERP_ORDER, http.StatusOK, ApiResponse{RetCode: 30031, RetMsg: "insufficient available balance"})
+
+	policy := DefaultRetryPolicy()
+	backoffs := []time.Duration{policy.Backoff(1), policy.Backoff(2), policy.Backoff(10)}
+
+	fmt.Printf("Backoffs: %v\n", backoffs)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.True(isSafeToResend(rateLimitErr), "Rate limited requests are never processed")
+	assert.True(isAmbiguous(serverErr), "A 5xx may have been processed")
+	assert.True(isAmbiguous(transportErr), "A timed out request may have been processed")
+	assert.False(isAmbiguous(notSentErr), "A request that was never sent can't have been processed")
+	assert.True(isSafeToResend(notSentErr), "A request that was never sent can be resent")
+	assert.False(isSafeToResend(balanceErr) || isAmbiguous(balanceErr), "Rejected orders must not be retried")
+
+	assert.InDelta(float64(policy.BaseDelay), float64(backoffs[0]), float64(policy.BaseDelay)/2)
+	assert.InDelta(float64(2*policy.BaseDelay), float64(backoffs[1]), float64(policy.BaseDelay))
+	assert.LessOrEqual(backoffs[2], policy.MaxDelay)
+}
+
+func TestPlaceOrderResolution(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestPlaceOrderResolution")
+
+	// Set up test, BTCUSDT placements time out but go through, and the first lookup lags behind them
+	var mu sync.Mutex
+	var calls []string
+	places, lookups := 0, 0
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, r.URL.Path)
+
+		switch r.URL.Path {
+		case PLACE_LINEAR_ORDER:
+			if places++; places == 1 || places == 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			fmt.Fprint(w, `{"ret_code":130006,"ret_msg":"order_link_id is repeated"}`)
+		case QUERY_LINEAR_ORDER:
+			if lookups++; lookups == 1 {
+				fmt.Fprint(w, `{"ret_code":0,"result":null}`)
+				return
+			}
+			fmt.Fprint(w, `{"ret_code":0,"result":{"order_id":"o1","symbol":"BTCUSDT","order_link_id":"`+r.URL.Query().Get("order_link_id")+`"}}`)
+		}
+	})
+	defer server.Close()
+	bybit.retryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	orderParams := PlacePerpOrderParams{Side: PLACE_PERP_BUY, Symbol: "BTCUSDT", OrderType: PLACE_PERP_MARKET, Qty: decimal.NewFromFloat(0.01), TimeInForce: PLACE_PERP_GTC}
+
+	// Run test
+	duplicateOrderId, duplicateErr := bybit.PlacePerpOrder(orderParams)
+	duplicateCalls := append([]string(nil), calls...)
+
+	bybit.retryPolicy.MaxAttempts = 1
+	singleOrderId, singleErr := bybit.PlacePerpOrder(orderParams)
+
+	fmt.Printf("Calls: %v\n", calls)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(duplicateErr)
+	assert.Equal("o1", duplicateOrderId)
+	assert.Equal([]string{PLACE_LINEAR_ORDER, QUERY_LINEAR_ORDER, PLACE_LINEAR_ORDER, QUERY_LINEAR_ORDER}, duplicateCalls)
+	assert.ErrorIs(checkApiResponse(V5_PLACE_ORDER, http.StatusOK, ApiResponse{RetCode: 110072, RetMsg: "OrderLinkedID is duplicate"}), ErrDuplicateOrderLinkId)
+
+	// a single attempt is still looked up before giving up
+	assert.NoError(singleErr)
+	assert.Equal("o1", singleOrderId)
+	assert.Equal([]string{PLACE_LINEAR_ORDER, QUERY_LINEAR_ORDER}, calls[len(duplicateCalls):])
+}
+
+func TestHostPoolFailover(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestHostPoolFailover")
+
+	// Set up test, bytick answers faster than the primary host
+	latencies := map[string]time.Duration{MAINNET_URL: 80 * time.Millisecond, MAINNET_URL_2: 20 * time.Millisecond}
+	probe := func(ctx context.Context, host string) (time.Duration, error) {
+		return latencies[host], nil
+	}
+	pool := newHostPool([]string{MAINNET_URL, MAINNET_URL_2}, probe, time.Minute, NewBybitExchange("secret", "key").logger)
+
+	// Run test
+	initialHost := pool.Active()
+	pool.Probe(context.Background())
+	fastestHost := pool.Active()
+
+	for i := 0; i < HOST_FAILURE_THRESHOLD; i++ {
+		pool.report(MAINNET_URL_2, true)
+	}
+	failoverHost := pool.pick()
+	status := pool.Status()
+
+	fmt.Printf("Hosts: %+v\n", status)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.Equal(MAINNET_URL, initialHost)
+	assert.Equal(MAINNET_URL_2, fastestHost)
+	assert.Equal(MAINNET_URL, failoverHost)
+	assert.Equal(CIRCUIT_OPEN, status[1].State)
+}
+
+func (suite *BybitTestSuite) TestProbeHosts() {
+	fmt.Println(">>> From TestProbeHosts")
+
+	// Set up test
+	mainnet := NewBybitExchange("secret", "key", WithEnvironment(ENV_MAINNET))
+
+	// Run test
+	mainnet.HostPool().Probe(context.Background())
+	status := mainnet.HostPool().Status()
+
+	fmt.Printf("Active host: %v, hosts: %+v\n", mainnet.ActiveHost(), status)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.Len(status, 2)
+	for _, host := range status {
+		suite.Equal(CIRCUIT_CLOSED, host.State, "Host %v is unhealthy", host.URL)
+		suite.NotZero(host.Latency, "Host %v wasn't probed", host.URL)
+	}
+}
+
+func TestDecimalParams(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestDecimalParams")
+
+	// Set up test, amounts a float64 or %f would mangle
+	orderParams := PlacePerpOrderParams{
+		Symbol: "BTCUSDT",
+		Qty:    decimal.RequireFromString("0.00000123"),
+		Price:  decimal.RequireFromString("30123.45"),
+	}
+	var balances ResponseForGetSpotWalletBalance
+	balancesJson := `{"ret_code":0,"result":{"balances":[{"coin":"SHIB","total":"123456789.123456789","free":"0.000000019","locked":"0"}]}}`
+
+	// Run test
+	paramString := NewBybitExchange("secret", "key").makeParamString(structs.Map(&orderParams))
+	err := json.Unmarshal([]byte(balancesJson), &balances)
+
+	fmt.Printf("Params: %v\n", paramString)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(err, "Couldn't decode decimal balances.")
+	assert.Contains(paramString, "qty=0.00000123")
+	assert.Contains(paramString, "price=30123.45")
+	assert.Equal("123456789.123456789", balances.Result.Balances[0].Total.String())
+	assert.Equal("0.000000019", balances.Result.Balances[0].Free.String())
+}
+
+func TestInstrumentRegistry(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestInstrumentRegistry")
+
+	// Set up test, a loader that is slow once and then fails
+	var loads int32
+	instruments := []Instrument{
+		{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_SPOT, BaseCoin: "BTC", QuoteCoin: "USDT", Status: INSTRUMENT_STATUS_TRADING},
+		{Symbol: "BTCUSDZ22", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_INVERSE_FUTURES, BaseCoin: "BTC", QuoteCoin: "USD"},
+		{Symbol: "BTCUSD", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_INVERSE_PERPETUAL, BaseCoin: "BTC", QuoteCoin: "USD"},
+	}
+	load := func(ctx context.Context) ([]Instrument, error) {
+		if atomic.AddInt32(&loads, 1) > 1 {
+			return nil, errors.New("exchange down")
+		}
+		time.Sleep(50 * time.Millisecond)
+		return instruments, nil
+	}
+	registry := newInstrumentRegistry(load, 100*time.Millisecond, NewBybitExchange("secret", "key").logger)
+
+	// Run test, concurrent lookups share the first load
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.BySymbol(context.Background(), exchange.MARKET_TYPE_SPOT, "BTCUSDT")
+		}()
+	}
+	wg.Wait()
+	sharedLoads := atomic.LoadInt32(&loads)
+
+	spot, spotErr := registry.BySymbol(context.Background(), exchange.MARKET_TYPE_SPOT, "btcusdt")
+	perp, perpErr := registry.ByPair(context.Background(), "BTC", "USD", exchange.MARKET_TYPE_PERP)
+	_, missingErr := registry.BySymbol(context.Background(), exchange.MARKET_TYPE_PERP, "BTCUSDT")
+
+	time.Sleep(150 * time.Millisecond)
+	_, staleErr := registry.BySymbol(context.Background(), exchange.MARKET_TYPE_SPOT, "BTCUSDT")
+
+	fmt.Printf("Loads: %v, spot: %+v, perp: %+v\n", atomic.LoadInt32(&loads), spot, perp)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.Equal(int32(1), sharedLoads)
+	assert.NoError(spotErr)
+	assert.True(spot.IsTrading())
+	assert.NoError(perpErr)
+	assert.Equal("BTCUSD", perp.Symbol)
+	assert.ErrorIs(missingErr, ErrInstrumentNotFound)
+	assert.NoError(staleErr, "Stale instruments should be served when a reload fails.")
+	assert.Equal(int32(2), atomic.LoadInt32(&loads))
+}
+
+func TestInstrumentRegistryCancelledCaller(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestInstrumentRegistryCancelledCaller")
+
+	// Set up test, a slow loader that gives up when its ctx is cancelled
+	var loads int32
+	load := func(ctx context.Context) ([]Instrument, error) {
+		atomic.AddInt32(&loads, 1)
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return []Instrument{{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_SPOT, BaseCoin: "BTC", QuoteCoin: "USDT", Status: INSTRUMENT_STATUS_TRADING}}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	registry := newInstrumentRegistry(load, time.Hour, NewBybitExchange("secret", "key").logger)
+
+	// Run test, the first caller starts the load and cancels while another caller waits on it
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		firstErr <- registry.Refresh(ctx)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	joinerErr := make(chan error, 1)
+	go func() {
+		joinerErr <- registry.Refresh(context.Background())
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	cancelledErr := <-firstErr
+	sharedErr := <-joinerErr
+	_, lookupErr := registry.BySymbol(context.Background(), exchange.MARKET_TYPE_SPOT, "BTCUSDT")
+
+	fmt.Printf("Loads: %v, cancelled: %v, joiner: %v\n", atomic.LoadInt32(&loads), cancelledErr, sharedErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.ErrorIs(cancelledErr, context.Canceled)
+	assert.NoError(sharedErr, "A caller joining the load should not fail when the caller that started it cancels.")
+	assert.NoError(lookupErr)
+	assert.Equal(int32(1), atomic.LoadInt32(&loads))
+}
+
+func (suite *BybitTestSuite) TestGetInstruments() {
+	fmt.Println(">>> From TestGetInstruments")
+
+	// Run test
+	spot, spotErr := suite.Exchange.Instruments().BySymbol(context.Background(), exchange.MARKET_TYPE_SPOT, "BTCUSDT")
+	linear, linearErr := suite.Exchange.Instruments().BySymbol(context.Background(), exchange.MARKET_TYPE_PERP, "BTCUSDT")
+	maxPrice, tickSize, tickErr := suite.Exchange.GetPerpTickSize("BTCUSD")
+
+	fmt.Printf("Spot: %+v\nLinear: %+v\nBTCUSD max price: %v, tick size: %v\n", spot, linear, maxPrice, tickSize)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(spotErr, "Couldn't get spot instrument.")
+	suite.True(spot.QtyStep.IsPositive())
+	suite.NoError(linearErr, "Couldn't get linear instrument.")
+	suite.Equal(CONTRACT_TYPE_LINEAR_PERPETUAL, linear.ContractType)
+	suite.True(linear.TickSize.IsPositive())
+	suite.NoError(tickErr, "Couldn't get perp tick size.")
+	suite.Greater(maxPrice, tickSize)
+}
+
+func TestOrderValidation(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestOrderValidation")
+
+	// Set up test, a client with cached instruments that never hits the network
+	bybit := NewBybitExchange("secret", "key", WithOrderValidation(OrderValidation{
+		PriceRounding: ROUNDING_PASSIVE,
+		QtyRounding:   ROUNDING_DOWN,
+	}))
+	bybit.instruments = newInstrumentRegistry(func(ctx context.Context) ([]Instrument, error) {
+		return []Instrument{
+			{
+				Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_SPOT, Status: INSTRUMENT_STATUS_TRADING,
+				TickSize: decimal.RequireFromString("0.01"), QtyStep: decimal.RequireFromString("0.000001"),
+				MinQty: decimal.RequireFromString("0.0001"), MaxQty: decimal.NewFromInt(100), MinNotional: decimal.NewFromInt(10),
+			},
+			{
+				Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_PERP, Status: INSTRUMENT_STATUS_TRADING,
+				TickSize: decimal.RequireFromString("0.5"), MinPrice: decimal.RequireFromString("0.5"), MaxPrice: decimal.NewFromInt(999999),
+				QtyStep: decimal.RequireFromString("0.001"), MinQty: decimal.RequireFromString("0.001"), MaxQty: decimal.NewFromInt(100),
+			},
+		}, nil
+	}, time.Hour, bybit.logger)
+
+	// Run test
+	spotSell, spotSellErr := bybit.ValidateSpotOrder(PlaceSpotOrderParams{
+		Symbol: "BTCUSDT", Side: ORDER_SIDE_SELL, Type: SPOT_ORDER_TYPE_LIMIT,
+		Qty: decimal.RequireFromString("0.0012345"), Price: decimal.RequireFromString("30000.123"),
+	})
+	perpBuy, perpBuyErr := bybit.ValidatePerpOrder(PlacePerpOrderParams{
+		Symbol: "BTCUSDT", Side: PLACE_PERP_BUY, OrderType: PLACE_PERP_LIMIT,
+		Qty: decimal.RequireFromString("0.0129"), Price: decimal.RequireFromString("30000.7"), StopLoss: decimal.RequireFromString("29000.3"),
+	})
+	_, smallErr := bybit.ValidateSpotOrder(PlaceSpotOrderParams{
+		Symbol: "BTCUSDT", Side: ORDER_SIDE_BUY, Type: SPOT_ORDER_TYPE_LIMIT,
+		Qty: decimal.RequireFromString("0.0002"), Price: decimal.NewFromInt(30000),
+	})
+	_, placeErr := bybit.PlacePerpOrder(PlacePerpOrderParams{
+		Symbol: "BTCUSDT", Side: PLACE_PERP_BUY, OrderType: PLACE_PERP_MARKET, Qty: decimal.RequireFromString("0.0001"),
+	})
+	strict := NewBybitExchange("secret", "key")
+	strict.instruments = bybit.instruments
+	_, strictErr := strict.ValidatePerpOrder(PlacePerpOrderParams{
+		Symbol: "BTCUSDT", Side: PLACE_PERP_BUY, OrderType: PLACE_PERP_LIMIT,
+		Qty: decimal.RequireFromString("0.01"), Price: decimal.RequireFromString("30000.7"),
+	})
+
+	fmt.Printf("Spot sell: %+v\nPerp buy: %+v\n", spotSell, perpBuy)
+	fmt.Printf("Errors: %v | %v | %v\n", smallErr, placeErr, strictErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(spotSellErr)
+	assert.Equal("0.001234", spotSell.Qty.String())
+	assert.Equal("30000.13", spotSell.Price.String())
+	assert.NoError(perpBuyErr)
+	assert.Equal("0.012", perpBuy.Qty.String())
+	assert.Equal("30000.5", perpBuy.Price.String())
+	assert.Equal("29000.5", perpBuy.StopLoss.String())
+
+	var validationErr *ValidationError
+	assert.ErrorAs(smallErr, &validationErr)
+	assert.Equal("notional", validationErr.Field)
+	assert.ErrorAs(placeErr, &validationErr, "Invalid orders shouldn't be sent.")
+	assert.Equal("qty", validationErr.Field)
+	assert.ErrorIs(strictErr, ErrInvalidOrder)
+}
+
+func TestRestClientGetPerpOrder(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestRestClientGetPerpOrder")
+
+	// Set up test, a closed linear order l1 that no longer shows in the order list
+	var mu sync.Mutex
+	var paths []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		if r.URL.Path == QUERY_LINEAR_ORDER && r.URL.Query().Get("order_id") == "l1" {
+			fmt.Fprint(w, `{"ret_code":0,"result":{"order_id":"l1","symbol":"BTCUSDT","side":"Sell","order_type":"Limit","price":21000,"qty":0.01,"cum_exec_qty":0.01,"order_status":"Filled"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"ret_code":0,"result":null}`)
+	})
+	defer server.Close()
+	client := NewBybitRestClient(bybit)
+
+	// Run test
+	order, err := client.GetOrder(exchange.GetOrderParams{ExchangeOrderId: "l1", BaseCurrency: "BTC", QuoteCurrency: "USDT", MarketType: exchange.MARKET_TYPE_PERP})
+	_, notFoundErr := client.GetOrder(exchange.GetOrderParams{ExchangeOrderId: "l2", BaseCurrency: "BTC", QuoteCurrency: "USDT", MarketType: exchange.MARKET_TYPE_PERP})
+
+	fmt.Printf("Paths: %v\nOrder: %+v\nError: %v\n", paths, order, notFoundErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(err)
+	assert.Equal("l1", order.ExchangeOrderId)
+	assert.ErrorIs(notFoundErr, ErrOrderNotFound)
+	assert.Equal([]string{QUERY_LINEAR_ORDER, QUERY_LINEAR_ORDER}, paths)
+}
+
+func TestLinearPerpRouting(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestLinearPerpRouting")
+
+	// Set up test, a mock server recording the paths bybit is called on
+	var mu sync.Mutex
+	var paths []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case GET_LINEAR_POSITION:
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"symbol":"BTCUSDT","side":"Buy","size":0,"position_idx":0},{"symbol":"BTCUSDT","side":"Sell","size":0.01,"entry_price":20000.5,"position_idx":0}]}`)
+		case GET_LINEAR_ORDER:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"current_page":1,"data":[{"order_id":"l1","symbol":"BTCUSDT","side":"Buy","order_type":"Limit","price":19000.5,"qty":0.01,"leaves_qty":0.004,"cum_exec_qty":0.006,"time_in_force":"GoodTillCancel","order_status":"PartiallyFilled"}]}}`)
+		default:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"order_id":"o1"}}`)
+		}
+	})
+	defer server.Close()
+
+	linearParams := PlacePerpOrderParams{Side: PLACE_PERP_BUY, Symbol: "BTCUSDT", OrderType: PLACE_PERP_MARKET, Qty: decimal.NewFromFloat(0.01), TimeInForce: PLACE_PERP_GTC}
+	inverseParams := PlacePerpOrderParams{Side: PLACE_PERP_BUY, Symbol: "BTCUSD", OrderType: PLACE_PERP_MARKET, Qty: decimal.NewFromInt(1), TimeInForce: PLACE_PERP_GTC}
+
+	// Run test
+	linearOrderId, linearErr := bybit.PlacePerpOrder(linearParams)
+	_, inverseErr := bybit.PlacePerpOrder(inverseParams)
+	position, positionErr := bybit.GetPerpPosition("BTCUSDT")
+	perpOrders, ordersErr := bybit.GetPerpOrder("BTCUSDT")
+	_, cancelErr := bybit.CancelPerpOrder("BTCUSDT", "o1")
+
+	fmt.Printf("Paths: %v\nPosition: %+v\nOrders: %+v\n", paths, position, perpOrders)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(linearErr)
+	assert.Equal("o1", linearOrderId)
+	assert.NoError(inverseErr)
+	assert.NoError(positionErr)
+	assert.NoError(ordersErr)
+	assert.NoError(cancelErr)
+	assert.Equal([]string{PLACE_LINEAR_ORDER, PLACE_PERP_ORDER, GET_LINEAR_POSITION, GET_LINEAR_ORDER, CANCEL_LINEAR_ORDER}, paths)
+	assert.Equal("Sell", position.Side)
+	assert.True(position.EntryPrice.Equal(decimal.RequireFromString("20000.5")))
+	assert.Len(perpOrders, 1)
+	assert.Equal("19000.5", perpOrders[0].Price)
+	assert.Equal("0.004", perpOrders[0].LeavesQty)
+
+	flat, flatErr := openLinearPosition("GetPerpPosition", "BTCUSDT", nil)
+	assert.NoError(flatErr)
+	assert.Equal("None", flat.Side)
+}
+
+func TestUsdcPerpsNotRouted(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestUsdcPerpsNotRouted")
+
+	// Set up test, a mock server listing an inverse, a USDT and a USDC perp and recording the paths bybit is called on
+	var mu sync.Mutex
+	var paths []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case GET_SPOT_SYMBOLS_INFO:
+			fmt.Fprint(w, `{"ret_code":0,"result":[]}`)
+		case GET_PERP_SYMBOLS_INFO:
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"name":"BTCUSD","status":"Trading","base_currency":"BTC","quote_currency":"USD"},{"name":"BTCUSDT","status":"Trading","base_currency":"BTC","quote_currency":"USDT"},{"name":"BTCPERP","status":"Trading","base_currency":"BTC","quote_currency":"USDC"}]}`)
+		default:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"order_id":"o1"}}`)
+		}
+	})
+	defer server.Close()
+	bybit.instruments = newInstrumentRegistry(bybit.loadInstruments, time.Minute, bybit.logger)
+
+	// Run test
+	usdtLinear, usdtErr := bybit.isLinear(context.Background(), "BTCUSDT")
+	inverseLinear, inverseErr := bybit.isLinear(context.Background(), "BTCUSD")
+	_, usdcErr := bybit.isLinear(context.Background(), "BTCPERP")
+	_, orderErr := bybit.PlacePerpOrder(PlacePerpOrderParams{Side: PLACE_PERP_BUY, Symbol: "BTCPERP", OrderType: PLACE_PERP_MARKET, Qty: decimal.NewFromFloat(0.01), TimeInForce: PLACE_PERP_GTC})
+
+	fmt.Printf("Paths: %v\nUSDC: %v\n", paths, orderErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(usdtErr)
+	assert.True(usdtLinear)
+	assert.NoError(inverseErr)
+	assert.False(inverseLinear)
+	assert.ErrorIs(usdcErr, ErrInstrumentNotFound)
+	assert.Error(orderErr, "A USDC perp should not be placed on the USDT linear endpoints.")
+	assert.NotContains(paths, PLACE_LINEAR_ORDER)
+	assert.NotContains(paths, PLACE_PERP_ORDER)
+}
+
+func TestTotalAcctUsdValue(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestTotalAcctUsdValue")
+
+	// Set up test, spot BTC, USDT and an empty DOGE balance, perp USDT and BTC wallets, prices failing once pricesDown is set
+	var mu sync.Mutex
+	var pricesDown bool
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		down := pricesDown
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case GET_SPOT_BALANCE:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"balances":[{"coin":"BTC","total":"0.5"},{"coin":"USDT","total":"100"},{"coin":"DOGE","total":"0"}]}}`)
+		case GET_PERP_BALANCE:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"USDT":{"wallet_balance":50},"BTC":{"wallet_balance":0.1}}}`)
+		case SPOT_MARKET_PRICE, TICKER:
+			if down {
+				fmt.Fprint(w, `{"ret_code":10001,"ret_msg":"params error"}`)
+				return
+			}
+			if r.URL.Path == SPOT_MARKET_PRICE {
+				fmt.Fprint(w, `{"ret_code":0,"result":{"bidPrice":"20000"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"mark_price":"21000"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	// Run test
+	total, totalErr := bybit.GetTotalAcctUsdValueDecimal()
+	mu.Lock()
+	pricesDown = true
+	mu.Unlock()
+	unpriced, unpricedErr := bybit.GetTotalAcctUsdValueDecimal()
+
+	fmt.Printf("Total: %v, unpriced: %v, error: %v\n", total, unpriced, unpricedErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(totalErr)
+	assert.True(total.Equal(decimal.NewFromInt(10000+100+50+2100)), "Got %v", total)
+	assert.Error(unpricedErr, "A coin that can't be priced shouldn't count as zero.")
+	assert.True(unpriced.IsZero())
+}
+
+func TestSpotListPagination(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestSpotListPagination")
+
+	// Set up test, three open orders and three trades served two per page, newest first
+	var mu sync.Mutex
+	var cursors []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		cursors = append(cursors, r.URL.Path+"?"+query.Get("orderId")+query.Get("toTicketId"))
+		mu.Unlock()
+
+		switch r.URL.Path + "?" + query.Get("orderId") + query.Get("toTicketId") {
+		case GET_SPOT_OPEN_ORDERS + "?":
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"orderId":"30","time":"1672531200000"},{"orderId":"20","time":"1672531100000"}]}`)
+		case GET_SPOT_OPEN_ORDERS + "?20":
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"orderId":"10","time":"1672531000000"}]}`)
+		case GET_SPOT_TRADE_HISTORY + "?":
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"id":"3","ticketId":"300","price":"20000","qty":"0.1","isBuyer":true,"time":"1672531200000"},{"id":"2","ticketId":"200","price":"20000","qty":"0.2","isMaker":true,"time":"1672531100000"}]}`)
+		case GET_SPOT_TRADE_HISTORY + "?199":
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"id":"1","ticketId":"100","price":"19000","qty":"0.3","time":"1672531000000"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	// Run test
+	openOrders, openErr := bybit.GetSpotOpenOrders(SpotListParams{Symbol: "BTCUSDT", Limit: 2}).All(context.Background())
+	recentOrders, recentErr := bybit.GetSpotOpenOrders(SpotListParams{Limit: 2, StartTime: time.UnixMilli(1672531100000)}).All(context.Background())
+	trades, tradesErr := bybit.GetSpotTradeHistory(SpotListParams{Limit: 2}).All(context.Background())
+
+	fmt.Printf("Requests: %v\nOpen orders: %v, trades: %v\n", cursors, len(openOrders), len(trades))
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(openErr)
+	assert.Len(openOrders, 3)
+	assert.Equal("10", openOrders[2].OrderId)
+	assert.NoError(recentErr)
+	assert.Len(recentOrders, 2, "Orders before the start time should be filtered out.")
+
+	assert.NoError(tradesErr)
+	assert.Len(trades, 3)
+	fill := trades[0].ToFill()
+	assert.Equal(exchange.ORDER_SIDE_BUY, fill.Side)
+	assert.Equal("300", fill.TradeID)
+	assert.Equal("3", fill.ID)
+	assert.Equal("maker", trades[1].ToFill().Liquidity)
+	assert.Equal(exchange.ORDER_SIDE_SELL, trades[2].ToFill().Side)
+}
+
+func (suite *BybitTestSuite) TestGetSpotOrderHistory() {
+	fmt.Println(">>> From TestGetSpotOrderHistory")
+
+	// Run test
+	params := SpotListParams{Symbol: "BTCUSDT", StartTime: time.Now().Add(-7 * 24 * time.Hour)}
+	orders, ordersErr := suite.Exchange.GetSpotOrderHistory(params).All(context.Background())
+	trades, tradesErr := suite.Exchange.GetSpotTradeHistory(params).All(context.Background())
+
+	fmt.Printf("Orders: %v, trades: %v\n", len(orders), len(trades))
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(ordersErr, "Couldn't list spot orders.")
+	suite.NoError(tradesErr, "Couldn't list spot trades.")
+	for _, order := range orders {
+		suite.Equal("BTCUSDT", order.ToOrder().Market)
+	}
+}
+
+func TestPerpListPagination(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestPerpListPagination")
+
+	// Set up test, linear fills served two per page, an inverse fill and a closed pnl record
+	var mu sync.Mutex
+	var requests []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		requests = append(requests, r.URL.Path+"?page="+query.Get("page"))
+		mu.Unlock()
+
+		switch r.URL.Path + "?page=" + query.Get("page") {
+		case GET_LINEAR_EXECUTIONS + "?page=1":
+			fmt.Fprint(w, `{"ret_code":0,"result":{"current_page":1,"data":[{"exec_id":"e3","order_id":"o3","symbol":"BTCUSDT","side":"Buy","exec_type":"Trade","exec_price":"20000","exec_qty":0.01,"exec_fee":"0.12","fee_rate":0.0006,"last_liquidity_ind":"RemovedLiquidity","trade_time_ms":1672531200000},{"exec_id":"e2","symbol":"BTCUSDT","side":"Sell","exec_type":"Trade","exec_price":"20100","exec_qty":0.01,"exec_fee":"-0.02","fee_rate":-0.0001,"last_liquidity_ind":"AddedLiquidity","trade_time_ms":1672531100000}]}}`)
+		case GET_LINEAR_EXECUTIONS + "?page=2":
+			fmt.Fprint(w, `{"ret_code":0,"result":{"current_page":2,"data":[{"exec_id":"e1","symbol":"BTCUSDT","side":"Buy","exec_price":"19900","exec_qty":0.02,"trade_time_ms":1672531000000}]}}`)
+		case GET_PERP_EXECUTIONS + "?page=1":
+			fmt.Fprintf(w, `{"ret_code":0,"result":{"order_id":"","trade_list":[{"exec_id":"i2","symbol":%q,"side":"Buy","exec_price":"20000","exec_qty":100,"trade_time_ms":1672531200000},{"exec_id":"i1","symbol":%q,"side":"Buy","exec_price":"20000","exec_qty":100,"trade_time_ms":1672531000000}]}}`, query.Get("symbol"), query.Get("symbol"))
+		case GET_LINEAR_CLOSED_PNL + "?page=1":
+			fmt.Fprint(w, `{"ret_code":0,"result":{"current_page":1,"data":[{"id":1,"symbol":"BTCUSDT","closed_pnl":12.5,"created_at":1672531200}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	// Run test
+	linearFills, linearErr := bybit.GetPerpExecutions(PerpListParams{Symbol: "BTCUSDT", Limit: 2}).All(context.Background())
+	inverseFills, inverseErr := bybit.GetPerpExecutions(PerpListParams{Symbol: "BTCUSD", Limit: 5, EndTime: time.UnixMilli(1672531100000)}).All(context.Background())
+	datedFills, datedErr := bybit.GetPerpExecutions(PerpListParams{Symbol: "BTCUSDZ22", Limit: 5}).All(context.Background())
+	closedPnls, closedErr := bybit.GetPerpClosedPnl(PerpListParams{Symbol: "BTCUSDT"}).All(context.Background())
+
+	fmt.Printf("Requests: %v\nLinear fills: %v, inverse fills: %v, closed pnl: %+v\n", requests, len(linearFills), len(inverseFills), closedPnls)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(linearErr)
+	assert.Len(linearFills, 3)
+	taker, maker := linearFills[0].ToFill(), linearFills[1].ToFill()
+	assert.Equal("taker", taker.Liquidity)
+	assert.Equal("USDT", taker.FeeCurrency)
+	assert.Equal(0.0006, taker.FeeRate)
+	assert.Equal("maker", maker.Liquidity)
+	assert.Equal(-0.02, maker.Fee)
+	assert.Equal("e3", taker.ID)
+	assert.Equal("e3", taker.TradeID)
+	assert.Equal("o3", taker.OrderID)
+
+	assert.NoError(inverseErr)
+	assert.Len(inverseFills, 1, "Fills after the end time should be filtered out.")
+	inverse := inverseFills[0].ToFill()
+	assert.Equal("BTC", inverse.FeeCurrency)
+	assert.Equal(0.005, inverse.Size, "Inverse fills should be sized in the base coin.")
+
+	assert.NoError(datedErr)
+	assert.Len(datedFills, 2)
+	assert.Equal(0.005, datedFills[0].ToFill().Size, "Dated inverse fills should be sized in the base coin too.")
+
+	assert.NoError(closedErr)
+	assert.Len(closedPnls, 1)
+	assert.True(closedPnls[0].ClosedPnl.Equal(decimal.RequireFromString("12.5")))
+}
+
+func (suite *BybitTestSuite) TestGetPerpExecutions() {
+	fmt.Println(">>> From TestGetPerpExecutions")
+
+	// Run test
+	params := PerpListParams{Symbol: "BTCUSDT", StartTime: time.Now().Add(-7 * 24 * time.Hour)}
+	executions, executionsErr := suite.Exchange.GetPerpExecutions(params).All(context.Background())
+	closedPnls, closedErr := suite.Exchange.GetPerpClosedPnl(params).All(context.Background())
+
+	fmt.Printf("Executions: %v, closed pnl: %v\n", len(executions), len(closedPnls))
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(executionsErr, "Couldn't list perp executions.")
+	suite.NoError(closedErr, "Couldn't list perp closed pnl.")
+	for _, execution := range executions {
+		suite.Equal("BTCUSDT", execution.ToFill().Market)
+	}
+}
+
+func TestFunding(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestFunding")
+
+	// Set up test, funding rates served two per page and an inverse fill among funding payments
+	var mu sync.Mutex
+	var endTimes []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case TICKER:
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"symbol":"BTCUSDT","mark_price":"20000","funding_rate":"0.0001","predicted_funding_rate":"-0.0002","next_funding_time":"2023-01-01T08:00:00Z"}]}`)
+		case V5_FUNDING_HISTORY:
+			mu.Lock()
+			endTimes = append(endTimes, query.Get("category")+":"+query.Get("endTime"))
+			mu.Unlock()
+			if query.Get("endTime") == "1672531199999" {
+				fmt.Fprint(w, `{"retCode":0,"result":{"category":"linear","list":[{"symbol":"BTCUSDT","fundingRate":"0.0003","fundingRateTimestamp":"1672502400000"}]}}`)
+				return
+			}
+			fmt.Fprint(w, `{"retCode":0,"result":{"category":"linear","list":[{"symbol":"BTCUSDT","fundingRate":"0.0001","fundingRateTimestamp":"1672560000000"},{"symbol":"BTCUSDT","fundingRate":"-0.0002","fundingRateTimestamp":"1672531200000"}]}}`)
+		case GET_PERP_EXECUTIONS:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"trade_list":[{"exec_id":"f1","symbol":"BTCUSD","exec_type":"Funding","exec_fee":"0.00001","trade_time_ms":1672560000000},{"exec_id":"t1","symbol":"BTCUSD","exec_type":"Trade","trade_time_ms":1672531000000}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	long := PerpPosition{Symbol: "BTCUSDT", Side: ORDER_SIDE_BUY, PositionValue: decimal.NewFromInt(10000)}
+	short := PerpPosition{Symbol: "BTCUSDT", Side: ORDER_SIDE_SELL, PositionValue: decimal.NewFromInt(10000)}
+
+	// Run test
+	fundingRate, rateErr := bybit.GetPerpFundingRate("BTCUSDT")
+	history, historyErr := bybit.GetPerpFundingRateHistory(PerpListParams{Symbol: "BTCUSDT", Limit: 2}).All(context.Background())
+	payments, paymentsErr := bybit.GetPerpFundingPayments(PerpListParams{Symbol: "BTCUSD"}).All(context.Background())
+	longRate, longCarry := long.AnnualisedFundingCarry(decimal.RequireFromString("0.0001"))
+	shortRate, shortCarry := short.AnnualisedFundingCarry(decimal.RequireFromString("0.0001"))
+	flatRate, _ := PerpPosition{Side: "None"}.AnnualisedFundingCarry(decimal.RequireFromString("0.0001"))
+
+	fmt.Printf("Funding rate: %+v\nHistory requests: %v\nHistory: %+v\n", fundingRate, endTimes, history)
+	fmt.Printf("Long carry: %v (%v), short carry: %v (%v)\n", longRate, longCarry, shortRate, shortCarry)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(rateErr)
+	assert.True(fundingRate.FundingRate.Equal(decimal.RequireFromString("0.0001")))
+	assert.True(fundingRate.PredictedFundingRate.IsNegative())
+	assert.Equal(time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC), fundingRate.NextFundingTime)
+
+	assert.NoError(historyErr)
+	assert.Len(history, 3)
+	assert.Equal([]string{"linear:", "linear:1672531199999"}, endTimes)
+
+	assert.NoError(paymentsErr)
+	assert.Len(payments, 1)
+	assert.Equal("f1", payments[0].ExecId)
+
+	assert.Equal("-0.1095", longRate.String())
+	assert.Equal("-1095", longCarry.String())
+	assert.Equal("0.1095", shortRate.String())
+	assert.Equal("1095", shortCarry.String())
+	assert.True(flatRate.IsZero())
+}
+
+func (suite *BybitTestSuite) TestGetPerpFundingRate() {
+	fmt.Println(">>> From TestGetPerpFundingRate")
+
+	// Run test
+	fundingRate, rateErr := suite.Exchange.GetPerpFundingRate("BTCUSDT")
+	params := PerpListParams{Symbol: "BTCUSDT", StartTime: time.Now().Add(-3 * 24 * time.Hour)}
+	history, historyErr := suite.Exchange.GetPerpFundingRateHistory(params).All(context.Background())
+	payments, paymentsErr := suite.Exchange.GetPerpFundingPayments(params).All(context.Background())
+
+	fmt.Printf("Funding rate: %+v\nHistory: %v rates, payments: %v\n", fundingRate, len(history), len(payments))
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(rateErr, "Couldn't get funding rate.")
+	suite.True(fundingRate.NextFundingTime.After(time.Now()))
+	suite.NoError(historyErr, "Couldn't get funding rate history.")
+	suite.NotEmpty(history)
+	suite.NoError(paymentsErr, "Couldn't get funding payments.")
+}
+
+func TestKlineRange(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestKlineRange")
+
+	// Set up test, minute klines served newest first with one extra candle past each page's end
+	var requests int32
+	var mu sync.Mutex
+	var categories []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		query := r.URL.Query()
+		mu.Lock()
+		categories = append(categories, r.URL.Path+" "+query.Get("category"))
+		mu.Unlock()
+
+		start, _ := strconv.ParseInt(query.Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(query.Get("end"), 10, 64)
+		var rows [][]string
+		for t := end - end%60000 + 60000; t >= start && len(rows) < 1000; t -= 60000 {
+			price := strconv.FormatInt(t/60000%100, 10)
+			rows = append(rows, []string{strconv.FormatInt(t, 10), price, price, price, price, "1", "2"})
+		}
+		body, _ := json.Marshal(map[string]interface{}{"retCode": 0, "result": map[string]interface{}{"list": rows}})
+		w.Write(body)
+	})
+	defer server.Close()
+
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := KlineParams{
+		Symbol:     "BTCUSDT",
+		MarketType: exchange.MARKET_TYPE_SPOT,
+		Interval:   KLINE_INTERVAL_1MIN,
+		StartTime:  startTime,
+		EndTime:    startTime.Add(2500 * time.Minute),
+	}
+
+	// Run test
+	klines, err := bybit.GetKlineRange(params)
+	rangeRequests := atomic.LoadInt32(&requests)
+
+	markParams := params
+	markParams.Symbol, markParams.MarketType, markParams.Type = "BTCUSD", exchange.MARKET_TYPE_PERP, KLINE_TYPE_MARK_PRICE
+	markKlines, markErr := bybit.GetKlines(markParams)
+
+	spotMarkParams := params
+	spotMarkParams.Type = KLINE_TYPE_MARK_PRICE
+	_, spotMarkErr := bybit.GetKlines(spotMarkParams)
+	_, badIntervalErr := bybit.GetKlineRange(KlineParams{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_SPOT, Interval: "2h", StartTime: startTime})
+
+	fmt.Printf("Requests: %v, klines: %v, categories: %v\n", rangeRequests, len(klines), categories)
+	fmt.Printf("Errors: %v, %v\n", spotMarkErr, badIntervalErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(err)
+	assert.Equal(int32(3), rangeRequests)
+	assert.Len(klines, 2500)
+	for i, kline := range klines {
+		if !assert.Equal(startTime.Add(time.Duration(i)*time.Minute), kline.StartTime.UTC()) {
+			break
+		}
+	}
+	assert.True(klines[0].Turnover.Equal(decimal.NewFromInt(2)))
+
+	assert.NoError(markErr)
+	assert.Len(markKlines, 1000)
+	assert.Contains(categories, V5_MARK_PRICE_KLINE+" "+CATEGORY_INVERSE)
+	assert.Contains(categories, V5_KLINE+" "+CATEGORY_SPOT)
+
+	assert.Error(spotMarkErr)
+	assert.Error(badIntervalErr)
+	assert.Equal(time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), KLINE_INTERVAL_1MONTH.add(startTime, 2))
+}
+
+func (suite *BybitTestSuite) TestGetKlineRange() {
+	fmt.Println(">>> From TestGetKlineRange")
+
+	// Set up test
+	endTime := time.Now().Truncate(time.Hour)
+	params := KlineParams{
+		Symbol:     "BTCUSDT",
+		MarketType: exchange.MARKET_TYPE_PERP,
+		Interval:   KLINE_INTERVAL_5MIN,
+		StartTime:  endTime.Add(-5 * 24 * time.Hour),
+		EndTime:    endTime,
+	}
+
+	// Run test
+	klines, err := suite.Exchange.GetKlineRange(params)
+
+	fmt.Printf("Klines: %v\n", len(klines))
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.Require().NoError(err, "Couldn't get klines.")
+	suite.Require().NotEmpty(klines)
+	suite.Len(klines, 5*24*12)
+	suite.Equal(params.StartTime, klines[0].StartTime)
+}
+
+func TestOrderbook(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestOrderbook")
+
+	// Set up test, levels served out of order
+	var mu sync.Mutex
+	var queries []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		mu.Unlock()
+		switch r.URL.Path {
+		case V5_ORDERBOOK:
+			fmt.Fprint(w, `{"retCode":0,"result":{"s":"BTCUSDT","b":[["19999.5","2"],["20000","1"]],"a":[["20001","3"],["20000.5","4"]],"ts":1672531200000,"u":42,"seq":7}}`)
+		case SPOT_MERGED_DEPTH:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"time":1672531200000,"bids":[["19990","5"]],"asks":[["20010","6"]]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	// Run test
+	perpBook, perpErr := bybit.GetOrderbook(OrderbookParams{Symbol: "BTCUSD", MarketType: exchange.MARKET_TYPE_PERP, Depth: 1000})
+	spotBook, spotErr := bybit.GetOrderbook(OrderbookParams{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_SPOT})
+	mergedBook, mergedErr := bybit.GetOrderbook(OrderbookParams{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_SPOT, Merged: true, Scale: 1})
+	_, perpMergedErr := bybit.GetOrderbook(OrderbookParams{Symbol: "BTCUSD", MarketType: exchange.MARKET_TYPE_PERP, Merged: true})
+
+	fmt.Printf("Perp book: %+v\nMerged book: %+v\nQueries: %v\n", perpBook, mergedBook, queries)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(perpErr)
+	assert.Equal([][]float64{{20000, 1}, {19999.5, 2}}, perpBook.Bids)
+	assert.Equal([][]float64{{20000.5, 4}, {20001, 3}}, perpBook.Asks)
+	assert.Equal(int64(42), perpBook.UpdateId)
+	assert.Equal(time.UnixMilli(1672531200000), perpBook.Time)
+
+	assert.NoError(spotErr)
+	assert.Equal(perpBook.Bids, spotBook.Bids)
+
+	assert.NoError(mergedErr)
+	assert.Equal([][]float64{{19990, 5}}, mergedBook.Bids)
+	assert.Zero(mergedBook.UpdateId)
+	assert.Error(perpMergedErr)
+
+	assert.Equal([]string{
+		V5_ORDERBOOK + "?category=inverse&limit=500&symbol=BTCUSD",
+		V5_ORDERBOOK + "?category=spot&limit=50&symbol=BTCUSDT",
+		SPOT_MERGED_DEPTH + "?limit=50&scale=1&symbol=BTCUSDT",
+	}, queries)
+}
+
+func (suite *BybitTestSuite) TestGetOrderbook() {
+	fmt.Println(">>> From TestGetOrderbook")
+
+	// Run test
+	orderbook, err := suite.Exchange.GetOrderbook(OrderbookParams{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_PERP, Depth: 25})
+
+	fmt.Printf("Orderbook: %+v\n", orderbook)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.Require().NoError(err, "Couldn't get order book.")
+	suite.Require().Len(orderbook.Bids, 25)
+	suite.Require().NotEmpty(orderbook.Asks)
+	suite.Less(orderbook.Bids[0][0], orderbook.Asks[0][0])
+	suite.NotZero(orderbook.UpdateId)
+}
+
+func TestMarketStats(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestMarketStats")
+
+	// Set up test, open interest and ratios served a page at a time through the cursor
+	var mu sync.Mutex
+	var queries []string
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		mu.Unlock()
+		switch r.URL.Path {
+		case V5_RECENT_TRADES:
+			fmt.Fprint(w, `{"retCode":0,"result":{"category":"inverse","list":[`+
+				`{"execId":"a1b2-c3","symbol":"BTCUSD","price":"20000","size":"10000","side":"Buy","time":"1672531260000"},`+
+				`{"execId":"d4e5-f6","symbol":"BTCUSD","price":"20000","size":"4000","side":"Sell","time":"1672531200000"}]}}`)
+		case V5_OPEN_INTEREST:
+			if query.Get("cursor") == "" {
+				fmt.Fprint(w, `{"retCode":0,"result":{"category":"linear","symbol":"BTCUSDT","list":[{"openInterest":"50000","timestamp":"1672534800000"}],"nextPageCursor":"page2"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"retCode":0,"result":{"category":"linear","symbol":"BTCUSDT","list":[{"openInterest":"49000","timestamp":"1672531200000"}],"nextPageCursor":""}}`)
+		case V5_ACCOUNT_RATIO:
+			fmt.Fprint(w, `{"retCode":0,"result":{"list":[{"symbol":"BTCUSDT","buyRatio":"0.6","sellRatio":"0.4","timestamp":"1672531200000"}],"nextPageCursor":""}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	statsParams := MarketStatsParams{Symbol: "BTCUSDT", Period: PERIOD_1HOUR, StartTime: time.UnixMilli(1672531200000)}
+
+	// Run test
+	trades, tradesErr := bybit.GetRecentTrades(RecentTradesParams{Symbol: "BTCUSD", MarketType: exchange.MARKET_TYPE_PERP, StartTime: time.UnixMilli(1672531230000)})
+	openInterests, openInterestErr := bybit.GetOpenInterest(statsParams).All(context.Background())
+	ratios, ratiosErr := bybit.GetLongShortRatio(statsParams).All(context.Background())
+
+	fmt.Printf("Trades: %+v\nOpen interest: %+v\nRatios: %+v\nQueries: %v\n", trades, openInterests, ratios, queries)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(tradesErr)
+	assert.Equal([]exchange.Trade{{ID: "a1b2-c3", Price: 20000, Side: exchange.ORDER_SIDE_BUY, Size: 0.5, Time: time.UnixMilli(1672531260000)}}, trades)
+
+	assert.NoError(openInterestErr)
+	assert.Len(openInterests, 2)
+	assert.True(openInterests[1].OpenInterest.Equal(decimal.NewFromInt(49000)))
+
+	assert.NoError(ratiosErr)
+	assert.Len(ratios, 1)
+	assert.True(ratios[0].BuyRatio.Equal(decimal.RequireFromString("0.6")))
+
+	assert.Equal([]string{
+		V5_RECENT_TRADES + "?category=inverse&limit=1000&symbol=BTCUSD",
+		V5_OPEN_INTEREST + "?category=linear&intervalTime=1h&limit=200&startTime=1672531200000&symbol=BTCUSDT",
+		V5_OPEN_INTEREST + "?category=linear&cursor=page2&intervalTime=1h&limit=200&startTime=1672531200000&symbol=BTCUSDT",
+		V5_ACCOUNT_RATIO + "?category=linear&limit=500&period=1h&startTime=1672531200000&symbol=BTCUSDT",
+	}, queries)
+}
+
+func (suite *BybitTestSuite) TestGetOpenInterest() {
+	fmt.Println(">>> From TestGetOpenInterest")
+
+	// Set up test
+	params := MarketStatsParams{Symbol: "BTCUSDT", Period: PERIOD_1HOUR, StartTime: time.Now().Add(-24 * time.Hour), EndTime: time.Now()}
+
+	// Run test
+	trades, tradesErr := suite.Exchange.GetRecentTrades(RecentTradesParams{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_SPOT})
+	openInterests, openInterestErr := suite.Exchange.GetOpenInterest(params).All(context.Background())
+	ratios, ratiosErr := suite.Exchange.GetLongShortRatio(params).All(context.Background())
+
+	fmt.Printf("Trades: %v, open interest: %v, ratios: %v\n", len(trades), len(openInterests), len(ratios))
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(tradesErr, "Couldn't get recent trades.")
+	suite.NotEmpty(trades)
+	suite.NoError(openInterestErr, "Couldn't get open interest.")
+	suite.NotEmpty(openInterests)
+	suite.NoError(ratiosErr, "Couldn't get long/short ratio.")
+	suite.NotEmpty(ratios)
+}
+
+func TestConditionalOrders(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestConditionalOrders")
+
+	// Set up test, a mock server recording the query of every call
+	var mu sync.Mutex
+	var queries = map[string][]url.Values{}
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		queries[r.URL.Path] = append(queries[r.URL.Path], query)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case GET_LINEAR_STOP_ORDER:
+			fmt.Fprintf(w, `{"ret_code":0,"result":{"current_page":%v,"last_page":2,"data":[{"stop_order_id":"s%v","symbol":"BTCUSDT","order_type":"Limit","price":19000,"qty":0.01,"trigger_price":19500.5,"base_price":20000,"trigger_by":"MarkPrice","order_status":"Untriggered"}]}}`, query.Get("page"), query.Get("page"))
+		case QUERY_PERP_STOP_ORDER:
+			if query.Get("order_link_id") != "" {
+				fmt.Fprint(w, `{"ret_code":0,"result":null}`)
+				return
+			}
+			fmt.Fprint(w, `{"ret_code":0,"result":{"stop_order_id":"s3","symbol":"BTCUSD","price":"21000","qty":100,"stop_px":"20500","base_price":"20000","order_status":"Untriggered","stop_order_type":"Stop"}}`)
+		default:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"stop_order_id":"s1"}}`)
+		}
+	})
+	defer server.Close()
+
+	placeParams := PlaceConditionalOrderParams{
+		Side:        PLACE_PERP_BUY,
+		Symbol:      "BTCUSD",
+		OrderType:   PLACE_PERP_LIMIT,
+		Qty:         decimal.NewFromInt(100),
+		Price:       decimal.NewFromInt(21000),
+		BasePrice:   decimal.NewFromInt(20000),
+		StopPx:      decimal.NewFromInt(20500),
+		TimeInForce: PLACE_PERP_GTC,
+		TriggerBy:   TRIGGER_BY_MARK_PRICE,
+	}
+
+	// Run test
+	stopOrderId, placeErr := bybit.PlaceConditionalOrder(placeParams)
+	linearOrders, listErr := bybit.GetConditionalOrders(ConditionalListParams{Symbol: "BTCUSDT", Status: STOP_ORDER_STATUS_UNTRIGGERED}).All(context.Background())
+	inverseOrder, queryErr := bybit.GetConditionalOrder("BTCUSD", "s3")
+	_, notFoundErr := bybit.GetConditionalOrderByLinkId("BTCUSD", "missing")
+	_, amendErr := bybit.AmendConditionalOrder(AmendConditionalOrderParams{Symbol: "BTCUSDT", StopOrderId: "s1", StopPx: decimal.NewFromInt(19400)})
+	_, cancelErr := bybit.CancelConditionalOrder("BTCUSD", "s1")
+
+	fmt.Printf("Linear orders: %+v\nInverse order: %+v\n", linearOrders, inverseOrder)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(placeErr)
+	assert.Equal("s1", stopOrderId)
+	placed := queries[PLACE_PERP_STOP_ORDER][0]
+	assert.Equal("20500", placed.Get("stop_px"))
+	assert.Equal("20000", placed.Get("base_price"))
+	assert.Equal(TRIGGER_BY_MARK_PRICE, placed.Get("trigger_by"))
+	assert.False(placed.Has("reduce_only"))
+	assert.NotEmpty(placed.Get("order_link_id"))
+
+	assert.NoError(listErr)
+	assert.Len(linearOrders, 2)
+	assert.Equal("s2", linearOrders[1].StopOrderId)
+	assert.True(linearOrders[0].StopPx.Equal(decimal.RequireFromString("19500.5")))
+	assert.Equal(STOP_ORDER_STATUS_UNTRIGGERED, queries[GET_LINEAR_STOP_ORDER][0].Get("stop_order_status"))
+
+	assert.NoError(queryErr)
+	assert.True(inverseOrder.StopPx.Equal(decimal.NewFromInt(20500)))
+	assert.ErrorIs(notFoundErr, ErrOrderNotFound)
+
+	assert.NoError(amendErr)
+	amended := queries[REPLACE_LINEAR_STOP_ORDER][0]
+	assert.Equal("19400", amended.Get("p_r_trigger_price"))
+	assert.False(amended.Has("p_r_qty"))
+	assert.False(amended.Has("p_r_price"))
+
+	assert.NoError(cancelErr)
+	assert.Len(queries[CANCEL_PERP_STOP_ORDER], 1)
+}
+
+func (suite *BybitTestSuite) TestPlaceConditionalOrder() {
+	fmt.Println(">>> From TestPlaceConditionalOrder")
+
+	// Set up test, a buy stop far above the market so it never triggers
+	price, err := suite.Exchange.GetPerpMarketPriceDecimal("BTCUSDT")
+	suite.NoError(err)
+	params := PlaceConditionalOrderParams{
+		Side:        PLACE_PERP_BUY,
+		Symbol:      "BTCUSDT",
+		OrderType:   PLACE_PERP_MARKET,
+		Qty:         decimal.RequireFromString("0.001"),
+		BasePrice:   price.Round(0),
+		StopPx:      price.Mul(decimal.NewFromInt(2)).Round(0),
+		TimeInForce: PLACE_PERP_GTC,
+		TriggerBy:   TRIGGER_BY_LAST_PRICE,
+	}
+
+	// Run test
+	stopOrderId, placeErr := suite.Exchange.PlaceConditionalOrder(params)
+	order, queryErr := suite.Exchange.GetConditionalOrder(params.Symbol, stopOrderId)
+	_, cancelErr := suite.Exchange.CancelConditionalOrder(params.Symbol, stopOrderId)
+
+	fmt.Printf("Conditional order: %+v\n", order)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(placeErr, "Couldn't place conditional order.")
+	suite.NoError(queryErr, "Couldn't get conditional order.")
+	suite.Equal(STOP_ORDER_STATUS_UNTRIGGERED, order.OrderStatus)
+	suite.NoError(cancelErr, "Couldn't cancel conditional order.")
+}
+
+func TestAmendAndCancelReplace(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestAmendAndCancelReplace")
+
+	// Set up test, orders c1 and c2 cancel fine, c2's replacement is rejected, c3 was already filled and c4's replacement is invalid
+	var mu sync.Mutex
+	var calls []string
+	var amended url.Values
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == REPLACE_LINEAR_ORDER:
+			amended = query
+			fmt.Fprint(w, `{"ret_code":0,"result":{"order_id":"p1"}}`)
+		case r.Method == http.MethodDelete && query.Get("orderId") == "c3":
+			fmt.Fprint(w, `{"ret_code":-2013,"ret_msg":"Order does not exist."}`)
+		case r.Method == http.MethodDelete:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"orderId":"`+query.Get("orderId")+`","executedQty":"0.25"}}`)
+		case query.Get("qty") == "2":
+			fmt.Fprint(w, `{"ret_code":-1131,"ret_msg":"Balance insufficient"}`)
+		default:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"orderId":"n1"}}`)
+		}
+	})
+	defer server.Close()
+
+	replacement := PlaceSpotOrderParams{Symbol: "BTCUSDT", Qty: decimal.NewFromInt(1), Side: ORDER_SIDE_BUY, Type: SPOT_ORDER_TYPE_LIMIT, TimeInForce: SPOT_GTC, Price: decimal.NewFromInt(19000)}
+	rejected := replacement
+	rejected.Qty = decimal.NewFromInt(2)
+
+	// Run test
+	amendedId, amendErr := bybit.AmendPerpOrder(AmendPerpOrderParams{Symbol: "BTCUSDT", OrderId: "p1", Price: decimal.RequireFromString("19000.5")})
+	_, noIdErr := bybit.AmendPerpOrder(AmendPerpOrderParams{Symbol: "BTCUSDT", Price: decimal.NewFromInt(19000)})
+	result, replaceErr := bybit.CancelReplaceSpotOrder("c1", replacement)
+	placeLegResult, placeLegErr := bybit.CancelReplaceSpotOrder("c2", rejected)
+	_, cancelLegErr := bybit.CancelReplaceSpotOrder("c3", replacement)
+
+	bybit.orderValidation = &OrderValidation{}
+	bybit.instruments = newInstrumentRegistry(func(ctx context.Context) ([]Instrument, error) {
+		return []Instrument{{
+			Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_SPOT, ContractType: CONTRACT_TYPE_SPOT, Status: INSTRUMENT_STATUS_TRADING,
+			TickSize: decimal.RequireFromString("0.01"), QtyStep: decimal.RequireFromString("0.001"), MinQty: decimal.NewFromInt(5), MaxQty: decimal.NewFromInt(100),
+		}}, nil
+	}, time.Minute, bybit.logger)
+	validateLegResult, validateLegErr := bybit.CancelReplaceSpotOrder("c4", replacement)
+
+	fmt.Printf("Calls: %v\nResult: %+v\nErrors: %v, %v, %v\n", calls, result, placeLegErr, cancelLegErr, validateLegErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(amendErr)
+	assert.Equal("p1", amendedId)
+	assert.Equal("19000.5", amended.Get("p_r_price"))
+	assert.False(amended.Has("p_r_qty"))
+	assert.False(amended.Has("order_link_id"))
+	assert.Error(noIdErr)
+
+	assert.NoError(replaceErr)
+	assert.Equal(CancelReplaceResult{CancelledOrderId: "c1", ExecutedQty: decimal.RequireFromString("0.25"), OrderId: "n1"}, result)
+
+	var replaceError *CancelReplaceError
+	assert.True(errors.As(placeLegErr, &replaceError))
+	assert.Equal(LEG_PLACE, replaceError.Leg)
+	assert.ErrorIs(placeLegErr, ErrInsufficientBalance)
+	assert.Equal("c2", placeLegResult.CancelledOrderId)
+	assert.Empty(placeLegResult.OrderId)
+
+	assert.True(errors.As(cancelLegErr, &replaceError))
+	assert.Equal(LEG_CANCEL, replaceError.Leg)
+	assert.ErrorIs(cancelLegErr, ErrOrderNotFound)
+
+	assert.True(errors.As(validateLegErr, &replaceError))
+	assert.Equal(LEG_VALIDATE, replaceError.Leg)
+	assert.ErrorIs(validateLegErr, ErrInvalidOrder)
+	assert.Empty(validateLegResult.CancelledOrderId)
+
+	// the replacement of c3 was never sent and c4 was never cancelled
+	assert.Equal([]string{
+		http.MethodPost + " " + REPLACE_LINEAR_ORDER,
+		http.MethodDelete + " " + SPOT_ORDER, http.MethodPost + " " + SPOT_ORDER,
+		http.MethodDelete + " " + SPOT_ORDER, http.MethodPost + " " + SPOT_ORDER,
+		http.MethodDelete + " " + SPOT_ORDER,
+	}, calls)
+}
+
+func TestBatchOrders(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestBatchOrders")
+
+	// Set up test, spot orders with qty 2 are rejected and order s2 can't be cancelled
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	var calls []string
+	var cancelledIds []string
+	var batchCancel url.Values
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case SPOT_ORDER:
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			if query.Get("qty") == "2" {
+				fmt.Fprint(w, `{"ret_code":-1131,"ret_msg":"Balance insufficient"}`)
+				return
+			}
+			fmt.Fprint(w, `{"ret_code":0,"result":{"orderId":"`+query.Get("orderLinkId")+`"}}`)
+		case CANCEL_ALL_LINEAR_ORDERS:
+			fmt.Fprint(w, `{"ret_code":0,"result":["l1","l2"]}`)
+		case CANCEL_ALL_PERP_ORDERS:
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"clOrdID":"i1","symbol":"BTCUSD","order_status":"Cancelled"}]}`)
+		case BATCH_CANCEL_SPOT_ORDERS:
+			batchCancel = query
+			fmt.Fprint(w, `{"ret_code":0,"result":{"success":true}}`)
+		case BATCH_CANCEL_SPOT_BY_IDS:
+			mu.Lock()
+			cancelledIds = append(cancelledIds, query.Get("orderIds"))
+			mu.Unlock()
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"orderId":"s2","code":"-2013"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	var spotParams []PlaceSpotOrderParams
+	for i := 0; i < 3*BATCH_ORDER_CONCURRENCY; i++ {
+		qty := decimal.NewFromInt(1)
+		if i == 1 {
+			qty = decimal.NewFromInt(2)
+		}
+		spotParams = append(spotParams, PlaceSpotOrderParams{Symbol: "BTCUSDT", Qty: qty, Side: ORDER_SIDE_BUY, Type: SPOT_ORDER_TYPE_MARKET})
+	}
+	var idsToCancel []string
+	for i := 1; i <= SPOT_BATCH_CANCEL_MAX_IDS+1; i++ {
+		idsToCancel = append(idsToCancel, "s"+strconv.Itoa(i))
+	}
+
+	// Run test
+	placed := bybit.PlaceSpotOrders(spotParams)
+	linearIds, linearErr := bybit.CancelAllPerpOrders("BTCUSDT")
+	inverseIds, inverseErr := bybit.CancelAllPerpOrders("BTCUSD")
+	status, spotErr := bybit.CancelSpotOrders(BatchCancelSpotParams{Symbol: "BTCUSDT", Side: ORDER_SIDE_SELL, OrderTypes: []string{SPOT_ORDER_TYPE_LIMIT, SPOT_ORDER_TYPE_LIMIT_MAKER}})
+	cancelled := bybit.CancelSpotOrdersByIds(idsToCancel)
+
+	fmt.Printf("Max in flight: %v\nPlaced: %+v\n", maxInFlight, placed[:3])
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.Len(placed, len(spotParams))
+	for i, result := range placed {
+		assert.NotEmpty(result.OrderLinkId)
+		if i == 1 {
+			assert.ErrorIs(result.Err, ErrInsufficientBalance)
+			continue
+		}
+		assert.NoError(result.Err)
+		assert.Equal(result.OrderLinkId, result.OrderId)
+	}
+	assert.Equal(int32(BATCH_ORDER_CONCURRENCY), maxInFlight)
+
+	assert.NoError(linearErr)
+	assert.Equal([]string{"l1", "l2"}, linearIds)
+	assert.NoError(inverseErr)
+	assert.Equal([]string{"i1"}, inverseIds)
+
+	assert.NoError(spotErr)
+	assert.True(status)
+	assert.Equal("BTCUSDT", batchCancel.Get("symbolId"))
+	assert.Equal(ORDER_SIDE_SELL, batchCancel.Get("side"))
+	assert.Equal("LIMIT,LIMIT_MAKER", batchCancel.Get("orderTypes"))
+	assert.Contains(calls, http.MethodDelete+" "+BATCH_CANCEL_SPOT_ORDERS)
+
+	assert.Len(cancelledIds, 2)
+	assert.Equal("s101", cancelledIds[1])
+	assert.Len(cancelled, len(idsToCancel))
+	assert.NoError(cancelled[0].Err)
+	assert.ErrorIs(cancelled[1].Err, ErrOrderNotFound)
+}
+
+func TestPositionSettings(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestPositionSettings")
+
+	// Set up test, BTCUSD's leverage and BTCUSDT's margin mode already hold, BTCUSDT has a short open and later both legs
+	var mu sync.Mutex
+	var calls []string
+	var hedged bool
+	requests := map[string]url.Values{}
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		requests[r.URL.Path] = query
+		bothLegs := hedged
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case SET_PERP_LEVERAGE:
+			fmt.Fprint(w, `{"ret_code":34036,"ret_msg":"leverage not modified"}`)
+		case SWITCH_LINEAR_ISOLATED:
+			fmt.Fprint(w, `{"ret_code":130056,"ret_msg":"Isolated not modified"}`)
+		case GET_LINEAR_POSITION:
+			if bothLegs {
+				fmt.Fprint(w, `{"ret_code":0,"result":[{"symbol":"BTCUSDT","side":"Buy","size":"0.2"},{"symbol":"BTCUSDT","side":"Sell","size":"0.5"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"symbol":"BTCUSDT","side":"Buy","size":"0"},{"symbol":"BTCUSDT","side":"Sell","size":"0.5"}]}`)
+		case CHANGE_PERP_MARGIN:
+			fmt.Fprint(w, `{"ret_code":30049,"ret_msg":"insufficient available balance"}`)
+		default:
+			fmt.Fprint(w, `{"ret_code":0,"result":null}`)
+		}
+	})
+	defer server.Close()
+
+	// Run test
+	linearLeverageErr := bybit.SetPerpLeverage(SetPerpLeverageParams{Symbol: "BTCUSDT", BuyLeverage: decimal.NewFromInt(5), SellLeverage: decimal.NewFromInt(3)})
+	inverseLeverageErr := bybit.SetPerpLeverage(SetPerpLeverageParams{Symbol: "BTCUSD", BuyLeverage: decimal.NewFromInt(2)})
+	splitLeverageErr := bybit.SetPerpLeverage(SetPerpLeverageParams{Symbol: "BTCUSD", BuyLeverage: decimal.NewFromInt(2), SellLeverage: decimal.NewFromInt(3)})
+	marginModeErr := bybit.SetPerpMarginMode(SetPerpMarginModeParams{Symbol: "BTCUSDT", IsIsolated: true, BuyLeverage: decimal.NewFromInt(10)})
+	positionModeErr := bybit.SetPerpPositionMode("BTCUSD", POSITION_MODE_HEDGE)
+	invalidModeErr := bybit.SetPerpPositionMode("BTCUSD", "Hedge")
+	tpSlModeErr := bybit.SetPerpTpSlMode("BTCUSDT", TP_SL_MODE_PARTIAL)
+	linearMarginErr := bybit.ChangePerpMargin(ChangePerpMarginParams{Symbol: "BTCUSDT", Margin: decimal.RequireFromString("-12.5")})
+	inverseMarginErr := bybit.ChangePerpMargin(ChangePerpMarginParams{Symbol: "BTCUSD", Margin: decimal.RequireFromString("0.01")})
+	mu.Lock()
+	hedged = true
+	mu.Unlock()
+	hedgedMarginErr := bybit.ChangePerpMargin(ChangePerpMarginParams{Symbol: "BTCUSDT", Margin: decimal.NewFromInt(5)})
+
+	fmt.Printf("Calls: %v\nErrors: %v, %v, %v, %v\n", calls, splitLeverageErr, invalidModeErr, inverseMarginErr, hedgedMarginErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(linearLeverageErr)
+	assert.Equal("5", requests[SET_LINEAR_LEVERAGE].Get("buy_leverage"))
+	assert.Equal("3", requests[SET_LINEAR_LEVERAGE].Get("sell_leverage"))
+	assert.NoError(inverseLeverageErr)
+	assert.Equal("2", requests[SET_PERP_LEVERAGE].Get("leverage"))
+	assert.Error(splitLeverageErr)
+
+	assert.NoError(marginModeErr)
+	assert.Equal("true", requests[SWITCH_LINEAR_ISOLATED].Get("is_isolated"))
+	assert.Equal("10", requests[SWITCH_LINEAR_ISOLATED].Get("sell_leverage"))
+
+	assert.NoError(positionModeErr)
+	assert.Equal("3", requests[SWITCH_PERP_POSITION_MODE].Get("mode"))
+	assert.Error(invalidModeErr)
+	assert.NoError(tpSlModeErr)
+	assert.Equal(TP_SL_MODE_PARTIAL, requests[SWITCH_LINEAR_TP_SL_MODE].Get("tp_sl_mode"))
+
+	assert.NoError(linearMarginErr)
+	assert.Equal(ORDER_SIDE_SELL, requests[CHANGE_LINEAR_MARGIN].Get("side"))
+	assert.Equal("-12.5", requests[CHANGE_LINEAR_MARGIN].Get("margin"))
+	assert.ErrorIs(inverseMarginErr, ErrInsufficientBalance)
+	assert.ErrorContains(hedgedMarginErr, "set Side")
+
+	// a rejected margin change isn't resent, nor is one with both legs open sent
+	marginCalls, linearMarginCalls := 0, 0
+	for _, call := range calls {
+		switch call {
+		case http.MethodPost + " " + CHANGE_PERP_MARGIN:
+			marginCalls++
+		case http.MethodPost + " " + CHANGE_LINEAR_MARGIN:
+			linearMarginCalls++
+		}
+	}
+	assert.Equal(1, marginCalls)
+	assert.Equal(1, linearMarginCalls)
+	assert.ErrorIs(checkApiResponse(SET_PERP_LEVERAGE, http.StatusOK, ApiResponse{RetCode: 34036}), ErrNotModified)
+}
+
+func TestTradingStop(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestTradingStop")
+
+	// Set up test, BTCUSDT has a short open and later both legs, the second BTCUSD call sets stops that already hold
+	var mu sync.Mutex
+	var calls []string
+	var hedged bool
+	requests := map[string]url.Values{}
+	inverseStops := 0
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		requests[r.URL.Path] = query
+
+		switch r.URL.Path {
+		case GET_LINEAR_POSITION:
+			if hedged {
+				fmt.Fprint(w, `{"ret_code":0,"result":[{"symbol":"BTCUSDT","side":"Buy","size":0.1},{"symbol":"BTCUSDT","side":"Sell","size":0.5}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"symbol":"BTCUSDT","side":"Buy","size":0},{"symbol":"BTCUSDT","side":"Sell","size":0.5,"stop_loss":21000,"take_profit":0}]}`)
+		case SET_LINEAR_TRADING_STOP:
+			fmt.Fprint(w, `{"ret_code":0,"result":null}`)
+		case SET_PERP_TRADING_STOP:
+			if inverseStops++; inverseStops > 1 {
+				fmt.Fprint(w, `{"ret_code":34040,"ret_msg":"not modified"}`)
+				return
+			}
+			fmt.Fprint(w, `{"ret_code":0,"result":{"symbol":"BTCUSD","side":"Buy","size":100,"take_profit":"22000","stop_loss":"0","trailing_stop":"150"}}`)
+		case GET_PERP_POSITION:
+			fmt.Fprint(w, `{"ret_code":0,"result":{"symbol":"BTCUSD","side":"Buy","size":100,"take_profit":"22000","trailing_stop":"150"}}`)
+		}
+	})
+	defer server.Close()
+
+	stopLoss := decimal.NewFromInt(21000)
+	takeProfit := decimal.NewFromInt(22000)
+	trailingStop := decimal.NewFromInt(150)
+
+	// Run test
+	linearPosition, linearErr := bybit.SetTradingStop(TradingStopParams{Symbol: "BTCUSDT", StopLoss: &stopLoss, SlTriggerBy: TRIGGER_BY_MARK_PRICE, SlSize: decimal.RequireFromString("0.2"), TakeProfit: &decimal.Zero})
+	inversePosition, inverseErr := bybit.SetTradingStop(TradingStopParams{Symbol: "BTCUSD", TakeProfit: &takeProfit, TrailingStop: &trailingStop, StopLoss: &decimal.Zero})
+	heldPosition, heldErr := bybit.SetTradingStop(TradingStopParams{Symbol: "BTCUSD", TakeProfit: &takeProfit})
+	_, noStopsErr := bybit.SetTradingStop(TradingStopParams{Symbol: "BTCUSD"})
+	_, triggerErr := bybit.SetTradingStop(TradingStopParams{Symbol: "BTCUSD", TakeProfit: &takeProfit, TpTriggerBy: "Last"})
+	mu.Lock()
+	hedged = true
+	mu.Unlock()
+	_, hedgedErr := bybit.SetTradingStop(TradingStopParams{Symbol: "BTCUSDT", StopLoss: &stopLoss})
+
+	fmt.Printf("Calls: %v\nPositions: %+v\n%+v\nErrors: %v, %v, %v\n", calls, linearPosition, inversePosition, noStopsErr, triggerErr, hedgedErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(linearErr)
+	assert.Equal("Sell", linearPosition.Side)
+	assert.True(linearPosition.StopLoss.Equal(stopLoss))
+	linearStop := requests[SET_LINEAR_TRADING_STOP]
+	assert.Equal(ORDER_SIDE_SELL, linearStop.Get("side"))
+	assert.Equal("21000", linearStop.Get("stop_loss"))
+	assert.Equal("0", linearStop.Get("take_profit"))
+	assert.Equal(TRIGGER_BY_MARK_PRICE, linearStop.Get("sl_trigger_by"))
+	assert.Equal("0.2", linearStop.Get("sl_size"))
+	assert.False(linearStop.Has("trailing_stop"))
+	assert.False(linearStop.Has("tp_size"))
+
+	assert.NoError(inverseErr)
+	assert.True(inversePosition.TrailingStop.Equal(trailingStop))
+	assert.NoError(heldErr)
+	assert.True(heldPosition.TakeProfit.Equal(takeProfit))
+	assert.Error(noStopsErr)
+	assert.Error(triggerErr)
+	assert.ErrorContains(hedgedErr, "set Side")
+
+	// the open side is looked up before the linear stop and the position listed again after it,
+	// with both legs open no stop is sent
+	assert.Equal([]string{
+		http.MethodGet + " " + GET_LINEAR_POSITION, http.MethodPost + " " + SET_LINEAR_TRADING_STOP, http.MethodGet + " " + GET_LINEAR_POSITION,
+		http.MethodPost + " " + SET_PERP_TRADING_STOP,
+		http.MethodPost + " " + SET_PERP_TRADING_STOP, http.MethodGet + " " + GET_PERP_POSITION,
+		http.MethodGet + " " + GET_LINEAR_POSITION,
+	}, calls)
+}
+
+func TestHedgeMode(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	fmt.Println(">>> From TestHedgeMode")
+
+	// Set up test, BTCUSDT in hedge mode with both legs open, 0.3 long and 0.1 short
+	var mu sync.Mutex
+	var placed []url.Values
+	var placedStop url.Values
+	bybit, server := newMockExchange(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case PLACE_LINEAR_ORDER:
+			mu.Lock()
+			placed = append(placed, r.URL.Query())
+			mu.Unlock()
+			fmt.Fprint(w, `{"ret_code":0,"result":{"order_id":"o1"}}`)
+		case PLACE_LINEAR_STOP_ORDER:
+			mu.Lock()
+			placedStop = r.URL.Query()
+			mu.Unlock()
+			fmt.Fprint(w, `{"ret_code":0,"result":{"stop_order_id":"s1"}}`)
+		case GET_LINEAR_POSITION:
+			fmt.Fprint(w, `{"ret_code":0,"result":[{"symbol":"BTCUSDT","side":"Buy","size":0.3,"entry_price":19000,"position_idx":1},{"symbol":"BTCUSDT","side":"Sell","size":0.1,"entry_price":21000,"position_idx":2}]}`)
+		}
+	})
+	defer server.Close()
+	client := NewBybitRestClient(bybit)
+
+	longSide, shortSide := exchange.POSITION_SIDE_LONG, exchange.POSITION_SIDE_SHORT
+	order := exchange.PlaceOrderParams{BaseCurrency: "BTC", QuoteCurrency: "USDT", OrderType: exchange.ORDER_TYPE_LIMIT, Price: decimal.NewFromInt(19000), Size: decimal.RequireFromString("0.01")}
+	openLong, closeShort, wrongLeg, unknownSide, oneWay := order, order, order, order, order
+	openLong.OrderSide, openLong.PositionSide = exchange.ORDER_SIDE_BUY, longSide
+	closeShort.OrderSide, closeShort.PositionSide, closeShort.ReduceOnly = exchange.ORDER_SIDE_BUY, shortSide, true
+	wrongLeg.OrderSide, wrongLeg.PositionSide, wrongLeg.ReduceOnly = exchange.ORDER_SIDE_BUY, longSide, true
+	oversizedClose := closeShort
+	oversizedClose.Size = decimal.RequireFromString("0.5")
+	unknownSide.OrderSide, unknownSide.PositionSide = exchange.ORDER_SIDE_SELL, "both"
+	oneWay.OrderSide = exchange.ORDER_SIDE_SELL
+
+	// Run test
+	_, openLongErr := client.PlacePerpOrder(openLong)
+	_, closeShortErr := client.PlacePerpOrder(closeShort)
+	_, wrongLegErr := client.PlacePerpOrder(wrongLeg)
+	_, oversizedCloseErr := client.PlacePerpOrder(oversizedClose)
+	_, unknownSideErr := client.PlacePerpOrder(unknownSide)
+	_, oneWayErr := client.PlacePerpOrder(oneWay)
+	positions, positionsErr := client.GetPerpPositions("BTCUSDT")
+	_, hedgedErr := client.GetPerpPosition("BTCUSDT")
+	_, hedgedPerpErr := bybit.GetPerpPosition("BTCUSDT")
+
+	bybit.orderValidation = &OrderValidation{}
+	bybit.instruments = newInstrumentRegistry(func(ctx context.Context) ([]Instrument, error) {
+		return []Instrument{{
+			Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_LINEAR_PERPETUAL, Status: INSTRUMENT_STATUS_TRADING,
+			TickSize: decimal.RequireFromString("0.5"), QtyStep: decimal.RequireFromString("0.001"), MinQty: decimal.RequireFromString("0.001"), MaxQty: decimal.NewFromInt(100),
+		}}, nil
+	}, time.Minute, bybit.logger)
+	perpOrder := PlacePerpOrderParams{Symbol: "BTCUSDT", OrderType: PLACE_PERP_LIMIT, Price: decimal.NewFromInt(19000), Qty: decimal.RequireFromString("0.01"), TimeInForce: PLACE_PERP_GTC, ReduceOnly: true}
+	closeLong, addLong, badIdx := perpOrder, perpOrder, perpOrder
+	closeLong.Side, closeLong.PositionIdx = PLACE_PERP_SELL, POSITION_IDX_HEDGE_BUY
+	addLong.Side, addLong.PositionIdx = PLACE_PERP_BUY, POSITION_IDX_HEDGE_BUY
+	badIdx.Side, badIdx.PositionIdx = PLACE_PERP_BUY, 3
+	_, closeLongErr := bybit.ValidatePerpOrder(closeLong)
+	_, addLongErr := bybit.ValidatePerpOrder(addLong)
+	_, badIdxErr := bybit.ValidatePerpOrder(badIdx)
+	stopOrder := PlaceConditionalOrderParams{Side: PLACE_PERP_SELL, Symbol: "BTCUSDT", OrderType: PLACE_PERP_MARKET, Qty: decimal.RequireFromString("0.01"), BasePrice: decimal.NewFromInt(20000), StopPx: decimal.NewFromInt(18000), TimeInForce: PLACE_PERP_GTC, ReduceOnly: true, PositionIdx: POSITION_IDX_HEDGE_BUY}
+	stopOrderId, stopErr := bybit.PlaceConditionalOrder(stopOrder)
+	addLongStop := stopOrder
+	addLongStop.Side = PLACE_PERP_BUY
+	_, addLongStopErr := bybit.ValidateConditionalOrder(addLongStop)
+
+	fmt.Printf("Placed: %v, %v\nPositions: %+v\nErrors: %v, %v, %v, %v, %v\n", placed, placedStop, positions, wrongLegErr, unknownSideErr, addLongErr, badIdxErr, addLongStopErr)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(openLongErr)
+	assert.NoError(closeShortErr)
+	assert.ErrorIs(wrongLegErr, ErrReduceOnlyViolation)
+	assert.ErrorIs(oversizedCloseErr, ErrReduceOnlyViolation, "A reduce-only order larger than its leg shouldn't be sent.")
+	assert.Error(unknownSideErr)
+	assert.NoError(oneWayErr)
+	require.Len(placed, 3)
+	assert.Equal("1", placed[0].Get("position_idx"))
+	assert.Equal("2", placed[1].Get("position_idx"))
+	assert.Equal("true", placed[1].Get("reduce_only"))
+	assert.False(placed[2].Has("position_idx"))
+
+	assert.NoError(positionsErr)
+	require.Len(positions, 2)
+	assert.Equal(exchange.ORDER_SIDE_BUY, positions[0].Side)
+	assert.Equal(0.3, positions[0].NetSize)
+	assert.Equal(exchange.ORDER_SIDE_SELL, positions[1].Side)
+	assert.Equal(-0.1, positions[1].NetSize)
+	assert.ErrorIs(hedgedErr, ErrHedgedPosition, "A single position shouldn't hide the other open leg.")
+	assert.ErrorIs(hedgedPerpErr, ErrHedgedPosition)
+
+	assert.NoError(closeLongErr)
+	assert.ErrorIs(addLongErr, ErrReduceOnlyViolation, "Validation should reject a reduce-only order on the wrong leg like the unified client.")
+	var validationErr *ValidationError
+	assert.True(errors.As(badIdxErr, &validationErr))
+	assert.Equal("position_idx", validationErr.Field)
+
+	assert.NoError(stopErr)
+	assert.Equal("s1", stopOrderId)
+	assert.Equal("1", placedStop.Get("position_idx"))
+	assert.ErrorIs(addLongStopErr, ErrReduceOnlyViolation)
+}
+
+func TestV5HeaderSigning(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestV5HeaderSigning")
+
+	// Set up test, a v5 client whose clock reads the local time
+	v5 := NewBybitV5Exchange("secret", "key")
+	v5.bybit.timeSync = newTimeSync(func(ctx context.Context) (float64, error) {
+		return float64(time.Now().UnixMilli()) / 1000, nil
+	}, time.Minute, v5.bybit.logger)
+
+	getParams := map[string]interface{}{"category": CATEGORY_LINEAR, "symbol": "BTCUSDT"}
+	postParams := map[string]interface{}{"category": CATEGORY_LINEAR, "symbol": "BTCUSDT", "orderId": "1"}
+
+	// Run test
+	getReq, getErr := v5.bybit.buildRequest(context.Background(), "https://api-testnet.bybit.com", newHeaderSignedRequest(http.MethodGet, V5_POSITIONS, getParams))
+	postReq, postErr := v5.bybit.buildRequest(context.Background(), "https://api-testnet.bybit.com", newHeaderSignedRequest(http.MethodPost, V5_CANCEL_ORDER, postParams))
+
+	fmt.Printf("GET: %v %v\nPOST: %v %v\n", getReq.URL, getReq.Header, postReq.URL, postReq.Header)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(getErr)
+	assert.Equal("category=linear&symbol=BTCUSDT", getReq.URL.RawQuery)
+	assert.Equal("key", getReq.Header.Get("X-BAPI-API-KEY"))
+	assert.Equal("2", getReq.Header.Get("X-BAPI-SIGN-TYPE"))
+	getHeader := getReq.Header
+	assert.Equal(v5.bybit.sign(getHeader.Get("X-BAPI-TIMESTAMP")+"key"+getHeader.Get("X-BAPI-RECV-WINDOW")+getReq.URL.RawQuery), getHeader.Get("X-BAPI-SIGN"))
+
+	assert.NoError(postErr)
+	body, _ := io.ReadAll(postReq.Body)
+	assert.JSONEq(`{"category":"linear","symbol":"BTCUSDT","orderId":"1"}`, string(body))
+	assert.Empty(postReq.URL.RawQuery, "POST params should only be sent in the body.")
+	postHeader := postReq.Header
+	assert.Equal(v5.bybit.sign(postHeader.Get("X-BAPI-TIMESTAMP")+"key"+postHeader.Get("X-BAPI-RECV-WINDOW")+string(body)), postHeader.Get("X-BAPI-SIGN"))
+}
+
+func TestV5ResponseMapping(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestV5ResponseMapping")
+
+	// Set up test
+	raw := `{"retCode":0,"retMsg":"OK","result":{"category":"linear","list":[{"orderId":"1f2","orderLinkId":"link-1","symbol":"BTCUSDT","side":"Buy","orderType":"Limit","price":"20000.5","qty":"0.010","timeInForce":"IOC","orderStatus":"PartiallyFilled","leavesQty":"0.004","cumExecQty":"0.006","positionIdx":0,"createdTime":"1672531200000","updatedTime":"1672531201000"}],"nextPageCursor":""},"time":1672531201234}`
+	var response ResponseForV5Orders
+
+	// Run test
+	decodeErr := json.Unmarshal([]byte(raw), &response)
+	perpOrder := v5OrderToPerpOrder(response.Result.List[0])
+	spotOrder := v5OrderToSpotOrder(response.Result.List[0])
+
+	fmt.Printf("Perp order: %+v\nSpot order: %+v\n", perpOrder, spotOrder)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(decodeErr)
+	assert.Equal(0, response.apiResponse().RetCode)
+	assert.Equal(PLACE_PERP_IMMEDIATE_OR_CANCEL, perpOrder.TimeInForce)
+	assert.Equal("2023-01-01T00:00:00Z", perpOrder.CreatedAt)
+	assert.Equal("0.004", perpOrder.LeavesQty)
+	assert.Equal("PARTIALLY_FILLED", spotOrder.Status)
+	assert.True(spotOrder.IsWorking)
+	assert.Equal("BUY", spotOrder.Side)
+
+	assert.Equal(V5_GTC, toV5TimeInForce(PLACE_PERP_GTC))
+}
+
+func TestV5PerpRouting(t *testing.T) {
+	assert := assert.New(t)
+	fmt.Println(">>> From TestV5PerpRouting")
+
+	// Set up test, BTCUSDT in hedge mode with a flat buy leg listed before an open sell leg, BTC-29DEC23 with both legs open
+	var mu sync.Mutex
+	var categories []string
+	v5, server := newMockV5Exchange(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		categories = append(categories, r.URL.Query().Get("category"))
+		mu.Unlock()
+
+		switch r.URL.Query().Get("symbol") {
+		case "BTCUSDT":
+			fmt.Fprint(w, `{"retCode":0,"result":{"category":"linear","list":[{"positionIdx":1,"symbol":"BTCUSDT","side":"Buy","size":"0"},{"positionIdx":2,"symbol":"BTCUSDT","side":"Sell","size":"0.2","avgPrice":"21000"}]}}`)
+		case "BTC-29DEC23":
+			fmt.Fprint(w, `{"retCode":0,"result":{"category":"linear","list":[{"positionIdx":1,"symbol":"BTC-29DEC23","side":"Buy","size":"0.1"},{"positionIdx":2,"symbol":"BTC-29DEC23","side":"Sell","size":"0.2"}]}}`)
+		default:
+			fmt.Fprint(w, `{"retCode":0,"result":{"list":[]}}`)
+		}
+	})
+	defer server.Close()
+
+	// Run test
+	hedgePosition, hedgeErr := v5.GetPerpPosition("BTCUSDT")
+	_, futuresErr := v5.GetPerpPosition("BTCUSDZ22")
+	_, linearFuturesErr := v5.GetPerpPosition("BTC-29DEC23")
+	flatPosition, flatErr := v5.GetPerpPosition("BTCUSD")
+
+	fmt.Printf("Categories: %v\nPosition: %+v\n", categories, hedgePosition)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	assert.NoError(hedgeErr)
+	assert.Equal(ORDER_SIDE_SELL, hedgePosition.Side)
+	assert.Equal(POSITION_IDX_HEDGE_SELL, hedgePosition.PositionIdx)
+	assert.NoError(futuresErr)
+	assert.ErrorIs(linearFuturesErr, ErrHedgedPosition)
+	assert.NoError(flatErr)
+	assert.Equal("None", flatPosition.Side)
+	assert.Equal([]string{CATEGORY_LINEAR, CATEGORY_INVERSE, CATEGORY_LINEAR, CATEGORY_INVERSE}, categories)
+}
+
+func (suite *BybitTestSuite) TestV5GetMarketPrice() {
+	fmt.Println(">>> From TestV5GetMarketPrice")
+
+	// Set up test
+	v5 := NewBybitV5Exchange(suite.Exchange.SecretKey, suite.Exchange.ApiKey, WithEnvironment(ENV_TESTNET))
+
+	// Run test
+	spotPrice, spotErr := v5.GetMarketPrice("BTC", "USDT", exchange.MARKET_TYPE_SPOT)
+	perpPrice, perpErr := v5.GetPerpMarketPrice("BTCUSD")
+
+	fmt.Printf("Spot price: %v, perp price: %v\n", spotPrice, perpPrice)
+	fmt.Printf("---------------------------------\n")
+
+	// Assert test
+	suite.NoError(spotErr, "Couldn't get v5 spot price.")
+	suite.Greater(spotPrice, 0.0)
+	suite.NoError(perpErr, "Couldn't get v5 perp price.")
+	suite.Greater(perpPrice, 0.0)
+}
+
 func TestBybitExchangeTestSuite(t *testing.T) {
 	suite.Run(t, new(BybitTestSuite))
 }
+
+// a client sending every request to handler, with a local clock and a BTCUSD inverse and BTCUSDT linear perp
+func newMockV5Exchange(handler http.HandlerFunc) (*BybitV5Exchange, *httptest.Server) {
+	bybit, server := newMockExchange(handler)
+	bybit.instruments = newInstrumentRegistry(func(ctx context.Context) ([]Instrument, error) {
+		return []Instrument{
+			{Symbol: "BTCUSD", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_INVERSE_PERPETUAL},
+			{Symbol: "BTCUSDZ22", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_INVERSE_FUTURES},
+			{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_LINEAR_PERPETUAL},
+			{Symbol: "BTC-29DEC23", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_LINEAR_FUTURES},
+		}, nil
+	}, time.Minute, bybit.logger)
+	bybit.serverTimePath = V5_SERVER_TIME
+	return &BybitV5Exchange{bybit: bybit}, server
+}
+
+func newMockExchange(handler http.HandlerFunc) (*BybitExchange, *httptest.Server) {
+	server := httptest.NewServer(handler)
+
+	bybit := NewBybitExchange("secret", "key", WithBaseURL(server.URL))
+	bybit.timeSync = newTimeSync(func(ctx context.Context) (float64, error) {
+		return float64(time.Now().UnixMilli()) / 1000, nil
+	}, time.Minute, bybit.logger)
+	bybit.instruments = newInstrumentRegistry(func(ctx context.Context) ([]Instrument, error) {
+		return []Instrument{
+			{Symbol: "BTCUSD", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_INVERSE_PERPETUAL},
+			{Symbol: "BTCUSDZ22", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_INVERSE_FUTURES},
+			{Symbol: "BTCUSDT", MarketType: exchange.MARKET_TYPE_PERP, ContractType: CONTRACT_TYPE_LINEAR_PERPETUAL},
+		}, nil
+	}, time.Minute, bybit.logger)
+	return bybit, server
+}
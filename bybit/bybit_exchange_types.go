@@ -1,5 +1,11 @@
 package bybit_exchange
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 type ApiResponse struct {
 	RetCode int    `json:"ret_code"`
 	RetMsg  string `json:"ret_msg"`
@@ -8,6 +14,15 @@ type ApiResponse struct {
 	TimeNow string `json:"time_now"`
 }
 
+// implemented by every response envelope so ret codes are checked in one place
+type apiResponder interface {
+	apiResponse() ApiResponse
+}
+
+func (response ApiResponse) apiResponse() ApiResponse {
+	return response
+}
+
 type ResponseForGetSpotMarketPrice struct {
 	ApiResponse
 	Result SpotMarketPrice `json:"result"`
@@ -17,6 +32,19 @@ type SpotMarketPrice struct {
 	BidPrice string `json:"bidPrice"`
 	BidQty   string `json:"bidQty"`
 	AskPrice string `json:"askPrice"`
+	AskQty   string `json:"askQty"`
+	Time     int64  `json:"time"`
+}
+
+type ResponseForGetSpotMergedDepth struct {
+	ApiResponse
+	Result SpotDepth `json:"result"`
+}
+
+type SpotDepth struct {
+	Time int64      `json:"time"`
+	Bids [][]string `json:"bids"` // [price, size]
+	Asks [][]string `json:"asks"`
 }
 
 type ResponseForGetPerpMarketPrice struct {
@@ -30,15 +58,61 @@ type ResponseForGetPerpSymbolsInfo struct {
 }
 
 type PerpSymbolInfo struct {
-	Name        string      `json:"name"`
-	PriceFilter PriceFilter `json:"price_filter"`
+	Name            string         `json:"name"`
+	Alias           string         `json:"alias"`
+	Status          string         `json:"status"`
+	BaseCurrency    string         `json:"base_currency"`
+	QuoteCurrency   string         `json:"quote_currency"`
+	PriceScale      int            `json:"price_scale"`
+	TakerFee        string         `json:"taker_fee"`
+	MakerFee        string         `json:"maker_fee"`
+	FundingInterval int            `json:"funding_interval"`
+	LeverageFilter  LeverageFilter `json:"leverage_filter"`
+	PriceFilter     PriceFilter    `json:"price_filter"`
+	LotSizeFilter   LotSizeFilter  `json:"lot_size_filter"`
+}
+
+type LeverageFilter struct {
+	MinLeverage  decimal.Decimal `json:"min_leverage"`
+	MaxLeverage  decimal.Decimal `json:"max_leverage"`
+	LeverageStep decimal.Decimal `json:"leverage_step"`
 }
 
 type PriceFilter struct {
+	MinPrice string `json:"min_price"`
 	MaxPrice string `json:"max_price"`
 	TickSize string `json:"tick_size"`
 }
 
+type LotSizeFilter struct {
+	MaxTradingQty         decimal.Decimal `json:"max_trading_qty"`
+	MinTradingQty         decimal.Decimal `json:"min_trading_qty"`
+	QtyStep               decimal.Decimal `json:"qty_step"`
+	PostOnlyMaxTradingQty decimal.Decimal `json:"post_only_max_trading_qty"`
+}
+
+type ResponseForGetSpotSymbolsInfo struct {
+	ApiResponse
+	Result []SpotSymbolInfo `json:"result"`
+}
+
+type SpotSymbolInfo struct {
+	Name              string          `json:"name"`
+	Alias             string          `json:"alias"`
+	BaseCurrency      string          `json:"baseCurrency"`
+	QuoteCurrency     string          `json:"quoteCurrency"`
+	BasePrecision     decimal.Decimal `json:"basePrecision"`
+	QuotePrecision    decimal.Decimal `json:"quotePrecision"`
+	MinTradeQuantity  decimal.Decimal `json:"minTradeQuantity"`
+	MinTradeAmount    decimal.Decimal `json:"minTradeAmount"`
+	MaxTradeQuantity  decimal.Decimal `json:"maxTradeQuantity"`
+	MaxTradeAmount    decimal.Decimal `json:"maxTradeAmount"`
+	MinPricePrecision decimal.Decimal `json:"minPricePrecision"`
+	Category          int             `json:"category"`
+	ShowStatus        bool            `json:"showStatus"`
+	Innovation        bool            `json:"innovation"`
+}
+
 type ResponseForGetServerTime struct {
 	ApiResponse
 	TimeNow string `json:"time_now"`
@@ -106,12 +180,12 @@ type SpotBalances struct {
 }
 
 type SpotBalance struct {
-	Coin     string `json:"coin"`
-	CoinId   string `json:"coinId"`
-	CoinName string `json:"coinName"`
-	Total    string `json:"total"`
-	Free     string `json:"free"`
-	Locked   string `json:"locked"`
+	Coin     string          `json:"coin"`
+	CoinId   string          `json:"coinId"`
+	CoinName string          `json:"coinName"`
+	Total    decimal.Decimal `json:"total"`
+	Free     decimal.Decimal `json:"free"`
+	Locked   decimal.Decimal `json:"locked"`
 }
 
 type ResponseForGetPerpWalletBalance struct {
@@ -120,25 +194,106 @@ type ResponseForGetPerpWalletBalance struct {
 }
 
 type PerpBalance struct {
-	Equity           float64 `json:"equity"`
-	AvailableBalance float64 `json:"available_balance"`
-	UsedMargin       float64 `json:"used_margin"`
-	OrderMargin      float64 `json:"order_margin"`
-	PositionMargin   float64 `json:"position_margin"`
-	OccClosingFee    float64 `json:"occ_closing_fee"`
-	OccFundingFee    float64 `json:"occ_funding_fee"`
-	WalletBalance    float64 `json:"wallet_balance"`
-	RealisedPnl      float64 `json:"realised_pnl"`
-	UnrealisedPnl    float64 `json:"unrealised_pnl"`
-	CumRealisedPnl   float64 `json:"cum_realised_pnl"`
-	GivenCash        float64 `json:"given_cash"`
-	ServiceCash      float64 `json:"service_cash"`
+	Equity           decimal.Decimal `json:"equity"`
+	AvailableBalance decimal.Decimal `json:"available_balance"`
+	UsedMargin       decimal.Decimal `json:"used_margin"`
+	OrderMargin      decimal.Decimal `json:"order_margin"`
+	PositionMargin   decimal.Decimal `json:"position_margin"`
+	OccClosingFee    decimal.Decimal `json:"occ_closing_fee"`
+	OccFundingFee    decimal.Decimal `json:"occ_funding_fee"`
+	WalletBalance    decimal.Decimal `json:"wallet_balance"`
+	RealisedPnl      decimal.Decimal `json:"realised_pnl"`
+	UnrealisedPnl    decimal.Decimal `json:"unrealised_pnl"`
+	CumRealisedPnl   decimal.Decimal `json:"cum_realised_pnl"`
+	GivenCash        decimal.Decimal `json:"given_cash"`
+	ServiceCash      decimal.Decimal `json:"service_cash"`
 }
 
 type PerpMarketPrice struct {
-	Symbol     string `json:"symbol"`
-	MarkPrice  string `json:"mark_price"`
-	IndexPrice string `json:"index_price"`
+	Symbol               string `json:"symbol"`
+	MarkPrice            string `json:"mark_price"`
+	IndexPrice           string `json:"index_price"`
+	FundingRate          string `json:"funding_rate"`
+	PredictedFundingRate string `json:"predicted_funding_rate"`
+	NextFundingTime      string `json:"next_funding_time"` // RFC3339
+}
+
+// Funding rate of the current interval and bybit's prediction for the next one
+type FundingRate struct {
+	Symbol               string
+	FundingRate          decimal.Decimal // settled at NextFundingTime, positive when longs pay shorts
+	PredictedFundingRate decimal.Decimal
+	NextFundingTime      time.Time
+}
+
+// A settled funding rate
+type HistoricalFundingRate struct {
+	Symbol      string
+	FundingRate decimal.Decimal
+	FundingTime time.Time
+}
+
+// A candle over [StartTime, StartTime + interval)
+type Kline struct {
+	Symbol    string
+	Interval  KlineInterval
+	StartTime time.Time
+	Open      decimal.Decimal
+	High      decimal.Decimal
+	Low       decimal.Decimal
+	Close     decimal.Decimal
+	Volume    decimal.Decimal // base coin for spot and linear, contracts for inverse, zero for price klines
+	Turnover  decimal.Decimal // quote coin for spot and linear, base coin for inverse, zero for price klines
+}
+
+type RecentTradesParams struct {
+	Symbol     string    // required, e.g. "BTCUSDT"
+	MarketType string    // required, exchange.MARKET_TYPE_SPOT or exchange.MARKET_TYPE_PERP
+	StartTime  time.Time // inclusive, bybit only serves the latest trades so older ones aren't reached
+	EndTime    time.Time // inclusive
+	Limit      int       // defaults to the most bybit serves
+}
+
+// Filters of the open interest and long/short ratio histories
+type MarketStatsParams struct {
+	Symbol    string    // required, e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+	Period    string    // required, e.g. PERIOD_1HOUR
+	StartTime time.Time // inclusive
+	EndTime   time.Time // inclusive
+	Limit     int       // page size, defaults to the most bybit allows
+}
+
+// Contracts open at Time, both sides summed, in USD for inverse perps and the base coin for linear ones
+type OpenInterest struct {
+	Symbol       string
+	OpenInterest decimal.Decimal
+	Time         time.Time
+}
+
+// Share of accounts holding long and short positions at Time
+type LongShortRatio struct {
+	Symbol    string
+	BuyRatio  decimal.Decimal
+	SellRatio decimal.Decimal
+	Time      time.Time
+}
+
+type OrderbookParams struct {
+	Symbol     string // required, e.g. "BTCUSDT"
+	MarketType string // required, exchange.MARKET_TYPE_SPOT or exchange.MARKET_TYPE_PERP
+	Depth      int    // levels per side, defaults to DEFAULT_ORDERBOOK_DEPTH
+	Merged     bool   // spot only, aggregates the levels to Scale decimals
+	Scale      int
+}
+
+type KlineParams struct {
+	Symbol     string        // required, e.g. "BTCUSDT"
+	MarketType string        // required, exchange.MARKET_TYPE_SPOT or exchange.MARKET_TYPE_PERP
+	Type       KlineType     // defaults to KLINE_TYPE_TRADE, the other types are perps only
+	Interval   KlineInterval // required
+	StartTime  time.Time     // inclusive, required by GetKlineRange
+	EndTime    time.Time     // exclusive, defaults to now
+	Limit      int           // GetKlines only, defaults to the most bybit allows
 }
 
 type ResponseForGetSpotOrder struct {
@@ -170,11 +325,61 @@ type SpotOrder struct {
 	Locked              string `json:"locked"`
 }
 
+type ResponseForGetSpotOrders struct {
+	ApiResponse
+	Result []SpotOrder `json:"result"`
+}
+
+// Filters of the spot order and trade listings, zero values don't filter
+type SpotListParams struct {
+	Symbol    string    // e.g. "BTCUSDT", "" for every symbol
+	StartTime time.Time // inclusive
+	EndTime   time.Time // inclusive
+	Limit     int       // page size, defaults to the most bybit allows
+}
+
+type ResponseForGetSpotTrades struct {
+	ApiResponse
+	Result []SpotTrade `json:"result"`
+}
+
+type SpotTrade struct {
+	Id              string          `json:"id"`
+	Symbol          string          `json:"symbol"`
+	SymbolName      string          `json:"symbolName"`
+	OrderId         string          `json:"orderId"`
+	TicketId        string          `json:"ticketId"`
+	MatchOrderId    string          `json:"matchOrderId"`
+	Price           decimal.Decimal `json:"price"`
+	Qty             decimal.Decimal `json:"qty"`
+	Commission      decimal.Decimal `json:"commission"`
+	CommissionAsset string          `json:"commissionAsset"`
+	Time            string          `json:"time"` // unix millis
+	IsBuyer         bool            `json:"isBuyer"`
+	IsMaker         bool            `json:"isMaker"`
+	Fee             SpotTradeFee    `json:"fee"`
+	FeeTokenId      string          `json:"feeTokenId"`
+	FeeAmount       decimal.Decimal `json:"feeAmount"`
+	MakerRebate     decimal.Decimal `json:"makerRebate"`
+	ExecutionTime   string          `json:"executionTime"`
+}
+
+type SpotTradeFee struct {
+	FeeTokenId   string          `json:"feeTokenId"`
+	FeeTokenName string          `json:"feeTokenName"`
+	Fee          decimal.Decimal `json:"fee"`
+}
+
 type ResponseForGetPerpOrder struct {
 	ApiResponse
 	Result PerpOrders `json:"result"`
 }
 
+type ResponseForQueryPerpOrder struct {
+	ApiResponse
+	Result *PerpOrder `json:"result"`
+}
+
 type PerpOrders struct {
 	PerpOrders []PerpOrder `json:"data"`
 }
@@ -204,45 +409,179 @@ type PerpOrder struct {
 	SlTriggerBy  string `json:"sl_trigger_by"`
 }
 
+// linear endpoints return numbers unquoted, decoded into decimals and converted with linearOrderToPerpOrder
+type ResponseForGetLinearOrders struct {
+	ApiResponse
+	Result LinearOrders `json:"result"`
+}
+
+type ResponseForQueryLinearOrder struct {
+	ApiResponse
+	Result *LinearOrder `json:"result"`
+}
+
+type LinearOrders struct {
+	CurrentPage  int           `json:"current_page"`
+	LinearOrders []LinearOrder `json:"data"`
+}
+
+type LinearOrder struct {
+	OrderId        string          `json:"order_id"`
+	UserId         int             `json:"user_id"`
+	Symbol         string          `json:"symbol"`
+	Side           string          `json:"side"`
+	OrderType      string          `json:"order_type"`
+	Price          decimal.Decimal `json:"price"`
+	Qty            decimal.Decimal `json:"qty"`
+	TimeInForce    string          `json:"time_in_force"`
+	OrderStatus    string          `json:"order_status"`
+	LastExecPrice  decimal.Decimal `json:"last_exec_price"`
+	LeavesQty      decimal.Decimal `json:"leaves_qty"`
+	CumExecQty     decimal.Decimal `json:"cum_exec_qty"`
+	CumExecValue   decimal.Decimal `json:"cum_exec_value"`
+	CumExecFee     decimal.Decimal `json:"cum_exec_fee"`
+	ReduceOnly     bool            `json:"reduce_only"`
+	CloseOnTrigger bool            `json:"close_on_trigger"`
+	OrderLinkId    string          `json:"order_link_id"`
+	CreatedTime    string          `json:"created_time"`
+	UpdatedTime    string          `json:"updated_time"`
+	TakeProfit     decimal.Decimal `json:"take_profit"`
+	StopLoss       decimal.Decimal `json:"stop_loss"`
+	TpTriggerBy    string          `json:"tp_trigger_by"`
+	SlTriggerBy    string          `json:"sl_trigger_by"`
+	PositionIdx    int             `json:"position_idx"`
+}
+
+// Filters of the perp execution and closed pnl listings, zero values don't filter
+type PerpListParams struct {
+	Symbol    string    // required, e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+	StartTime time.Time // inclusive
+	EndTime   time.Time // inclusive
+	Limit     int       // page size, defaults to the most bybit allows
+	ExecType  string    // executions only, e.g. EXEC_TYPE_FUNDING, "" for every type
+}
+
+type ResponseForGetPerpExecutions struct {
+	ApiResponse
+	Result PerpExecutions `json:"result"`
+}
+
+type PerpExecutions struct {
+	OrderId   string          `json:"order_id"`
+	TradeList []PerpExecution `json:"trade_list"`
+}
+
+type ResponseForGetLinearExecutions struct {
+	ApiResponse
+	Result LinearExecutions `json:"result"`
+}
+
+type LinearExecutions struct {
+	CurrentPage int             `json:"current_page"`
+	Data        []PerpExecution `json:"data"`
+}
+
+// A fill of a perp order, qty is in USD for inverse contracts and base coin for linear ones
+type PerpExecution struct {
+	ExecId           string          `json:"exec_id"`
+	OrderId          string          `json:"order_id"`
+	OrderLinkId      string          `json:"order_link_id"`
+	Symbol           string          `json:"symbol"`
+	Side             string          `json:"side"`
+	OrderType        string          `json:"order_type"`
+	OrderPrice       decimal.Decimal `json:"order_price"`
+	OrderQty         decimal.Decimal `json:"order_qty"`
+	ExecType         string          `json:"exec_type"` // "Trade", "AdlTrade", "Funding" or "BustTrade"
+	ExecPrice        decimal.Decimal `json:"exec_price"`
+	ExecQty          decimal.Decimal `json:"exec_qty"`
+	ExecValue        decimal.Decimal `json:"exec_value"`
+	ExecFee          decimal.Decimal `json:"exec_fee"`
+	FeeRate          decimal.Decimal `json:"fee_rate"`
+	LeavesQty        decimal.Decimal `json:"leaves_qty"`
+	ClosedSize       decimal.Decimal `json:"closed_size"`
+	LastLiquidityInd string          `json:"last_liquidity_ind"` // "AddedLiquidity" for makers, "RemovedLiquidity" for takers
+	TradeTimeMs      int64           `json:"trade_time_ms"`
+	Inverse          bool            `json:"-"` // set from the instrument registry when listed, sizes ToFill in the base coin
+}
+
+type ResponseForGetPerpClosedPnl struct {
+	ApiResponse
+	Result PerpClosedPnls `json:"result"`
+}
+
+type PerpClosedPnls struct {
+	CurrentPage int             `json:"current_page"`
+	Data        []PerpClosedPnl `json:"data"`
+}
+
+// Realized pnl of an order that reduced or closed a position
+type PerpClosedPnl struct {
+	Id            int64           `json:"id"`
+	UserId        int             `json:"user_id"`
+	Symbol        string          `json:"symbol"`
+	OrderId       string          `json:"order_id"`
+	Side          string          `json:"side"`
+	Qty           decimal.Decimal `json:"qty"`
+	OrderPrice    decimal.Decimal `json:"order_price"`
+	OrderType     string          `json:"order_type"`
+	ExecType      string          `json:"exec_type"`
+	ClosedSize    decimal.Decimal `json:"closed_size"`
+	CumEntryValue decimal.Decimal `json:"cum_entry_value"`
+	AvgEntryPrice decimal.Decimal `json:"avg_entry_price"`
+	CumExitValue  decimal.Decimal `json:"cum_exit_value"`
+	AvgExitPrice  decimal.Decimal `json:"avg_exit_price"`
+	ClosedPnl     decimal.Decimal `json:"closed_pnl"`
+	FillCount     int             `json:"fill_count"`
+	Leverage      decimal.Decimal `json:"leverage"`
+	CreatedAt     int64           `json:"created_at"` // unix seconds
+}
+
 type ResponseForGetPerpPositions struct {
 	ApiResponse
 	Result PerpPosition `json:"result"`
 }
 
+// one position per side, in one-way mode the flat side has size 0
+type ResponseForGetLinearPositions struct {
+	ApiResponse
+	Result []PerpPosition `json:"result"`
+}
+
 type PerpPosition struct {
-	Id                  int     `json:"id"`
-	UserId              int     `json:"user_id"`
-	RiskId              int     `json:"risk_id"`
-	Symbol              string  `json:"symbol"`
-	Side                string  `json:"side"`
-	Size                float64 `json:"size"`
-	PositionValue       string  `json:"position_value"`
-	EntryPrice          string  `json:"entry_price"`
-	IsIsolated          bool    `json:"is_isolated"`
-	AutoAddMargin       int     `json:"auto_add_margin"`
-	Leverage            string  `json:"leverage"`
-	EffectiveLeverage   string  `json:"effective_leverage"`
-	PositionMargin      string  `json:"position_margin"`
-	LiqPrice            string  `json:"liq_price"`
-	BustPrice           string  `json:"bust_price"`
-	OccClosingFee       string  `json:"occ_closing_fee"`
-	OccFundingFee       string  `json:"occ_funding_fee"`
-	TakeProfit          string  `json:"take_profit"`
-	StopLoss            string  `json:"stop_loss"`
-	TrailingStop        string  `json:"trailing_stop"`
-	PositionStatus      string  `json:"position_status"`
-	DeleverageIndicator int     `json:"deleverage_indicator"`
-	OcCalcData          string  `json:"oc_calc_data"`
-	OrderMargin         string  `json:"order_margin"`
-	WalletBalance       string  `json:"wallet_balance"`
-	RealisedPnl         string  `json:"realised_pnl"`
-	UnrealisedPnl       float64 `json:"unrealised_pnl"`
-	CumRealisedPnl      string  `json:"cum_realised_pnl"`
-	CrossSeq            int     `json:"cross_seq"`
-	PositionSeq         int     `json:"position_seq"`
-	CreatedAt           string  `json:"created_at"`
-	UpdatedAt           string  `json:"updated_at"`
-	TpSlMode            string  `json:"tp_sl_mode"`
+	Id                  int             `json:"id"`
+	UserId              int             `json:"user_id"`
+	RiskId              int             `json:"risk_id"`
+	Symbol              string          `json:"symbol"`
+	Side                string          `json:"side"`
+	Size                decimal.Decimal `json:"size"`
+	PositionValue       decimal.Decimal `json:"position_value"`
+	EntryPrice          decimal.Decimal `json:"entry_price"`
+	IsIsolated          bool            `json:"is_isolated"`
+	AutoAddMargin       int             `json:"auto_add_margin"`
+	Leverage            decimal.Decimal `json:"leverage"`
+	EffectiveLeverage   decimal.Decimal `json:"effective_leverage"`
+	PositionMargin      decimal.Decimal `json:"position_margin"`
+	LiqPrice            decimal.Decimal `json:"liq_price"`
+	BustPrice           decimal.Decimal `json:"bust_price"`
+	OccClosingFee       decimal.Decimal `json:"occ_closing_fee"`
+	OccFundingFee       decimal.Decimal `json:"occ_funding_fee"`
+	TakeProfit          decimal.Decimal `json:"take_profit"`
+	StopLoss            decimal.Decimal `json:"stop_loss"`
+	TrailingStop        decimal.Decimal `json:"trailing_stop"`
+	PositionStatus      string          `json:"position_status"`
+	DeleverageIndicator int             `json:"deleverage_indicator"`
+	OcCalcData          string          `json:"oc_calc_data"`
+	OrderMargin         decimal.Decimal `json:"order_margin"`
+	WalletBalance       decimal.Decimal `json:"wallet_balance"`
+	RealisedPnl         decimal.Decimal `json:"realised_pnl"`
+	UnrealisedPnl       decimal.Decimal `json:"unrealised_pnl"`
+	CumRealisedPnl      decimal.Decimal `json:"cum_realised_pnl"`
+	CrossSeq            int             `json:"cross_seq"`
+	PositionSeq         int             `json:"position_seq"`
+	CreatedAt           string          `json:"created_at"`
+	UpdatedAt           string          `json:"updated_at"`
+	TpSlMode            string          `json:"tp_sl_mode"`
+	PositionIdx         int             `json:"position_idx"`
 }
 
 type ResponseForGetDepositAddress struct {
@@ -254,6 +593,10 @@ type ResponseForGetDepositAddress struct {
 	Result  CoinDepositAddress `json:"result"`
 }
 
+func (response ResponseForGetDepositAddress) apiResponse() ApiResponse {
+	return ApiResponse{RetCode: response.RetCode, RetMsg: response.RetMsg, ExtCode: response.ExtCode, ExtInfo: response.ExtInfo}
+}
+
 type CoinDepositAddress struct {
 	Coin   string                      `json:"coin"`
 	Chains []CoinDepositAddressOnChain `json:"chains"`
@@ -267,29 +610,286 @@ type CoinDepositAddressOnChain struct {
 }
 
 type PlaceSpotOrderParams struct {
-	Symbol      string  `structs:"symbol"`
-	Qty         float64 `structs:"qty"`
-	Side        string  `structs:"side"`
-	Type        string  `structs:"type"`
-	TimeInForce string  `structs:"timeInForce"`
-	Price       float64 `structs:"price"`
-	OrderLinkId string  `structs:"orderLinkId"`
+	Symbol      string          `structs:"symbol"`
+	Qty         decimal.Decimal `structs:"qty,omitnested"`
+	Side        string          `structs:"side"`
+	Type        string          `structs:"type"`
+	TimeInForce string          `structs:"timeInForce"`
+	Price       decimal.Decimal `structs:"price,omitnested"`
+	OrderLinkId string          `structs:"orderLinkId"`
 }
 
 type PlacePerpOrderParams struct {
-	Side           string  `structs:"side"`       //required
-	Symbol         string  `structs:"symbol"`     //required
-	OrderType      string  `structs:"order_type"` //required
-	Qty            float64 `structs:"qty"`        //required (in USD)
-	Price          float64 `structs:"price"`
-	TimeInForce    string  `structs:"time_in_force"` //required
-	ReduceOnly     bool    `structs:"reduce_only"`
-	CloseOnTrigger bool    `structs:"close_on_trigger"`
-	OrderLinkId    string  `structs:"order_link_id"`
-	TakeProfit     float64 `structs:"take_profit"`
-	StopLoss       float64 `structs:"stop_loss"`
-	TpTriggerBy    string  `structs:"tp_trigger_by"`
-	SlTriggerBy    string  `structs:"sl_trigger_by"`
+	Side           string          `structs:"side"`           //required
+	Symbol         string          `structs:"symbol"`         //required
+	OrderType      string          `structs:"order_type"`     //required
+	Qty            decimal.Decimal `structs:"qty,omitnested"` //required (in USD for inverse, base coin for linear)
+	Price          decimal.Decimal `structs:"price,omitnested"`
+	TimeInForce    string          `structs:"time_in_force"` //required
+	ReduceOnly     bool            `structs:"reduce_only"`
+	CloseOnTrigger bool            `structs:"close_on_trigger"`
+	OrderLinkId    string          `structs:"order_link_id"`
+	TakeProfit     decimal.Decimal `structs:"take_profit,omitnested"`
+	StopLoss       decimal.Decimal `structs:"stop_loss,omitnested"`
+	TpTriggerBy    string          `structs:"tp_trigger_by"`
+	SlTriggerBy    string          `structs:"sl_trigger_by"`
+	PositionIdx    int             `structs:"position_idx,omitempty"` // POSITION_IDX_*, the leg the order opens or reduces in hedge mode
+}
+
+// Changes to an active perp order, zero values are left unchanged. Set OrderId or OrderLinkId.
+type AmendPerpOrderParams struct {
+	Symbol      string          `structs:"symbol"` //required
+	OrderId     string          `structs:"order_id,omitempty"`
+	OrderLinkId string          `structs:"order_link_id,omitempty"`
+	Qty         decimal.Decimal `structs:"p_r_qty,omitnested,omitempty"` // the new total quantity, filled quantity included
+	Price       decimal.Decimal `structs:"p_r_price,omitnested,omitempty"`
+	TakeProfit  decimal.Decimal `structs:"take_profit,omitnested,omitempty"`
+	StopLoss    decimal.Decimal `structs:"stop_loss,omitnested,omitempty"`
+	TpTriggerBy string          `structs:"tp_trigger_by,omitempty"`
+	SlTriggerBy string          `structs:"sl_trigger_by,omitempty"`
+}
+
+type ResponseForAmendPerpOrder struct {
+	ApiResponse
+	Result AmendPerpOrderResult `json:"result"`
+}
+
+type AmendPerpOrderResult struct {
+	OrderId string `json:"order_id"`
+}
+
+// Outcome of one order of a batch, Err is nil when it went through
+type BatchOrderResult struct {
+	OrderId     string
+	OrderLinkId string
+	Err         error
+}
+
+// Filters of a spot batch cancel, zero values don't filter
+type BatchCancelSpotParams struct {
+	Symbol     string   // required, e.g. "BTCUSDT"
+	Side       string   // ORDER_SIDE_BUY or ORDER_SIDE_SELL
+	OrderTypes []string // e.g. SPOT_ORDER_TYPE_LIMIT, SPOT_ORDER_TYPE_LIMIT_MAKER
+}
+
+type ResponseForBatchCancelSpotOrders struct {
+	ApiResponse
+	Result BatchCancelSpotResult `json:"result"`
+}
+
+type BatchCancelSpotResult struct {
+	Success bool `json:"success"`
+}
+
+type ResponseForBatchCancelSpotOrdersByIds struct {
+	ApiResponse
+	Result []BatchCancelSpotFailure `json:"result"` // only the orders that weren't cancelled
+}
+
+type BatchCancelSpotFailure struct {
+	OrderId string `json:"orderId"`
+	Code    string `json:"code"`
+}
+
+type ResponseForCancelAllPerpOrders struct {
+	ApiResponse
+	Result []CancelAllPerpOrderResult `json:"result"`
+}
+
+type CancelAllPerpOrderResult struct {
+	OrderId     string `json:"clOrdID"`
+	Symbol      string `json:"symbol"`
+	Side        string `json:"side"`
+	OrderStatus string `json:"order_status"`
+}
+
+type ResponseForCancelAllLinearOrders struct {
+	ApiResponse
+	Result []string `json:"result"` // cancelled order ids
+}
+
+// Outcome of a spot cancel-replace
+type CancelReplaceResult struct {
+	CancelledOrderId string
+	ExecutedQty      decimal.Decimal // filled on the cancelled order before it was cancelled
+	OrderId          string          // the replacement, "" when it wasn't placed
+}
+
+// Stop and stop-limit perp order, triggered when the TriggerBy price crosses StopPx
+type PlaceConditionalOrderParams struct {
+	Side           string          `structs:"side"`           //required
+	Symbol         string          `structs:"symbol"`         //required
+	OrderType      string          `structs:"order_type"`     //required, PLACE_PERP_MARKET for stop, PLACE_PERP_LIMIT for stop-limit
+	Qty            decimal.Decimal `structs:"qty,omitnested"` //required (in USD for inverse, base coin for linear)
+	Price          decimal.Decimal `structs:"price,omitnested"`
+	BasePrice      decimal.Decimal `structs:"base_price,omitnested"` //required, the current price, tells bybit which way StopPx is crossed
+	StopPx         decimal.Decimal `structs:"stop_px,omitnested"`    //required
+	TimeInForce    string          `structs:"time_in_force"`         //required
+	TriggerBy      string          `structs:"trigger_by"`            // TRIGGER_BY_LAST_PRICE (default), TRIGGER_BY_MARK_PRICE or TRIGGER_BY_INDEX_PRICE
+	ReduceOnly     bool            `structs:"reduce_only"`
+	CloseOnTrigger bool            `structs:"close_on_trigger"`
+	OrderLinkId    string          `structs:"order_link_id"`
+	TakeProfit     decimal.Decimal `structs:"take_profit,omitnested"`
+	StopLoss       decimal.Decimal `structs:"stop_loss,omitnested"`
+	TpTriggerBy    string          `structs:"tp_trigger_by"`
+	SlTriggerBy    string          `structs:"sl_trigger_by"`
+	PositionIdx    int             `structs:"position_idx,omitempty"` // POSITION_IDX_*, the leg the order opens or reduces in hedge mode
+}
+
+// Changes to an untriggered conditional order, zero values are left unchanged
+type AmendConditionalOrderParams struct {
+	Symbol      string          `structs:"symbol"`        //required
+	StopOrderId string          `structs:"stop_order_id"` //required
+	Qty         decimal.Decimal `structs:"p_r_qty,omitnested,omitempty"`
+	Price       decimal.Decimal `structs:"p_r_price,omitnested,omitempty"`
+	StopPx      decimal.Decimal `structs:"p_r_trigger_price,omitnested,omitempty"`
+	TakeProfit  decimal.Decimal `structs:"take_profit,omitnested,omitempty"`
+	StopLoss    decimal.Decimal `structs:"stop_loss,omitnested,omitempty"`
+}
+
+// Filters of the conditional order listing
+type ConditionalListParams struct {
+	Symbol string // required, e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+	Status string // e.g. STOP_ORDER_STATUS_UNTRIGGERED, "" for every status
+}
+
+type ResponseForPlaceConditionalOrder struct {
+	ApiResponse
+	Result ConditionalOrderId `json:"result"`
+}
+
+type ConditionalOrderId struct {
+	StopOrderId string `json:"stop_order_id"`
+}
+
+// inverse endpoints, quoting some numbers and not others
+type ResponseForGetConditionalOrders struct {
+	ApiResponse
+	Result ConditionalOrders `json:"result"`
+}
+
+type ResponseForQueryConditionalOrder struct {
+	ApiResponse
+	Result *ConditionalOrder `json:"result"`
+}
+
+type ConditionalOrders struct {
+	ConditionalOrders []ConditionalOrder `json:"data"`
+	Cursor            string             `json:"cursor"`
+}
+
+// A stop or stop-limit perp order, kept apart from active orders until it triggers
+type ConditionalOrder struct {
+	StopOrderId    string          `json:"stop_order_id"`
+	UserId         int             `json:"user_id"`
+	Symbol         string          `json:"symbol"`
+	Side           string          `json:"side"`
+	OrderType      string          `json:"order_type"`
+	Price          decimal.Decimal `json:"price"`
+	Qty            decimal.Decimal `json:"qty"`
+	TimeInForce    string          `json:"time_in_force"`
+	OrderStatus    string          `json:"order_status"` // STOP_ORDER_STATUS_UNTRIGGERED, ...
+	StopOrderType  string          `json:"stop_order_type"`
+	TriggerBy      string          `json:"trigger_by"`
+	BasePrice      decimal.Decimal `json:"base_price"`
+	StopPx         decimal.Decimal `json:"stop_px"`
+	ReduceOnly     bool            `json:"reduce_only"`
+	CloseOnTrigger bool            `json:"close_on_trigger"`
+	OrderLinkId    string          `json:"order_link_id"`
+	CreatedAt      string          `json:"created_at"`
+	UpdatedAt      string          `json:"updated_at"`
+	TakeProfit     decimal.Decimal `json:"take_profit"`
+	StopLoss       decimal.Decimal `json:"stop_loss"`
+	TpTriggerBy    string          `json:"tp_trigger_by"`
+	SlTriggerBy    string          `json:"sl_trigger_by"`
+}
+
+// linear endpoints, converted with linearConditionalToConditionalOrder
+type ResponseForGetLinearConditionalOrders struct {
+	ApiResponse
+	Result LinearConditionalOrders `json:"result"`
+}
+
+type ResponseForQueryLinearConditionalOrder struct {
+	ApiResponse
+	Result *LinearConditionalOrder `json:"result"`
+}
+
+type LinearConditionalOrders struct {
+	CurrentPage             int                      `json:"current_page"`
+	LastPage                int                      `json:"last_page"`
+	LinearConditionalOrders []LinearConditionalOrder `json:"data"`
+}
+
+type LinearConditionalOrder struct {
+	StopOrderId    string          `json:"stop_order_id"`
+	UserId         int             `json:"user_id"`
+	Symbol         string          `json:"symbol"`
+	Side           string          `json:"side"`
+	OrderType      string          `json:"order_type"`
+	Price          decimal.Decimal `json:"price"`
+	Qty            decimal.Decimal `json:"qty"`
+	TimeInForce    string          `json:"time_in_force"`
+	OrderStatus    string          `json:"order_status"`
+	TriggerBy      string          `json:"trigger_by"`
+	BasePrice      decimal.Decimal `json:"base_price"`
+	TriggerPrice   decimal.Decimal `json:"trigger_price"`
+	ReduceOnly     bool            `json:"reduce_only"`
+	CloseOnTrigger bool            `json:"close_on_trigger"`
+	OrderLinkId    string          `json:"order_link_id"`
+	CreatedTime    string          `json:"created_time"`
+	UpdatedTime    string          `json:"updated_time"`
+	TakeProfit     decimal.Decimal `json:"take_profit"`
+	StopLoss       decimal.Decimal `json:"stop_loss"`
+	TpTriggerBy    string          `json:"tp_trigger_by"`
+	SlTriggerBy    string          `json:"sl_trigger_by"`
+}
+
+// Perp leverage, inverse perps take a single leverage so both sides must match
+type SetPerpLeverageParams struct {
+	Symbol       string
+	BuyLeverage  decimal.Decimal
+	SellLeverage decimal.Decimal // defaults to BuyLeverage
+}
+
+// Switch between isolated and cross margin, bybit sets the leverage in the same call
+type SetPerpMarginModeParams struct {
+	Symbol       string
+	IsIsolated   bool
+	BuyLeverage  decimal.Decimal
+	SellLeverage decimal.Decimal // defaults to BuyLeverage
+}
+
+// Margin moved in or out of an isolated position
+type ChangePerpMarginParams struct {
+	Symbol string
+	Side   string          // linear only, the side of the position, defaults to the open one, required when both hedge legs are open
+	Margin decimal.Decimal // positive adds margin, negative reduces it
+}
+
+// Take profit, stop loss and trailing stop of an open perp position. A nil
+// price leaves it unchanged, a zero one removes it.
+type TradingStopParams struct {
+	Symbol       string
+	Side         string           // linear only, the side of the position, defaults to the open one, required when both hedge legs are open
+	TakeProfit   *decimal.Decimal // price
+	StopLoss     *decimal.Decimal // price
+	TrailingStop *decimal.Decimal // distance from the best price
+	TpTriggerBy  string           // TRIGGER_BY_*, defaults to TRIGGER_BY_LAST_PRICE
+	SlTriggerBy  string           // TRIGGER_BY_*, defaults to TRIGGER_BY_LAST_PRICE
+	TpSize       decimal.Decimal  // TP_SL_MODE_PARTIAL only, the quantity the take profit closes
+	SlSize       decimal.Decimal  // TP_SL_MODE_PARTIAL only, the quantity the stop loss closes
+}
+
+// inverse replies with the updated position, linear with nothing
+type ResponseForSetTradingStop struct {
+	ApiResponse
+	Result PerpPosition `json:"result"`
+}
+
+// replies to position settings carry nothing we use
+type ResponseForPositionSetting struct {
+	ApiResponse
 }
 
 type ResponseForCancelSpotOrder struct {
@@ -358,6 +958,10 @@ type ResponseForWithdrawlFromSpotWallet struct {
 	RateLimit        int                           `json:"rate_limit"`
 }
 
+func (response ResponseForWithdrawlFromSpotWallet) apiResponse() ApiResponse {
+	return ApiResponse{RetCode: response.RetCode, RetMsg: response.RetMsg, ExtCode: response.ExtCode, ExtInfo: response.ExtInfo}
+}
+
 type WithdrawlFromSpotWalletResult struct {
 	WithdrawlId string `json:"id"`
 }
@@ -0,0 +1,174 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+/*
+	Gets the funding rate of the current interval and the predicted rate of the
+	next one.
+
+	Requires:
+		symbol string - e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+
+	Returns:
+		fundingRate FundingRate
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-latestsymbolinfo
+*/
+func (bybit *BybitExchange) GetPerpFundingRate(symbol string) (fundingRate FundingRate, err error) {
+	return bybit.GetPerpFundingRateCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpFundingRate, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetPerpFundingRateCtx(ctx context.Context, symbol string) (fundingRate FundingRate, err error) {
+	functionName := "GetPerpFundingRate"
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+
+	// create request
+	req := newPublicRequest(http.MethodGet, TICKER, params)
+
+	var response = new(ResponseForGetPerpMarketPrice)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return fundingRate, err
+	}
+	if len(response.Result) == 0 {
+		return fundingRate, fmt.Errorf("%v failed: no ticker for %v", functionName, symbol)
+	}
+
+	ticker := response.Result[0]
+	fundingRate.Symbol = ticker.Symbol
+	if fundingRate.FundingRate, err = decimal.NewFromString(ticker.FundingRate); err != nil {
+		return fundingRate, fmt.Errorf("%v failed: %v has no funding rate: %w", functionName, symbol, err)
+	}
+	fundingRate.PredictedFundingRate, _ = decimal.NewFromString(ticker.PredictedFundingRate)
+	fundingRate.NextFundingTime, _ = time.Parse(time.RFC3339, ticker.NextFundingTime)
+	return fundingRate, nil
+}
+
+/*
+	Lists the settled funding rates of a perp, newest first, to backfill them
+	over a time range. Served by the v5 market data, which both account types
+	can read.
+
+	Requires:
+		params PerpListParams - Symbol, StartTime, EndTime and Limit are used
+
+	Returns:
+		fundingRates *Iterator[HistoricalFundingRate]
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/history-fund-rate
+*/
+func (bybit *BybitExchange) GetPerpFundingRateHistory(params PerpListParams) (fundingRates *Iterator[HistoricalFundingRate]) {
+	return newIterator(bybit.fundingRatesPage("GetPerpFundingRateHistory", params))
+}
+
+/*
+	Lists the funding our positions paid or received, newest first. Fees are
+	positive when paid and negative when received, in the perp's settle coin.
+
+	Requires:
+		params PerpListParams - ExecType is ignored
+
+	Returns:
+		payments *Iterator[PerpExecution]
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-usertraderecords
+*/
+func (bybit *BybitExchange) GetPerpFundingPayments(params PerpListParams) (payments *Iterator[PerpExecution]) {
+	params.ExecType = EXEC_TYPE_FUNDING
+	return bybit.GetPerpExecutions(params)
+}
+
+/*
+	Annualises the funding a position earns at a funding rate, assuming the rate
+	and position value hold for a year of FUNDING_INTERVAL settlements.
+
+	Requires:
+		fundingRate decimal.Decimal - per interval, e.g. FundingRate.PredictedFundingRate
+
+	Returns:
+		carryRate decimal.Decimal - yearly return on the position value, negative when the position pays
+		carry decimal.Decimal - yearly funding in the settle coin, negative when the position pays
+*/
+func (position PerpPosition) AnnualisedFundingCarry(fundingRate decimal.Decimal) (carryRate, carry decimal.Decimal) {
+	intervalsPerYear := decimal.NewFromInt(int64(DAYS_PER_YEAR * 24 * time.Hour / FUNDING_INTERVAL))
+
+	// positive rates are paid by longs to shorts
+	switch position.Side {
+	case ORDER_SIDE_BUY:
+		carryRate = fundingRate.Neg().Mul(intervalsPerYear)
+	case ORDER_SIDE_SELL:
+		carryRate = fundingRate.Mul(intervalsPerYear)
+	default:
+		return decimal.Zero, decimal.Zero
+	}
+	return carryRate, carryRate.Mul(position.PositionValue)
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// fetches a page of funding rates settled before the cursor, a unix millis end time
+func (bybit *BybitExchange) fundingRatesPage(functionName string, listParams PerpListParams) func(ctx context.Context, cursor string) ([]HistoricalFundingRate, string, error) {
+	limit := listParams.Limit
+	if limit <= 0 || limit > FUNDING_HISTORY_PAGE_SIZE {
+		limit = FUNDING_HISTORY_PAGE_SIZE
+	}
+
+	return func(ctx context.Context, cursor string) (fundingRates []HistoricalFundingRate, nextCursor string, err error) {
+		isLinear, err := bybit.isLinear(ctx, listParams.Symbol)
+		if err != nil {
+			return fundingRates, nextCursor, err
+		}
+
+		params := map[string]interface{}{}
+		params["category"] = CATEGORY_INVERSE
+		if isLinear {
+			params["category"] = CATEGORY_LINEAR
+		}
+		params["symbol"] = listParams.Symbol
+		params["limit"] = limit
+		if !listParams.StartTime.IsZero() {
+			params["startTime"] = listParams.StartTime.UnixMilli()
+			// bybit rejects a start time without an end time
+			params["endTime"] = time.Now().UnixMilli()
+		}
+		if !listParams.EndTime.IsZero() {
+			params["endTime"] = listParams.EndTime.UnixMilli()
+		}
+		if cursor != "" {
+			params["endTime"] = cursor
+		}
+
+		req := newPublicRequest(http.MethodGet, V5_FUNDING_HISTORY, params)
+		var response = new(ResponseForV5FundingHistory)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return fundingRates, nextCursor, err
+		}
+
+		var oldest int64
+		for i, record := range response.Result.List {
+			fundingTime, _ := strconv.ParseInt(record.FundingRateTimestamp, 10, 64)
+			if i == 0 || fundingTime < oldest {
+				oldest = fundingTime
+			}
+			fundingRates = append(fundingRates, HistoricalFundingRate{
+				Symbol:      record.Symbol,
+				FundingRate: parseDecimal(record.FundingRate),
+				FundingTime: time.UnixMilli(fundingTime),
+			})
+		}
+		if len(response.Result.List) == limit && oldest > 0 {
+			nextCursor = strconv.FormatInt(oldest-1, 10)
+		}
+		return fundingRates, nextCursor, nil
+	}
+}
@@ -0,0 +1,288 @@
+package bybit_exchange
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Circuit breaker state of a host
+type CircuitState string
+
+const (
+	CIRCUIT_CLOSED    CircuitState = "closed"    // healthy, requests flow
+	CIRCUIT_OPEN      CircuitState = "open"      // failing, skipped until the cooldown ends
+	CIRCUIT_HALF_OPEN CircuitState = "half-open" // cooldown ended, the next request decides
+)
+
+// Snapshot of a host in the pool
+type HostStatus struct {
+	URL       string
+	State     CircuitState
+	Latency   time.Duration // smoothed round trip of the health probes, zero until probed
+	Failures  int           // consecutive failures
+	OpenUntil time.Time     // end of the cooldown while the circuit is open
+	LastProbe time.Time
+}
+
+// Routes requests to the healthiest, fastest of the configured hosts. Each host
+// has a circuit breaker opened by consecutive connection errors or 5xx, and is
+// latency probed through the server time endpoint. Safe for concurrent use.
+type HostPool struct {
+	probe    func(ctx context.Context, host string) (rtt time.Duration, err error)
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu     sync.RWMutex
+	hosts  []*hostState
+	active string
+	cancel context.CancelFunc
+}
+
+type hostState struct {
+	url       string
+	latency   time.Duration
+	failures  int
+	openUntil time.Time
+	lastProbe time.Time
+}
+
+func newHostPool(urls []string, probe func(ctx context.Context, host string) (time.Duration, error), interval time.Duration, logger *zap.Logger) *HostPool {
+	pool := &HostPool{
+		probe:    probe,
+		interval: interval,
+		logger:   logger,
+	}
+	for _, url := range urls {
+		pool.hosts = append(pool.hosts, &hostState{url: url})
+	}
+	if len(urls) > 0 {
+		pool.active = urls[0]
+	}
+	return pool
+}
+
+// Returns the host requests are currently routed to
+func (pool *HostPool) Active() string {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.active
+}
+
+// Returns the state of every host, in configured order
+func (pool *HostPool) Status() []HostStatus {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	now := time.Now()
+	statuses := make([]HostStatus, 0, len(pool.hosts))
+	for _, host := range pool.hosts {
+		statuses = append(statuses, HostStatus{
+			URL:       host.url,
+			State:     host.state(now),
+			Latency:   host.latency,
+			Failures:  host.failures,
+			OpenUntil: host.openUntil,
+			LastProbe: host.lastProbe,
+		})
+	}
+	return statuses
+}
+
+/*
+	Health checks every host once and reroutes to the best one.
+
+	Requires:
+		ctx context.Context
+*/
+func (pool *HostPool) Probe(ctx context.Context) {
+	pool.mu.RLock()
+	urls := make([]string, 0, len(pool.hosts))
+	for _, host := range pool.hosts {
+		urls = append(urls, host.url)
+	}
+	pool.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, url := range urls {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+
+			rtt, err := pool.probe(ctx, url)
+			if err != nil {
+				if ctx.Err() == nil {
+					pool.logger.Warn("bybit host probe failed", zap.String("host", url), zap.Error(err))
+					pool.reportFailure(url)
+				}
+				return
+			}
+			pool.reportProbe(url, rtt)
+		}(url)
+	}
+	wg.Wait()
+
+	pool.pick()
+}
+
+/*
+	Starts probing the hosts in the background every interval until ctx is done
+	or Stop is called. Calling Start on a running HostPool is a no-op.
+
+	Requires:
+		ctx context.Context
+*/
+func (pool *HostPool) Start(ctx context.Context) {
+	pool.mu.Lock()
+	if pool.cancel != nil {
+		pool.mu.Unlock()
+		return
+	}
+	ctx, pool.cancel = context.WithCancel(ctx)
+	pool.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(pool.interval)
+		defer ticker.Stop()
+
+		for {
+			pool.Probe(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stops the background probing started by Start
+func (pool *HostPool) Stop() {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.cancel != nil {
+		pool.cancel()
+		pool.cancel = nil
+	}
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// picks the host for the next attempt: healthy hosts first, then those
+// without recent failures, then the lowest latency. When every circuit is
+// open the one closest to its half-open retry is used.
+func (pool *HostPool) pick() string {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	// start from the active host so near ties don't flap between hosts
+	now := time.Now()
+	best := pool.host(pool.active)
+	for _, host := range pool.hosts {
+		if best == nil || host.isBetterThan(best, now) {
+			best = host
+		}
+	}
+	if best == nil {
+		return ""
+	}
+
+	if best.url != pool.active {
+		pool.logger.Warn("bybit switching host", zap.String("from", pool.active), zap.String("to", best.url))
+		pool.active = best.url
+	}
+	return best.url
+}
+
+// records the outcome of a request sent to host
+func (pool *HostPool) report(host string, isHostFailure bool) {
+	if isHostFailure {
+		pool.reportFailure(host)
+		return
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if state := pool.host(host); state != nil {
+		state.failures = 0
+		state.openUntil = time.Time{}
+	}
+}
+
+func (pool *HostPool) reportFailure(host string) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	state := pool.host(host)
+	if state == nil {
+		return
+	}
+	state.failures++
+	// a failed half-open trial opens the circuit again straight away
+	if state.failures >= HOST_FAILURE_THRESHOLD {
+		state.openUntil = time.Now().Add(HOST_OPEN_DURATION)
+	}
+}
+
+func (pool *HostPool) reportProbe(host string, rtt time.Duration) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	state := pool.host(host)
+	if state == nil {
+		return
+	}
+	if state.latency == 0 {
+		state.latency = rtt
+	} else {
+		state.latency = time.Duration(HOST_LATENCY_SMOOTHING*float64(rtt) + (1-HOST_LATENCY_SMOOTHING)*float64(state.latency))
+	}
+	state.lastProbe = time.Now()
+	state.failures = 0
+	state.openUntil = time.Time{}
+}
+
+// must be called with pool.mu held
+func (pool *HostPool) host(url string) *hostState {
+	for _, host := range pool.hosts {
+		if host.url == url {
+			return host
+		}
+	}
+	return nil
+}
+
+func (host *hostState) state(now time.Time) CircuitState {
+	switch {
+	case host.failures < HOST_FAILURE_THRESHOLD:
+		return CIRCUIT_CLOSED
+	case now.Before(host.openUntil):
+		return CIRCUIT_OPEN
+	default:
+		return CIRCUIT_HALF_OPEN
+	}
+}
+
+func (host *hostState) isBetterThan(other *hostState, now time.Time) bool {
+	hostOpen, otherOpen := host.state(now) == CIRCUIT_OPEN, other.state(now) == CIRCUIT_OPEN
+	if hostOpen != otherOpen {
+		return !hostOpen
+	}
+	if hostOpen {
+		return host.openUntil.Before(other.openUntil)
+	}
+	if (host.failures > 0) != (other.failures > 0) {
+		return host.failures == 0
+	}
+	// unprobed hosts don't displace the current pick
+	if host.latency == 0 || other.latency == 0 {
+		return false
+	}
+	return host.latency+HOST_LATENCY_TOLERANCE < other.latency
+}
@@ -0,0 +1,363 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Trading rules of a spot pair or derivatives contract
+type Instrument struct {
+	Symbol       string // exchange symbol, e.g. "BTCUSDT"
+	Alias        string
+	MarketType   string // exchange.MARKET_TYPE_SPOT or exchange.MARKET_TYPE_PERP
+	ContractType string // CONTRACT_TYPE_SPOT, CONTRACT_TYPE_LINEAR_PERPETUAL, ...
+	BaseCoin     string
+	QuoteCoin    string
+	Status       string // INSTRUMENT_STATUS_TRADING or INSTRUMENT_STATUS_CLOSED
+
+	TickSize decimal.Decimal
+	MinPrice decimal.Decimal
+	MaxPrice decimal.Decimal
+
+	QtyStep     decimal.Decimal
+	MinQty      decimal.Decimal
+	MaxQty      decimal.Decimal
+	MinNotional decimal.Decimal // zero when bybit doesn't enforce one
+	MaxNotional decimal.Decimal
+
+	MinLeverage  decimal.Decimal // zero for spot
+	MaxLeverage  decimal.Decimal
+	LeverageStep decimal.Decimal
+}
+
+// Returns whether new orders are accepted
+func (instrument Instrument) IsTrading() bool {
+	return instrument.Status == INSTRUMENT_STATUS_TRADING
+}
+
+// Cache of spot and derivatives instruments, loaded at most once at a time and
+// reloaded after the TTL. When a reload fails the previous instruments keep
+// being served. Safe for concurrent use.
+type InstrumentRegistry struct {
+	load   func(ctx context.Context) ([]Instrument, error)
+	ttl    time.Duration
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	bySymbol map[instrumentKey]Instrument
+	byPair   map[instrumentKey]Instrument
+	loadedAt time.Time
+	inflight *instrumentLoad
+	cancel   context.CancelFunc
+}
+
+// symbols are only unique within a market, spot and linear both list BTCUSDT
+type instrumentKey struct {
+	marketType string
+	name       string
+}
+
+// a load in progress that concurrent callers wait on instead of starting their own
+type instrumentLoad struct {
+	done chan struct{}
+	err  error
+}
+
+func newInstrumentRegistry(load func(ctx context.Context) ([]Instrument, error), ttl time.Duration, logger *zap.Logger) *InstrumentRegistry {
+	return &InstrumentRegistry{
+		load:   load,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+/*
+	Gets an instrument by exchange symbol.
+
+	Requires:
+		ctx context.Context
+		marketType string - exchange.MARKET_TYPE_SPOT or exchange.MARKET_TYPE_PERP
+		symbol string - e.g. "BTCUSDT"
+
+	Returns:
+		instrument Instrument
+		err error - wraps ErrInstrumentNotFound when bybit doesn't list the symbol
+*/
+func (registry *InstrumentRegistry) BySymbol(ctx context.Context, marketType, symbol string) (instrument Instrument, err error) {
+	if err = registry.ensureLoaded(ctx); err != nil {
+		return instrument, err
+	}
+
+	registry.mu.RLock()
+	instrument, ok := registry.bySymbol[instrumentKey{marketType, strings.ToUpper(symbol)}]
+	registry.mu.RUnlock()
+	if !ok {
+		return instrument, fmt.Errorf("no %v instrument %v: %w", marketType, symbol, ErrInstrumentNotFound)
+	}
+	return instrument, nil
+}
+
+/*
+	Gets an instrument by base and quote coin. For perps the perpetual contract
+	is returned rather than a dated future.
+
+	Requires:
+		ctx context.Context
+		baseCoin string - e.g. "BTC"
+		quoteCoin string - e.g. "USDT"
+		marketType string - exchange.MARKET_TYPE_SPOT or exchange.MARKET_TYPE_PERP
+
+	Returns:
+		instrument Instrument
+		err error - wraps ErrInstrumentNotFound when bybit doesn't list the pair
+*/
+func (registry *InstrumentRegistry) ByPair(ctx context.Context, baseCoin, quoteCoin, marketType string) (instrument Instrument, err error) {
+	if err = registry.ensureLoaded(ctx); err != nil {
+		return instrument, err
+	}
+
+	registry.mu.RLock()
+	instrument, ok := registry.byPair[instrumentKey{marketType, pairName(baseCoin, quoteCoin)}]
+	registry.mu.RUnlock()
+	if !ok {
+		return instrument, fmt.Errorf("no %v instrument for %v/%v: %w", marketType, baseCoin, quoteCoin, ErrInstrumentNotFound)
+	}
+	return instrument, nil
+}
+
+// Returns every cached instrument, loading them first if needed
+func (registry *InstrumentRegistry) All(ctx context.Context) (instruments []Instrument, err error) {
+	if err = registry.ensureLoaded(ctx); err != nil {
+		return instruments, err
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for _, instrument := range registry.bySymbol {
+		instruments = append(instruments, instrument)
+	}
+	return instruments, nil
+}
+
+// Reloads the instruments now, joining a reload already in progress. The
+// reload is shared, so it runs detached from ctx, which only bounds the wait.
+func (registry *InstrumentRegistry) Refresh(ctx context.Context) error {
+	registry.mu.Lock()
+	call := registry.inflight
+	if call == nil {
+		call = &instrumentLoad{done: make(chan struct{})}
+		registry.inflight = call
+		go registry.runLoad(call)
+	}
+	registry.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+/*
+	Starts reloading the instruments in the background every TTL until ctx is
+	done or Stop is called, so lookups never wait on a reload.
+	Calling Start on a running InstrumentRegistry is a no-op.
+
+	Requires:
+		ctx context.Context
+*/
+func (registry *InstrumentRegistry) Start(ctx context.Context) {
+	registry.mu.Lock()
+	if registry.cancel != nil {
+		registry.mu.Unlock()
+		return
+	}
+	ctx, registry.cancel = context.WithCancel(ctx)
+	registry.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(registry.ttl)
+		defer ticker.Stop()
+
+		for {
+			if err := registry.Refresh(ctx); err != nil && ctx.Err() == nil {
+				registry.logger.Warn("bybit instrument refresh failed", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stops the background reload started by Start
+func (registry *InstrumentRegistry) Stop() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.cancel != nil {
+		registry.cancel()
+		registry.cancel = nil
+	}
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+func (registry *InstrumentRegistry) ensureLoaded(ctx context.Context) error {
+	registry.mu.RLock()
+	isLoaded := !registry.loadedAt.IsZero()
+	isFresh := isLoaded && time.Since(registry.loadedAt) < registry.ttl
+	registry.mu.RUnlock()
+
+	if isFresh {
+		return nil
+	}
+
+	err := registry.Refresh(ctx)
+	if err != nil && isLoaded {
+		// stale instruments are still better than failing the lookup
+		registry.logger.Warn("bybit instrument refresh failed, using cached instruments", zap.Error(err))
+		return nil
+	}
+	return err
+}
+
+// loads for every caller waiting on call, so no one caller's cancellation or deadline fails the others
+func (registry *InstrumentRegistry) runLoad(call *instrumentLoad) {
+	ctx, cancel := context.WithTimeout(context.Background(), INSTRUMENT_LOAD_TIMEOUT)
+	defer cancel()
+
+	instruments, err := registry.load(ctx)
+
+	registry.mu.Lock()
+	if err == nil {
+		registry.index(instruments)
+	}
+	registry.inflight = nil
+	call.err = err
+	registry.mu.Unlock()
+	close(call.done)
+}
+
+// must be called with registry.mu held
+func (registry *InstrumentRegistry) index(instruments []Instrument) {
+	registry.bySymbol = make(map[instrumentKey]Instrument, len(instruments))
+	registry.byPair = make(map[instrumentKey]Instrument, len(instruments))
+
+	for _, instrument := range instruments {
+		registry.bySymbol[instrumentKey{instrument.MarketType, instrument.Symbol}] = instrument
+
+		pairKey := instrumentKey{instrument.MarketType, pairName(instrument.BaseCoin, instrument.QuoteCoin)}
+		if existing, ok := registry.byPair[pairKey]; ok && !isFutures(existing.ContractType) {
+			continue
+		}
+		registry.byPair[pairKey] = instrument
+	}
+	registry.loadedAt = time.Now()
+}
+
+// loads spot and derivatives symbols
+func (bybit *BybitExchange) loadInstruments(ctx context.Context) (instruments []Instrument, err error) {
+	spotSymbols, err := bybit.GetSpotSymbolsCtx(ctx)
+	if err != nil {
+		return instruments, err
+	}
+	perpSymbols, err := bybit.GetPerpSymbolsCtx(ctx)
+	if err != nil {
+		return instruments, err
+	}
+
+	for _, spotSymbol := range spotSymbols {
+		instruments = append(instruments, spotSymbolToInstrument(spotSymbol))
+	}
+	for _, perpSymbol := range perpSymbols {
+		// USDC perps trade on their own endpoints, which this client doesn't call
+		if perpSymbol.QuoteCurrency == "USDC" {
+			continue
+		}
+		instruments = append(instruments, perpSymbolToInstrument(perpSymbol))
+	}
+	return instruments, nil
+}
+
+func spotSymbolToInstrument(spotSymbol SpotSymbolInfo) Instrument {
+	status := INSTRUMENT_STATUS_CLOSED
+	if spotSymbol.ShowStatus {
+		status = INSTRUMENT_STATUS_TRADING
+	}
+
+	return Instrument{
+		Symbol:       spotSymbol.Name,
+		Alias:        spotSymbol.Alias,
+		MarketType:   exchange.MARKET_TYPE_SPOT,
+		ContractType: CONTRACT_TYPE_SPOT,
+		BaseCoin:     spotSymbol.BaseCurrency,
+		QuoteCoin:    spotSymbol.QuoteCurrency,
+		Status:       status,
+		TickSize:     spotSymbol.MinPricePrecision,
+		QtyStep:      spotSymbol.BasePrecision,
+		MinQty:       spotSymbol.MinTradeQuantity,
+		MaxQty:       spotSymbol.MaxTradeQuantity,
+		MinNotional:  spotSymbol.MinTradeAmount,
+		MaxNotional:  spotSymbol.MaxTradeAmount,
+	}
+}
+
+func perpSymbolToInstrument(perpSymbol PerpSymbolInfo) Instrument {
+	contractType := CONTRACT_TYPE_INVERSE_PERPETUAL
+	switch {
+	case perpSymbol.QuoteCurrency == "USDT":
+		contractType = CONTRACT_TYPE_LINEAR_PERPETUAL
+	case perpSymbol.Name != perpSymbol.BaseCurrency+perpSymbol.QuoteCurrency:
+		// dated futures carry the delivery month, e.g. BTCUSDZ22
+		contractType = CONTRACT_TYPE_INVERSE_FUTURES
+	}
+
+	status := INSTRUMENT_STATUS_CLOSED
+	if perpSymbol.Status == INSTRUMENT_STATUS_TRADING {
+		status = INSTRUMENT_STATUS_TRADING
+	}
+
+	minPrice, _ := decimal.NewFromString(perpSymbol.PriceFilter.MinPrice)
+	maxPrice, _ := decimal.NewFromString(perpSymbol.PriceFilter.MaxPrice)
+	tickSize, _ := decimal.NewFromString(perpSymbol.PriceFilter.TickSize)
+
+	return Instrument{
+		Symbol:       perpSymbol.Name,
+		Alias:        perpSymbol.Alias,
+		MarketType:   exchange.MARKET_TYPE_PERP,
+		ContractType: contractType,
+		BaseCoin:     perpSymbol.BaseCurrency,
+		QuoteCoin:    perpSymbol.QuoteCurrency,
+		Status:       status,
+		TickSize:     tickSize,
+		MinPrice:     minPrice,
+		MaxPrice:     maxPrice,
+		QtyStep:      perpSymbol.LotSizeFilter.QtyStep,
+		MinQty:       perpSymbol.LotSizeFilter.MinTradingQty,
+		MaxQty:       perpSymbol.LotSizeFilter.MaxTradingQty,
+		MinLeverage:  perpSymbol.LeverageFilter.MinLeverage,
+		MaxLeverage:  perpSymbol.LeverageFilter.MaxLeverage,
+		LeverageStep: perpSymbol.LeverageFilter.LeverageStep,
+	}
+}
+
+func isFutures(contractType string) bool {
+	return contractType == CONTRACT_TYPE_INVERSE_FUTURES || contractType == CONTRACT_TYPE_LINEAR_FUTURES
+}
+
+func pairName(baseCoin, quoteCoin string) string {
+	return strings.ToUpper(baseCoin) + "/" + strings.ToUpper(quoteCoin)
+}
@@ -0,0 +1,293 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
+)
+
+// Kline interval (interval)
+type KlineInterval string
+
+const (
+	KLINE_INTERVAL_1MIN   KlineInterval = "1"
+	KLINE_INTERVAL_3MIN   KlineInterval = "3"
+	KLINE_INTERVAL_5MIN   KlineInterval = "5"
+	KLINE_INTERVAL_15MIN  KlineInterval = "15"
+	KLINE_INTERVAL_30MIN  KlineInterval = "30"
+	KLINE_INTERVAL_1HOUR  KlineInterval = "60"
+	KLINE_INTERVAL_2HOUR  KlineInterval = "120"
+	KLINE_INTERVAL_4HOUR  KlineInterval = "240"
+	KLINE_INTERVAL_6HOUR  KlineInterval = "360"
+	KLINE_INTERVAL_12HOUR KlineInterval = "720"
+	KLINE_INTERVAL_1DAY   KlineInterval = "D"
+	KLINE_INTERVAL_1WEEK  KlineInterval = "W"
+	KLINE_INTERVAL_1MONTH KlineInterval = "M"
+)
+
+// What a kline is built from
+type KlineType string
+
+const (
+	KLINE_TYPE_TRADE         KlineType = "trade"
+	KLINE_TYPE_MARK_PRICE    KlineType = "mark_price"
+	KLINE_TYPE_INDEX_PRICE   KlineType = "index_price"
+	KLINE_TYPE_PREMIUM_INDEX KlineType = "premium_index"
+)
+
+var klineEndpoints = map[KlineType]string{
+	KLINE_TYPE_TRADE:         V5_KLINE,
+	KLINE_TYPE_MARK_PRICE:    V5_MARK_PRICE_KLINE,
+	KLINE_TYPE_INDEX_PRICE:   V5_INDEX_KLINE,
+	KLINE_TYPE_PREMIUM_INDEX: V5_PREMIUM_KLINE,
+}
+
+/*
+	Gets up to a page of klines, oldest first. Without a time range the latest
+	klines are returned.
+
+	Requires:
+		params KlineParams
+
+	Returns:
+		klines []Kline
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/kline
+	Ref: https://bybit-exchange.github.io/docs/v5/market/mark-kline
+	Ref: https://bybit-exchange.github.io/docs/v5/market/index-kline
+	Ref: https://bybit-exchange.github.io/docs/v5/market/premium-index-kline
+*/
+func (bybit *BybitExchange) GetKlines(params KlineParams) (klines []Kline, err error) {
+	return bybit.GetKlinesCtx(context.Background(), params)
+}
+
+// Same as GetKlines, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetKlinesCtx(ctx context.Context, params KlineParams) (klines []Kline, err error) {
+	functionName := "GetKlines"
+	category, err := bybit.klineCategory(ctx, functionName, params)
+	if err != nil {
+		return klines, err
+	}
+
+	limit := params.Limit
+	if limit <= 0 || limit > KLINES_PAGE_SIZE {
+		limit = KLINES_PAGE_SIZE
+	}
+	return bybit.klinesPage(ctx, functionName, category, params, params.StartTime, params.EndTime, limit)
+}
+
+/*
+	Backfills the klines starting within [StartTime, EndTime), oldest first.
+	The range is split into pages fetched KLINE_FETCH_CONCURRENCY at a time,
+	and candles repeated on page boundaries are only returned once. Intervals
+	bybit has no kline for, e.g. before the symbol was listed, are left out.
+
+	Requires:
+		params KlineParams - Limit is ignored
+
+	Returns:
+		klines []Kline
+		err error - the first page that failed, the other pages are cancelled
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/kline
+*/
+func (bybit *BybitExchange) GetKlineRange(params KlineParams) (klines []Kline, err error) {
+	return bybit.GetKlineRangeCtx(context.Background(), params)
+}
+
+// Same as GetKlineRange, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetKlineRangeCtx(ctx context.Context, params KlineParams) (klines []Kline, err error) {
+	functionName := "GetKlineRange"
+	if params.StartTime.IsZero() {
+		return klines, fmt.Errorf("%v failed: no start time", functionName)
+	}
+	endTime := params.EndTime
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	category, err := bybit.klineCategory(ctx, functionName, params)
+	if err != nil {
+		return klines, err
+	}
+
+	// windows are a candle short of a page, so a boundary candle bybit adds can't push out the oldest one
+	type window struct{ start, end time.Time }
+	var windows []window
+	for start := params.StartTime; start.Before(endTime); {
+		end := params.Interval.add(start, KLINES_PAGE_SIZE-1)
+		if end.After(endTime) {
+			end = endTime
+		}
+		windows = append(windows, window{start, end})
+		start = end
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make([][]Kline, len(windows))
+	slots := make(chan struct{}, KLINE_FETCH_CONCURRENCY)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	for i, w := range windows {
+		wg.Add(1)
+		go func(i int, w window) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+			page, pageErr := bybit.klinesPage(ctx, functionName, category, params, w.start, w.end, KLINES_PAGE_SIZE)
+			if pageErr != nil {
+				errOnce.Do(func() {
+					err = pageErr
+					cancel()
+				})
+				return
+			}
+			pages[i] = page
+		}(i, w)
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	seen := map[int64]bool{}
+	for _, page := range pages {
+		for _, kline := range page {
+			startTime := kline.StartTime.UnixMilli()
+			if seen[startTime] || kline.StartTime.Before(params.StartTime) || !kline.StartTime.Before(endTime) {
+				continue
+			}
+			seen[startTime] = true
+			klines = append(klines, kline)
+		}
+	}
+	sort.Slice(klines, func(i, j int) bool {
+		return klines[i].StartTime.Before(klines[j].StartTime)
+	})
+	return klines, nil
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+func (interval KlineInterval) isValid() bool {
+	switch interval {
+	case KLINE_INTERVAL_1MIN, KLINE_INTERVAL_3MIN, KLINE_INTERVAL_5MIN, KLINE_INTERVAL_15MIN, KLINE_INTERVAL_30MIN,
+		KLINE_INTERVAL_1HOUR, KLINE_INTERVAL_2HOUR, KLINE_INTERVAL_4HOUR, KLINE_INTERVAL_6HOUR, KLINE_INTERVAL_12HOUR,
+		KLINE_INTERVAL_1DAY, KLINE_INTERVAL_1WEEK, KLINE_INTERVAL_1MONTH:
+		return true
+	}
+	return false
+}
+
+// moves t n intervals ahead, months by the calendar
+func (interval KlineInterval) add(t time.Time, n int) time.Time {
+	switch interval {
+	case KLINE_INTERVAL_1DAY:
+		return t.AddDate(0, 0, n)
+	case KLINE_INTERVAL_1WEEK:
+		return t.AddDate(0, 0, 7*n)
+	case KLINE_INTERVAL_1MONTH:
+		return t.AddDate(0, n, 0)
+	}
+	minutes, _ := strconv.Atoi(string(interval))
+	return t.Add(time.Duration(n*minutes) * time.Minute)
+}
+
+// checks the params and returns the v5 category of the symbol
+func (bybit *BybitExchange) klineCategory(ctx context.Context, functionName string, params KlineParams) (category string, err error) {
+	if !params.Interval.isValid() {
+		return category, fmt.Errorf("%v failed: invalid interval %q", functionName, params.Interval)
+	}
+	if _, ok := klineEndpoints[params.Type]; !ok && params.Type != "" {
+		return category, fmt.Errorf("%v failed: invalid kline type %q", functionName, params.Type)
+	}
+
+	switch params.MarketType {
+	case exchange.MARKET_TYPE_SPOT:
+		if params.Type != "" && params.Type != KLINE_TYPE_TRADE {
+			return category, fmt.Errorf("%v failed: no %v klines for spot", functionName, params.Type)
+		}
+		return CATEGORY_SPOT, nil
+	case exchange.MARKET_TYPE_PERP:
+		isLinear, err := bybit.isLinear(ctx, params.Symbol)
+		if err != nil {
+			return category, err
+		}
+		if isLinear {
+			return CATEGORY_LINEAR, nil
+		}
+		return CATEGORY_INVERSE, nil
+	}
+	return category, fmt.Errorf("%v failed: invalid market type %q", functionName, params.MarketType)
+}
+
+// fetches the klines starting within [startTime, endTime), oldest first, zero times are left to bybit
+func (bybit *BybitExchange) klinesPage(ctx context.Context, functionName, category string, klineParams KlineParams, startTime, endTime time.Time, limit int) (klines []Kline, err error) {
+	path := V5_KLINE
+	if klineParams.Type != "" {
+		path = klineEndpoints[klineParams.Type]
+	}
+
+	params := map[string]interface{}{}
+	params["category"] = category
+	params["symbol"] = klineParams.Symbol
+	params["interval"] = string(klineParams.Interval)
+	params["limit"] = limit
+	if !startTime.IsZero() {
+		params["start"] = startTime.UnixMilli()
+	}
+	if !endTime.IsZero() {
+		// bybit's end is inclusive
+		params["end"] = endTime.UnixMilli() - 1
+	}
+
+	req := newPublicRequest(http.MethodGet, path, params)
+	var response = new(ResponseForV5Klines)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return klines, err
+	}
+
+	rows := response.Result.List
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		if len(row) < 5 {
+			return nil, fmt.Errorf("%v failed: malformed kline %v", functionName, row)
+		}
+		startMillis, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v failed: malformed kline start time %q: %w", functionName, row[0], err)
+		}
+
+		kline := Kline{
+			Symbol:    klineParams.Symbol,
+			Interval:  klineParams.Interval,
+			StartTime: time.UnixMilli(startMillis),
+			Open:      parseDecimal(row[1]),
+			High:      parseDecimal(row[2]),
+			Low:       parseDecimal(row[3]),
+			Close:     parseDecimal(row[4]),
+		}
+		if len(row) >= 7 {
+			kline.Volume = parseDecimal(row[5])
+			kline.Turnover = parseDecimal(row[6])
+		}
+		klines = append(klines, kline)
+	}
+	return klines, nil
+}
@@ -0,0 +1,202 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
+)
+
+/*
+	Gets the latest public trades, newest first. Bybit only serves the most
+	recent trades, the time range filters within them.
+
+	Requires:
+		params RecentTradesParams
+
+	Returns:
+		trades []exchange.Trade - sizes in the base coin, sides are the taker's
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/recent-trade
+*/
+func (bybit *BybitExchange) GetRecentTrades(params RecentTradesParams) (trades []exchange.Trade, err error) {
+	return bybit.GetRecentTradesCtx(context.Background(), params)
+}
+
+// Same as GetRecentTrades, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetRecentTradesCtx(ctx context.Context, params RecentTradesParams) (trades []exchange.Trade, err error) {
+	functionName := "GetRecentTrades"
+
+	var category string
+	maxLimit := PERP_RECENT_TRADES_LIMIT
+	switch params.MarketType {
+	case exchange.MARKET_TYPE_SPOT:
+		category = CATEGORY_SPOT
+		maxLimit = SPOT_RECENT_TRADES_LIMIT
+	case exchange.MARKET_TYPE_PERP:
+		isLinear, err := bybit.isLinear(ctx, params.Symbol)
+		if err != nil {
+			return trades, err
+		}
+		category = CATEGORY_INVERSE
+		if isLinear {
+			category = CATEGORY_LINEAR
+		}
+	default:
+		return trades, fmt.Errorf("%v failed: invalid market type %q", functionName, params.MarketType)
+	}
+
+	limit := params.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	requestParams := map[string]interface{}{}
+	requestParams["category"] = category
+	requestParams["symbol"] = params.Symbol
+	requestParams["limit"] = limit
+
+	// create request
+	req := newPublicRequest(http.MethodGet, V5_RECENT_TRADES, requestParams)
+
+	var response = new(ResponseForV5RecentTrades)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return trades, err
+	}
+
+	for _, recentTrade := range response.Result.List {
+		trade := recentTradeToTrade(category, recentTrade)
+		if !isInTimeRange(trade.Time, params.StartTime, params.EndTime) {
+			continue
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+/*
+	Lists the open interest history of a perp, newest first.
+
+	Requires:
+		params MarketStatsParams
+
+	Returns:
+		openInterests *Iterator[OpenInterest]
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/open-interest
+*/
+func (bybit *BybitExchange) GetOpenInterest(params MarketStatsParams) (openInterests *Iterator[OpenInterest]) {
+	functionName := "GetOpenInterest"
+	return newIterator(func(ctx context.Context, cursor string) (openInterests []OpenInterest, nextCursor string, err error) {
+		requestParams, err := bybit.marketStatsParams(ctx, params, OPEN_INTEREST_PAGE_SIZE, cursor)
+		if err != nil {
+			return openInterests, nextCursor, err
+		}
+		requestParams["intervalTime"] = params.Period
+
+		req := newPublicRequest(http.MethodGet, V5_OPEN_INTEREST, requestParams)
+		var response = new(ResponseForV5OpenInterest)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return openInterests, nextCursor, err
+		}
+
+		for _, record := range response.Result.List {
+			openInterests = append(openInterests, OpenInterest{
+				Symbol:       params.Symbol,
+				OpenInterest: parseDecimal(record.OpenInterest),
+				Time:         parseMillis(record.Timestamp),
+			})
+		}
+		return openInterests, response.Result.NextPageCursor, nil
+	})
+}
+
+/*
+	Lists the long/short account ratio history of a perp, newest first.
+
+	Requires:
+		params MarketStatsParams
+
+	Returns:
+		ratios *Iterator[LongShortRatio]
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/long-short-ratio
+*/
+func (bybit *BybitExchange) GetLongShortRatio(params MarketStatsParams) (ratios *Iterator[LongShortRatio]) {
+	functionName := "GetLongShortRatio"
+	return newIterator(func(ctx context.Context, cursor string) (ratios []LongShortRatio, nextCursor string, err error) {
+		requestParams, err := bybit.marketStatsParams(ctx, params, LONG_SHORT_RATIO_PAGE_SIZE, cursor)
+		if err != nil {
+			return ratios, nextCursor, err
+		}
+		requestParams["period"] = params.Period
+
+		req := newPublicRequest(http.MethodGet, V5_ACCOUNT_RATIO, requestParams)
+		var response = new(ResponseForV5AccountRatio)
+		if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return ratios, nextCursor, err
+		}
+
+		for _, record := range response.Result.List {
+			ratios = append(ratios, LongShortRatio{
+				Symbol:    record.Symbol,
+				BuyRatio:  parseDecimal(record.BuyRatio),
+				SellRatio: parseDecimal(record.SellRatio),
+				Time:      parseMillis(record.Timestamp),
+			})
+		}
+		return ratios, response.Result.NextPageCursor, nil
+	})
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// request params shared by the open interest and long/short ratio pages
+func (bybit *BybitExchange) marketStatsParams(ctx context.Context, listParams MarketStatsParams, maxLimit int, cursor string) (params map[string]interface{}, err error) {
+	isLinear, err := bybit.isLinear(ctx, listParams.Symbol)
+	if err != nil {
+		return params, err
+	}
+
+	limit := listParams.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	params = map[string]interface{}{}
+	params["category"] = CATEGORY_INVERSE
+	if isLinear {
+		params["category"] = CATEGORY_LINEAR
+	}
+	params["symbol"] = listParams.Symbol
+	params["limit"] = limit
+	if !listParams.StartTime.IsZero() {
+		params["startTime"] = listParams.StartTime.UnixMilli()
+	}
+	if !listParams.EndTime.IsZero() {
+		params["endTime"] = listParams.EndTime.UnixMilli()
+	}
+	if cursor != "" {
+		params["cursor"] = cursor
+	}
+	return params, nil
+}
+
+func recentTradeToTrade(category string, recentTrade V5RecentTrade) exchange.Trade {
+	price := parseFloat(recentTrade.Price)
+	size := parseFloat(recentTrade.Size)
+	if category == CATEGORY_INVERSE && price > 0 {
+		size /= price
+	}
+
+	return exchange.Trade{
+		ID:    recentTrade.ExecId,
+		Price: price,
+		Side:  strings.ToLower(recentTrade.Side),
+		Size:  size,
+		Time:  parseMillis(recentTrade.Time),
+	}
+}
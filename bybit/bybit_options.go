@@ -0,0 +1,148 @@
+package bybit_exchange
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Bybit environment a client connects to
+type Environment string
+
+// Configures a bybit exchange client on construction
+type Option func(*BybitExchange)
+
+// Selects testnet or mainnet. Mainnet fails over between api.bybit.com and
+// api.bytick.com, ENV_BYTICK prefers the latter.
+// Unknown environments leave the current hosts untouched.
+func WithEnvironment(env Environment) Option {
+	return func(bybit *BybitExchange) {
+		switch env {
+		case ENV_TESTNET:
+			bybit.hostURLs = []string{TESTNET_URL}
+		case ENV_MAINNET:
+			bybit.hostURLs = []string{MAINNET_URL, MAINNET_URL_2}
+		case ENV_BYTICK:
+			bybit.hostURLs = []string{MAINNET_URL_2, MAINNET_URL}
+		default:
+			bybit.logger.Warn("unknown bybit environment, keeping hosts", zap.String("environment", string(env)), zap.Strings("hosts", bybit.hostURLs))
+		}
+	}
+}
+
+// Overrides the base url, e.g. to point at a proxy or a mock server.
+// Disables failover, use WithHosts to fail over between several urls.
+func WithBaseURL(baseURL string) Option {
+	return func(bybit *BybitExchange) {
+		bybit.hostURLs = []string{baseURL}
+	}
+}
+
+// Routes requests across several base urls, preferring them in the given order
+// until latency probes say otherwise.
+func WithHosts(hostURLs ...string) Option {
+	return func(bybit *BybitExchange) {
+		if len(hostURLs) > 0 {
+			bybit.hostURLs = hostURLs
+		}
+	}
+}
+
+// Sets how often HostPool.Start health checks the hosts.
+func WithHostProbeInterval(interval time.Duration) Option {
+	return func(bybit *BybitExchange) {
+		if interval > 0 {
+			bybit.hostProbeInterval = interval
+		}
+	}
+}
+
+// Uses a custom http client, e.g. with its own transport or timeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(bybit *BybitExchange) {
+		if client != nil {
+			bybit.Client = client
+		}
+	}
+}
+
+// Sets the recv_window (in milliseconds) sent with every signed request.
+func WithRecvWindow(recvWindow int) Option {
+	return func(bybit *BybitExchange) {
+		if recvWindow > 0 {
+			bybit.recvWindow = recvWindow
+		}
+	}
+}
+
+// Sets the timeout applied to requests whose context carries no deadline,
+// zero disables it.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(bybit *BybitExchange) {
+		if timeout >= 0 {
+			bybit.requestTimeout = timeout
+		}
+	}
+}
+
+// Sets how often the clock offset to bybit server time is refreshed.
+func WithTimeSyncInterval(interval time.Duration) Option {
+	return func(bybit *BybitExchange) {
+		if interval > 0 {
+			bybit.timeSyncInterval = interval
+		}
+	}
+}
+
+// Uses a custom logger instead of the global pingcap logger.
+func WithLogger(logger *zap.Logger) Option {
+	return func(bybit *BybitExchange) {
+		if logger != nil {
+			bybit.logger = logger
+		}
+	}
+}
+
+// Sets whether requests wait for rate limit tokens (RATE_LIMIT_WAIT, default)
+// or fail with ErrRateLimited (RATE_LIMIT_FAIL_FAST).
+func WithRateLimitMode(mode RateLimitMode) Option {
+	return func(bybit *BybitExchange) {
+		bybit.rateLimitMode = mode
+	}
+}
+
+// Sets how failed requests are retried, MaxAttempts of 1 disables retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(bybit *BybitExchange) {
+		if policy.MaxAttempts < 1 {
+			policy.MaxAttempts = 1
+		}
+		bybit.retryPolicy = policy
+	}
+}
+
+// Sets how long cached instruments are served before they're reloaded.
+func WithInstrumentTTL(ttl time.Duration) Option {
+	return func(bybit *BybitExchange) {
+		if ttl > 0 {
+			bybit.instrumentTTL = ttl
+		}
+	}
+}
+
+// Checks orders against the instrument filters before PlaceSpotOrder and
+// PlacePerpOrder send them, rounding prices and quantities as configured.
+func WithOrderValidation(validation OrderValidation) Option {
+	return func(bybit *BybitExchange) {
+		bybit.orderValidation = &validation
+	}
+}
+
+// Queries the unified trading account instead of the classic spot and contract
+// wallets. Only used by BybitV5Exchange.
+func WithUnifiedAccount() Option {
+	return func(bybit *BybitExchange) {
+		bybit.unifiedAccount = true
+	}
+}
@@ -0,0 +1,151 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
+)
+
+/*
+	Gets an L2 order book snapshot, bids highest first and asks lowest first.
+	Snapshots carry bybit's update id, so a local book can be seeded from one
+	and patched with the websocket deltas that follow it. Merged spot books
+	come from the merged depth endpoint, which doesn't number its snapshots.
+
+	Requires:
+		params OrderbookParams
+
+	Returns:
+		orderbook exchange.Orderbook
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/orderbook
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-mergedorderbook
+*/
+func (bybit *BybitExchange) GetOrderbook(params OrderbookParams) (orderbook exchange.Orderbook, err error) {
+	return bybit.GetOrderbookCtx(context.Background(), params)
+}
+
+// Same as GetOrderbook, cancelled or timed out through ctx
+func (bybit *BybitExchange) GetOrderbookCtx(ctx context.Context, params OrderbookParams) (orderbook exchange.Orderbook, err error) {
+	functionName := "GetOrderbook"
+
+	depth := params.Depth
+	if depth <= 0 {
+		depth = DEFAULT_ORDERBOOK_DEPTH
+	}
+
+	var category string
+	switch params.MarketType {
+	case exchange.MARKET_TYPE_SPOT:
+		if depth > SPOT_ORDERBOOK_MAX_DEPTH {
+			depth = SPOT_ORDERBOOK_MAX_DEPTH
+		}
+		if params.Merged {
+			return bybit.getSpotMergedDepth(ctx, functionName, params.Symbol, depth, params.Scale)
+		}
+		category = CATEGORY_SPOT
+	case exchange.MARKET_TYPE_PERP:
+		if params.Merged {
+			return orderbook, fmt.Errorf("%v failed: merged depth is spot only", functionName)
+		}
+		if depth > PERP_ORDERBOOK_MAX_DEPTH {
+			depth = PERP_ORDERBOOK_MAX_DEPTH
+		}
+		isLinear, err := bybit.isLinear(ctx, params.Symbol)
+		if err != nil {
+			return orderbook, err
+		}
+		category = CATEGORY_INVERSE
+		if isLinear {
+			category = CATEGORY_LINEAR
+		}
+	default:
+		return orderbook, fmt.Errorf("%v failed: invalid market type %q", functionName, params.MarketType)
+	}
+
+	requestParams := map[string]interface{}{}
+	requestParams["category"] = category
+	requestParams["symbol"] = params.Symbol
+	requestParams["limit"] = depth
+
+	// create request
+	req := newPublicRequest(http.MethodGet, V5_ORDERBOOK, requestParams)
+
+	var response = new(ResponseForV5Orderbook)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return orderbook, err
+	}
+
+	if orderbook, err = toOrderbook(response.Result.Bids, response.Result.Asks); err != nil {
+		return orderbook, fmt.Errorf("%v failed: %w", functionName, err)
+	}
+	orderbook.Time = time.UnixMilli(response.Result.Time)
+	orderbook.UpdateId = response.Result.UpdateId
+	return orderbook, nil
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+func (bybit *BybitExchange) getSpotMergedDepth(ctx context.Context, functionName, symbol string, depth, scale int) (orderbook exchange.Orderbook, err error) {
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+	params["limit"] = depth
+	params["scale"] = scale
+
+	// create request
+	req := newPublicRequest(http.MethodGet, SPOT_MERGED_DEPTH, params)
+
+	var response = new(ResponseForGetSpotMergedDepth)
+	if err = bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return orderbook, err
+	}
+
+	if orderbook, err = toOrderbook(response.Result.Bids, response.Result.Asks); err != nil {
+		return orderbook, fmt.Errorf("%v failed: %w", functionName, err)
+	}
+	orderbook.Time = time.UnixMilli(response.Result.Time)
+	return orderbook, nil
+}
+
+// parses [price, size] levels, sorting bids highest first and asks lowest first
+func toOrderbook(bids, asks [][]string) (orderbook exchange.Orderbook, err error) {
+	if orderbook.Bids, err = parseLevels(bids); err != nil {
+		return orderbook, err
+	}
+	if orderbook.Asks, err = parseLevels(asks); err != nil {
+		return orderbook, err
+	}
+
+	sort.Slice(orderbook.Bids, func(i, j int) bool {
+		return orderbook.Bids[i][0] > orderbook.Bids[j][0]
+	})
+	sort.Slice(orderbook.Asks, func(i, j int) bool {
+		return orderbook.Asks[i][0] < orderbook.Asks[j][0]
+	})
+	return orderbook, nil
+}
+
+func parseLevels(rawLevels [][]string) (levels [][]float64, err error) {
+	levels = make([][]float64, 0, len(rawLevels))
+	for _, rawLevel := range rawLevels {
+		if len(rawLevel) < 2 {
+			return levels, fmt.Errorf("malformed order book level %v", rawLevel)
+		}
+		price, err := strconv.ParseFloat(rawLevel[0], 64)
+		if err != nil {
+			return levels, fmt.Errorf("malformed order book price %q: %w", rawLevel[0], err)
+		}
+		size, err := strconv.ParseFloat(rawLevel[1], 64)
+		if err != nil {
+			return levels, fmt.Errorf("malformed order book size %q: %w", rawLevel[1], err)
+		}
+		levels = append(levels, []float64{price, size})
+	}
+	return levels, nil
+}
@@ -0,0 +1,64 @@
+package bybit_exchange
+
+import "context"
+
+// Iterator over a paginated bybit listing. Pages are fetched lazily, the next
+// one only once the current one is used up. Not safe for concurrent use.
+//
+//	orders := bybit.GetSpotOrderHistory(SpotListParams{Symbol: "BTCUSDT"})
+//	for orders.Next(ctx) {
+//		order := orders.Item()
+//	}
+//	if err := orders.Err(); err != nil {
+//		return err
+//	}
+type Iterator[T any] struct {
+	fetch  func(ctx context.Context, cursor string) (page []T, nextCursor string, err error)
+	page   []T
+	cursor string
+	item   T
+	isLast bool
+	err    error
+}
+
+// fetch returns a page and the cursor of the next one, "" when it was the last page
+func newIterator[T any](fetch func(ctx context.Context, cursor string) ([]T, string, error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch}
+}
+
+// Advances to the next item, fetching the next page when needed. Returns false
+// once every item was read or a fetch failed, check Err to tell them apart.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.isLast || it.err != nil {
+			return false
+		}
+		// pages may come back empty after client side filtering, keep going until the cursor runs out
+		it.page, it.cursor, it.err = it.fetch(ctx, it.cursor)
+		if it.err != nil {
+			return false
+		}
+		it.isLast = it.cursor == ""
+	}
+
+	it.item, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Returns the item Next advanced to
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Returns the error that stopped the iteration, nil when every item was read
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Reads every remaining item
+func (it *Iterator[T]) All(ctx context.Context) (items []T, err error) {
+	for it.Next(ctx) {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
@@ -0,0 +1,279 @@
+package bybit_exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+/*
+	Sets the leverage of a perp. Linear perps take a leverage per side, inverse
+	perps a single one. Setting the leverage it already has succeeds.
+
+	Requires:
+		params SetPerpLeverageParams
+
+	Returns:
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-setleverage
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-setleverage
+*/
+func (bybit *BybitExchange) SetPerpLeverage(params SetPerpLeverageParams) (err error) {
+	return bybit.SetPerpLeverageCtx(context.Background(), params)
+}
+
+// Same as SetPerpLeverage, cancelled or timed out through ctx
+func (bybit *BybitExchange) SetPerpLeverageCtx(ctx context.Context, params SetPerpLeverageParams) (err error) {
+	functionName := "SetPerpLeverage"
+	buyLeverage, sellLeverage, err := sideLeverages(functionName, params.BuyLeverage, params.SellLeverage)
+	if err != nil {
+		return err
+	}
+	isLinear, err := bybit.isLinear(ctx, params.Symbol)
+	if err != nil {
+		return err
+	}
+
+	requestParams := map[string]interface{}{}
+	requestParams["symbol"] = params.Symbol
+	if isLinear {
+		requestParams["buy_leverage"] = buyLeverage.String()
+		requestParams["sell_leverage"] = sellLeverage.String()
+		return bybit.sendPositionSetting(ctx, functionName, SET_LINEAR_LEVERAGE, requestParams)
+	}
+
+	if !buyLeverage.Equal(sellLeverage) {
+		return fmt.Errorf("%v failed: inverse perp %v takes a single leverage", functionName, params.Symbol)
+	}
+	requestParams["leverage"] = buyLeverage.String()
+	return bybit.sendPositionSetting(ctx, functionName, SET_PERP_LEVERAGE, requestParams)
+}
+
+/*
+	Switches a perp between isolated and cross margin, setting its leverage at
+	the same time. Switching to the mode it's already in succeeds.
+
+	Requires:
+		params SetPerpMarginModeParams
+
+	Returns:
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-marginswitch
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-marginswitch
+*/
+func (bybit *BybitExchange) SetPerpMarginMode(params SetPerpMarginModeParams) (err error) {
+	return bybit.SetPerpMarginModeCtx(context.Background(), params)
+}
+
+// Same as SetPerpMarginMode, cancelled or timed out through ctx
+func (bybit *BybitExchange) SetPerpMarginModeCtx(ctx context.Context, params SetPerpMarginModeParams) (err error) {
+	functionName := "SetPerpMarginMode"
+	buyLeverage, sellLeverage, err := sideLeverages(functionName, params.BuyLeverage, params.SellLeverage)
+	if err != nil {
+		return err
+	}
+	isLinear, err := bybit.isLinear(ctx, params.Symbol)
+	if err != nil {
+		return err
+	}
+	endpoint := SWITCH_PERP_ISOLATED
+	if isLinear {
+		endpoint = SWITCH_LINEAR_ISOLATED
+	}
+
+	requestParams := map[string]interface{}{}
+	requestParams["symbol"] = params.Symbol
+	requestParams["is_isolated"] = params.IsIsolated
+	requestParams["buy_leverage"] = buyLeverage.String()
+	requestParams["sell_leverage"] = sellLeverage.String()
+	return bybit.sendPositionSetting(ctx, functionName, endpoint, requestParams)
+}
+
+/*
+	Switches a perp between one-way mode, a single position per symbol, and
+	hedge mode, a long and a short position held at once. Bybit refuses while
+	the symbol has a position or active orders. Switching to the mode it's
+	already in succeeds.
+
+	Requires:
+		symbol string
+		mode string - POSITION_MODE_ONE_WAY or POSITION_MODE_HEDGE
+
+	Returns:
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-switchpositionmode
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-switchpositionmode
+*/
+func (bybit *BybitExchange) SetPerpPositionMode(symbol string, mode string) (err error) {
+	return bybit.SetPerpPositionModeCtx(context.Background(), symbol, mode)
+}
+
+// Same as SetPerpPositionMode, cancelled or timed out through ctx
+func (bybit *BybitExchange) SetPerpPositionModeCtx(ctx context.Context, symbol string, mode string) (err error) {
+	functionName := "SetPerpPositionMode"
+	if mode != POSITION_MODE_ONE_WAY && mode != POSITION_MODE_HEDGE {
+		return fmt.Errorf("%v failed: invalid position mode %q", functionName, mode)
+	}
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+	if isLinear {
+		params["mode"] = mode
+		return bybit.sendPositionSetting(ctx, functionName, SWITCH_LINEAR_MODE, params)
+	}
+
+	// inverse perps number the modes
+	params["mode"] = 0
+	if mode == POSITION_MODE_HEDGE {
+		params["mode"] = 3
+	}
+	return bybit.sendPositionSetting(ctx, functionName, SWITCH_PERP_POSITION_MODE, params)
+}
+
+/*
+	Switches a perp between full take profit and stop loss, covering the whole
+	position, and partial, covering part of it. Switching to the mode it's
+	already in succeeds.
+
+	Requires:
+		symbol string
+		mode string - TP_SL_MODE_FULL or TP_SL_MODE_PARTIAL
+
+	Returns:
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-switchmode
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-switchmode
+*/
+func (bybit *BybitExchange) SetPerpTpSlMode(symbol string, mode string) (err error) {
+	return bybit.SetPerpTpSlModeCtx(context.Background(), symbol, mode)
+}
+
+// Same as SetPerpTpSlMode, cancelled or timed out through ctx
+func (bybit *BybitExchange) SetPerpTpSlModeCtx(ctx context.Context, symbol string, mode string) (err error) {
+	functionName := "SetPerpTpSlMode"
+	if mode != TP_SL_MODE_FULL && mode != TP_SL_MODE_PARTIAL {
+		return fmt.Errorf("%v failed: invalid tp/sl mode %q", functionName, mode)
+	}
+	isLinear, err := bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	endpoint := SWITCH_PERP_TP_SL_MODE
+	if isLinear {
+		endpoint = SWITCH_LINEAR_TP_SL_MODE
+	}
+
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+	params["tp_sl_mode"] = mode
+	return bybit.sendPositionSetting(ctx, functionName, endpoint, params)
+}
+
+/*
+	Adds margin to an isolated perp position, or reduces it when Margin is
+	negative. Unlike the settings above this isn't idempotent, so an ambiguous
+	failure is returned rather than resent.
+
+	Requires:
+		params ChangePerpMarginParams
+
+	Returns:
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-changemargin
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-addmargin
+*/
+func (bybit *BybitExchange) ChangePerpMargin(params ChangePerpMarginParams) (err error) {
+	return bybit.ChangePerpMarginCtx(context.Background(), params)
+}
+
+// Same as ChangePerpMargin, cancelled or timed out through ctx
+func (bybit *BybitExchange) ChangePerpMarginCtx(ctx context.Context, params ChangePerpMarginParams) (err error) {
+	functionName := "ChangePerpMargin"
+	if params.Margin.IsZero() {
+		return fmt.Errorf("%v failed: no margin to change", functionName)
+	}
+	isLinear, err := bybit.isLinear(ctx, params.Symbol)
+	if err != nil {
+		return err
+	}
+
+	requestParams := map[string]interface{}{}
+	requestParams["symbol"] = params.Symbol
+	requestParams["margin"] = params.Margin.String()
+	endpoint := CHANGE_PERP_MARGIN
+	if isLinear {
+		endpoint = CHANGE_LINEAR_MARGIN
+		side := params.Side
+		if side == "" {
+			if side, err = bybit.openLinearSide(ctx, functionName, params.Symbol); err != nil {
+				return err
+			}
+		}
+		requestParams["side"] = side
+	}
+
+	// create request
+	req := newSignedRequest(http.MethodPost, endpoint, requestParams)
+
+	var response = new(ResponseForPositionSetting)
+	return bybit.sendRequest(ctx, functionName, req, response)
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// sends a setting that can be resent, bybit answering that it already holds counts as success
+func (bybit *BybitExchange) sendPositionSetting(ctx context.Context, functionName, endpoint string, params map[string]interface{}) error {
+	req := newSignedRequest(http.MethodPost, endpoint, params)
+	req.idempotent = true
+
+	var response = new(ResponseForPositionSetting)
+	if err := bybit.sendRequest(ctx, functionName, req, response); err != nil && !errors.Is(err, ErrNotModified) {
+		return err
+	}
+	return nil
+}
+
+// the side of the one open leg of a linear position, in hedge mode with both
+// legs open there's no telling which one is meant
+func (bybit *BybitExchange) openLinearSide(ctx context.Context, functionName, symbol string) (side string, err error) {
+	positions, err := bybit.getLinearPositions(ctx, functionName, symbol)
+	if err != nil {
+		return side, err
+	}
+	for _, position := range positions {
+		if !position.Size.IsPositive() {
+			continue
+		}
+		if side != "" {
+			return "", fmt.Errorf("%v failed: both %v legs are open, set Side", functionName, symbol)
+		}
+		side = position.Side
+	}
+	if side == "" {
+		return side, fmt.Errorf("%v failed: no open %v position", functionName, symbol)
+	}
+	return side, nil
+}
+
+// the buy and sell leverage, sell defaulting to buy
+func sideLeverages(functionName string, buyLeverage, sellLeverage decimal.Decimal) (buy, sell decimal.Decimal, err error) {
+	if sellLeverage.IsZero() {
+		sellLeverage = buyLeverage
+	}
+	if !buyLeverage.IsPositive() || !sellLeverage.IsPositive() {
+		return buy, sell, fmt.Errorf("%v failed: leverage must be positive", functionName)
+	}
+	return buyLeverage, sellLeverage, nil
+}
@@ -0,0 +1,398 @@
+package bybit_exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// What a request does when its rate limit group has no tokens left
+type RateLimitMode int
+
+const (
+	RATE_LIMIT_WAIT      RateLimitMode = iota // block until a token is available or ctx is done
+	RATE_LIMIT_FAIL_FAST                      // return ErrRateLimited immediately
+)
+
+// Request priority within a rate limit group
+type RateLimitPriority int
+
+const (
+	PRIORITY_NORMAL RateLimitPriority = iota
+	PRIORITY_HIGH                     // cancels, served before normal requests and allowed into the reserve
+)
+
+// Snapshot of a rate limit group
+type RateLimitStatus struct {
+	Group        string
+	Limit        int           // requests allowed per window
+	Window       time.Duration // window the limit applies to
+	Remaining    int           // tokens currently available
+	BlockedUntil time.Time     // set when bybit reported the group exhausted or rate limited us
+}
+
+// Per endpoint group token buckets, seeded with bybit's documented limits and
+// adjusted from the rate limit headers and fields of every response.
+// Safe for concurrent use.
+type RateLimiter struct {
+	mode RateLimitMode
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	limit        float64
+	window       time.Duration
+	tokens       float64
+	lastRefill   time.Time
+	blockedUntil time.Time
+	highWaiting  int // high priority requests waiting, normal requests yield to them
+}
+
+type rateLimitSeed struct {
+	limit  int
+	window time.Duration
+}
+
+// documented limits, replaced by the live values bybit reports
+var defaultRateLimits = map[string]rateLimitSeed{
+	RATE_LIMIT_PUBLIC:           {50, time.Second},
+	RATE_LIMIT_PRIVATE:          {120, time.Minute},
+	RATE_LIMIT_PERP_ORDER:       {100, time.Minute},
+	RATE_LIMIT_PERP_ORDER_QUERY: {600, time.Minute},
+	RATE_LIMIT_PERP_POSITION:    {120, time.Minute},
+	RATE_LIMIT_PERP_WALLET:      {120, time.Minute},
+	RATE_LIMIT_LINEAR_ORDER:     {100, time.Minute},
+	RATE_LIMIT_LINEAR_QUERY:     {600, time.Minute},
+	RATE_LIMIT_LINEAR_POSITION:  {120, time.Minute},
+	RATE_LIMIT_SPOT_ORDER:       {20, time.Second},
+	RATE_LIMIT_SPOT_QUERY:       {20, time.Second},
+	RATE_LIMIT_ASSET:            {60, time.Minute},
+	RATE_LIMIT_V5_ORDER:         {10, time.Second},
+	RATE_LIMIT_V5_ORDER_QUERY:   {50, time.Second},
+	RATE_LIMIT_V5_POSITION:      {50, time.Second},
+	RATE_LIMIT_V5_ACCOUNT:       {50, time.Second},
+}
+
+// endpoint to rate limit group, keyed by "METHOD path" first then by path
+var endpointRateLimitGroups = map[string]string{
+	http.MethodGet + " " + SPOT_ORDER:    RATE_LIMIT_SPOT_QUERY,
+	http.MethodPost + " " + SPOT_ORDER:   RATE_LIMIT_SPOT_ORDER,
+	http.MethodDelete + " " + SPOT_ORDER: RATE_LIMIT_SPOT_ORDER,
+	GET_SPOT_BALANCE:                     RATE_LIMIT_SPOT_QUERY,
+	GET_SPOT_OPEN_ORDERS:                 RATE_LIMIT_SPOT_QUERY,
+	GET_SPOT_ORDER_HISTORY:               RATE_LIMIT_SPOT_QUERY,
+	GET_SPOT_TRADE_HISTORY:               RATE_LIMIT_SPOT_QUERY,
+	PLACE_PERP_ORDER:                     RATE_LIMIT_PERP_ORDER,
+	CANCEL_PERP_ORDER:                    RATE_LIMIT_PERP_ORDER,
+	GET_PERP_ORDER:                       RATE_LIMIT_PERP_ORDER_QUERY,
+	QUERY_PERP_ORDER:                     RATE_LIMIT_PERP_ORDER_QUERY,
+	GET_PERP_POSITION:                    RATE_LIMIT_PERP_POSITION,
+	GET_PERP_BALANCE:                     RATE_LIMIT_PERP_WALLET,
+	PLACE_LINEAR_ORDER:                   RATE_LIMIT_LINEAR_ORDER,
+	CANCEL_LINEAR_ORDER:                  RATE_LIMIT_LINEAR_ORDER,
+	GET_LINEAR_ORDER:                     RATE_LIMIT_LINEAR_QUERY,
+	QUERY_LINEAR_ORDER:                   RATE_LIMIT_LINEAR_QUERY,
+	GET_LINEAR_POSITION:                  RATE_LIMIT_LINEAR_POSITION,
+	GET_PERP_EXECUTIONS:                  RATE_LIMIT_PERP_POSITION,
+	GET_PERP_CLOSED_PNL:                  RATE_LIMIT_PERP_POSITION,
+	GET_LINEAR_EXECUTIONS:                RATE_LIMIT_LINEAR_POSITION,
+	GET_LINEAR_CLOSED_PNL:                RATE_LIMIT_LINEAR_POSITION,
+	REPLACE_PERP_ORDER:                   RATE_LIMIT_PERP_ORDER,
+	CANCEL_ALL_PERP_ORDERS:               RATE_LIMIT_PERP_ORDER,
+	CANCEL_ALL_LINEAR_ORDERS:             RATE_LIMIT_LINEAR_ORDER,
+	BATCH_CANCEL_SPOT_ORDERS:             RATE_LIMIT_SPOT_ORDER,
+	BATCH_CANCEL_SPOT_BY_IDS:             RATE_LIMIT_SPOT_ORDER,
+	REPLACE_LINEAR_ORDER:                 RATE_LIMIT_LINEAR_ORDER,
+	PLACE_PERP_STOP_ORDER:                RATE_LIMIT_PERP_ORDER,
+	REPLACE_PERP_STOP_ORDER:              RATE_LIMIT_PERP_ORDER,
+	CANCEL_PERP_STOP_ORDER:               RATE_LIMIT_PERP_ORDER,
+	GET_PERP_STOP_ORDER:                  RATE_LIMIT_PERP_ORDER_QUERY,
+	QUERY_PERP_STOP_ORDER:                RATE_LIMIT_PERP_ORDER_QUERY,
+	PLACE_LINEAR_STOP_ORDER:              RATE_LIMIT_LINEAR_ORDER,
+	REPLACE_LINEAR_STOP_ORDER:            RATE_LIMIT_LINEAR_ORDER,
+	CANCEL_LINEAR_STOP_ORDER:             RATE_LIMIT_LINEAR_ORDER,
+	GET_LINEAR_STOP_ORDER:                RATE_LIMIT_LINEAR_QUERY,
+	QUERY_LINEAR_STOP_ORDER:              RATE_LIMIT_LINEAR_QUERY,
+	SET_PERP_LEVERAGE:                    RATE_LIMIT_PERP_POSITION,
+	SET_LINEAR_LEVERAGE:                  RATE_LIMIT_LINEAR_POSITION,
+	SWITCH_PERP_ISOLATED:                 RATE_LIMIT_PERP_POSITION,
+	SWITCH_LINEAR_ISOLATED:               RATE_LIMIT_LINEAR_POSITION,
+	SWITCH_PERP_POSITION_MODE:            RATE_LIMIT_PERP_POSITION,
+	SWITCH_LINEAR_MODE:                   RATE_LIMIT_LINEAR_POSITION,
+	SWITCH_PERP_TP_SL_MODE:               RATE_LIMIT_PERP_POSITION,
+	SWITCH_LINEAR_TP_SL_MODE:             RATE_LIMIT_LINEAR_POSITION,
+	CHANGE_PERP_MARGIN:                   RATE_LIMIT_PERP_POSITION,
+	CHANGE_LINEAR_MARGIN:                 RATE_LIMIT_LINEAR_POSITION,
+	SET_PERP_TRADING_STOP:                RATE_LIMIT_PERP_POSITION,
+	SET_LINEAR_TRADING_STOP:              RATE_LIMIT_LINEAR_POSITION,
+	GET_DEPOSIT_ADDRESS:                  RATE_LIMIT_ASSET,
+	WITHDRAW_FROM_SPOT_WALLET:            RATE_LIMIT_ASSET,
+	V5_PLACE_ORDER:                       RATE_LIMIT_V5_ORDER,
+	V5_CANCEL_ORDER:                      RATE_LIMIT_V5_ORDER,
+	V5_OPEN_ORDERS:                       RATE_LIMIT_V5_ORDER_QUERY,
+	V5_ORDER_HISTORY:                     RATE_LIMIT_V5_ORDER_QUERY,
+	V5_POSITIONS:                         RATE_LIMIT_V5_POSITION,
+	V5_WALLET_BALANCE:                    RATE_LIMIT_V5_ACCOUNT,
+	V5_DEPOSIT_ADDRESS:                   RATE_LIMIT_ASSET,
+	V5_WITHDRAW:                          RATE_LIMIT_ASSET,
+}
+
+// endpoints that must not be starved by new orders, keyed like endpointRateLimitGroups
+var highPriorityEndpoints = map[string]bool{
+	http.MethodDelete + " " + SPOT_ORDER: true,
+	CANCEL_PERP_ORDER:                    true,
+	CANCEL_LINEAR_ORDER:                  true,
+	CANCEL_PERP_STOP_ORDER:               true,
+	CANCEL_ALL_PERP_ORDERS:               true,
+	CANCEL_ALL_LINEAR_ORDERS:             true,
+	BATCH_CANCEL_SPOT_ORDERS:             true,
+	BATCH_CANCEL_SPOT_BY_IDS:             true,
+	CANCEL_LINEAR_STOP_ORDER:             true,
+	V5_CANCEL_ORDER:                      true,
+}
+
+func newRateLimiter(mode RateLimitMode) *RateLimiter {
+	return &RateLimiter{
+		mode:    mode,
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+/*
+	Takes a token from the group's bucket. Waits for one in RATE_LIMIT_WAIT mode,
+	returns ErrRateLimited right away in RATE_LIMIT_FAIL_FAST mode.
+
+	Requires:
+		ctx context.Context
+		group string - rate limit group, e.g. RATE_LIMIT_PERP_ORDER
+		priority RateLimitPriority
+
+	Returns:
+		err error
+*/
+func (rl *RateLimiter) Wait(ctx context.Context, group string, priority RateLimitPriority) error {
+	isWaiting := false
+	defer func() {
+		if isWaiting {
+			rl.mu.Lock()
+			rl.bucket(group).highWaiting--
+			rl.mu.Unlock()
+		}
+	}()
+
+	for {
+		rl.mu.Lock()
+		wait := rl.bucket(group).take(time.Now(), priority)
+		if wait == 0 {
+			rl.mu.Unlock()
+			return nil
+		}
+		if rl.mode == RATE_LIMIT_FAIL_FAST {
+			rl.mu.Unlock()
+			return fmt.Errorf("%v rate limit exhausted for %v: %w", group, wait, ErrRateLimited)
+		}
+		if priority == PRIORITY_HIGH && !isWaiting {
+			rl.bucket(group).highWaiting++
+			isWaiting = true
+		}
+		rl.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Returns a snapshot of the group's bucket
+func (rl *RateLimiter) Status(group string) RateLimitStatus {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	bucket := rl.bucket(group)
+	bucket.refill(time.Now())
+	return RateLimitStatus{
+		Group:        group,
+		Limit:        int(bucket.limit),
+		Window:       bucket.window,
+		Remaining:    int(bucket.tokens),
+		BlockedUntil: bucket.blockedUntil,
+	}
+}
+
+/*
+	Adjusts the group's bucket to what bybit reports.
+
+	Requires:
+		group string
+		limit int - requests allowed per window, ignored when not positive
+		remaining int - requests left in the current window
+		resetAt time.Time - when the window resets, zero when unknown
+*/
+func (rl *RateLimiter) Update(group string, limit, remaining int, resetAt time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	bucket := rl.bucket(group)
+	bucket.refill(now)
+
+	if limit > 0 {
+		bucket.limit = float64(limit)
+	}
+	bucket.tokens = math.Max(0, math.Min(bucket.tokens, float64(remaining)))
+	if remaining <= 0 && resetAt.After(now) && resetAt.After(bucket.blockedUntil) {
+		bucket.blockedUntil = resetAt
+	}
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// reads the X-Bapi-Limit headers and the rate_limit fields of legacy responses
+func (rl *RateLimiter) observe(group string, header http.Header, body []byte) {
+	// without a readable status the remaining count is unknown, not zero
+	limit, limitErr := strconv.Atoi(header.Get("X-Bapi-Limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("X-Bapi-Limit-Status"))
+	if limitErr == nil && remainingErr == nil {
+		resetMs, _ := strconv.ParseInt(header.Get("X-Bapi-Limit-Reset-Timestamp"), 10, 64)
+		rl.Update(group, limit, remaining, millisToTime(resetMs))
+		return
+	}
+
+	var fields struct {
+		RateLimit        *int     `json:"rate_limit"`
+		RateLimitStatus  *int     `json:"rate_limit_status"`
+		RateLimitResetMs *float64 `json:"rate_limit_reset_ms"`
+	}
+	if json.Unmarshal(body, &fields) != nil || fields.RateLimitStatus == nil {
+		return
+	}
+
+	limit = 0
+	if fields.RateLimit != nil {
+		limit = *fields.RateLimit
+	}
+	var resetAt time.Time
+	if fields.RateLimitResetMs != nil {
+		resetAt = millisToTime(int64(*fields.RateLimitResetMs))
+	}
+	rl.Update(group, limit, *fields.RateLimitStatus, resetAt)
+}
+
+// blocks the group for a while after bybit said we were rate limited
+func (rl *RateLimiter) backOffOn(group string, err error) {
+	if !errors.Is(err, ErrRateLimited) {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	bucket := rl.bucket(group)
+	if until := time.Now().Add(RATE_LIMIT_BACKOFF); until.After(bucket.blockedUntil) {
+		bucket.blockedUntil = until
+	}
+	bucket.tokens = 0
+}
+
+// must be called with rl.mu held
+func (rl *RateLimiter) bucket(group string) *tokenBucket {
+	bucket, ok := rl.buckets[group]
+	if !ok {
+		seed, ok := defaultRateLimits[group]
+		if !ok {
+			seed = defaultRateLimits[RATE_LIMIT_PRIVATE]
+		}
+		bucket = &tokenBucket{
+			limit:      float64(seed.limit),
+			window:     seed.window,
+			tokens:     float64(seed.limit),
+			lastRefill: time.Now(),
+		}
+		rl.buckets[group] = bucket
+	}
+	return bucket
+}
+
+func (bucket *tokenBucket) refill(now time.Time) {
+	// bybit's window rolled over, the whole limit is available again
+	if !bucket.blockedUntil.IsZero() && !now.Before(bucket.blockedUntil) {
+		bucket.tokens = bucket.limit
+		bucket.lastRefill = now
+		bucket.blockedUntil = time.Time{}
+		return
+	}
+
+	elapsed := now.Sub(bucket.lastRefill)
+	if elapsed <= 0 {
+		return
+	}
+	bucket.tokens = math.Min(bucket.limit, bucket.tokens+bucket.limit*elapsed.Seconds()/bucket.window.Seconds())
+	bucket.lastRefill = now
+}
+
+// takes a token and returns 0, or returns how long to wait before trying again
+func (bucket *tokenBucket) take(now time.Time, priority RateLimitPriority) time.Duration {
+	if now.Before(bucket.blockedUntil) {
+		return bucket.blockedUntil.Sub(now)
+	}
+	bucket.refill(now)
+
+	// normal requests leave a reserve for cancels and yield to waiting ones
+	reserve := 0.0
+	if priority == PRIORITY_NORMAL {
+		reserve = math.Floor(bucket.limit * RATE_LIMIT_RESERVE)
+		if bucket.highWaiting > 0 {
+			return RATE_LIMIT_POLL_INTERVAL
+		}
+	}
+
+	if bucket.tokens-reserve >= 1 {
+		bucket.tokens--
+		return 0
+	}
+
+	missing := 1 + reserve - bucket.tokens
+	wait := time.Duration(missing / bucket.limit * float64(bucket.window))
+	if wait < RATE_LIMIT_POLL_INTERVAL {
+		wait = RATE_LIMIT_POLL_INTERVAL
+	}
+	return wait
+}
+
+func endpointRateLimit(req *apiRequest) (group string, priority RateLimitPriority) {
+	key := req.method + " " + req.path
+	priority = PRIORITY_NORMAL
+	if highPriorityEndpoints[key] || highPriorityEndpoints[req.path] {
+		priority = PRIORITY_HIGH
+	}
+
+	if group, ok := endpointRateLimitGroups[key]; ok {
+		return group, priority
+	}
+	if group, ok := endpointRateLimitGroups[req.path]; ok {
+		return group, priority
+	}
+	if req.auth == authNone {
+		return RATE_LIMIT_PUBLIC, priority
+	}
+	return RATE_LIMIT_PRIVATE, priority
+}
+
+func millisToTime(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
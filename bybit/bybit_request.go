@@ -0,0 +1,277 @@
+package bybit_exchange
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How a request is authenticated
+type authMode int
+
+const (
+	authNone        authMode = iota // public endpoint
+	authQuery                       // api_key, timestamp and sign in the query string
+	authSignAsABody                 // api_key, timestamp and sign in the json body
+	authHeader                      // v5, X-BAPI-* headers signing the query string or json body
+)
+
+// A single REST call. It is only bound to a host and signed when sent, so a
+// wait on the rate limiter never eats into the recv_window.
+type apiRequest struct {
+	method     string
+	path       string
+	params     map[string]interface{}
+	auth       authMode
+	idempotent bool // safe to resend when the outcome of an attempt is unknown
+}
+
+func newPublicRequest(method, path string, params map[string]interface{}) *apiRequest {
+	return &apiRequest{method: method, path: path, params: params, auth: authNone, idempotent: method == http.MethodGet}
+}
+
+func newSignedRequest(method, path string, params map[string]interface{}) *apiRequest {
+	return &apiRequest{method: method, path: path, params: params, auth: authQuery, idempotent: method == http.MethodGet}
+}
+
+func newSignedBodyRequest(method, path string, params map[string]interface{}) *apiRequest {
+	return &apiRequest{method: method, path: path, params: params, auth: authSignAsABody, idempotent: method == http.MethodGet}
+}
+
+func newHeaderSignedRequest(method, path string, params map[string]interface{}) *apiRequest {
+	return &apiRequest{method: method, path: path, params: params, auth: authHeader, idempotent: method == http.MethodGet}
+}
+
+// sends the request once and decodes its body into response, returning an *APIError
+// when bybit reports a non-zero ret_code or an unparsable non-2xx status
+func (bybit *BybitExchange) sendOnce(ctx context.Context, functionName string, req *apiRequest, response apiResponder) error {
+	group, priority := endpointRateLimit(req)
+	if err := bybit.rateLimiter.Wait(ctx, group, priority); err != nil {
+		err = fmt.Errorf("%v failed: %w", functionName, err)
+		bybit.logger.Error(err.Error())
+		return err
+	}
+
+	host := bybit.hostPool.pick()
+	httpReq, err := bybit.buildRequest(ctx, host, req)
+	if err != nil {
+		err = fmt.Errorf("%v failed to create request object: %w", functionName, &notSentError{err})
+		bybit.logger.Error(err.Error())
+		return err
+	}
+
+	body, header, statusCode, err := bybit.getResponseBody(functionName, httpReq)
+	// connection errors and cdn 5xx count against the host, our own cancellation doesn't
+	if ctx.Err() == nil {
+		bybit.hostPool.report(host, err != nil || statusCode >= http.StatusInternalServerError)
+	}
+	if err != nil {
+		return err
+	}
+	bybit.rateLimiter.observe(group, header, body)
+
+	if err = json.Unmarshal(body, response); err != nil {
+		if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+			err = newHTTPError(req.path, statusCode)
+		} else {
+			err = fmt.Errorf("%v failed to parse response body: %w", functionName, err)
+		}
+		bybit.rateLimiter.backOffOn(group, err)
+		bybit.logger.Error(err.Error())
+		return err
+	}
+
+	if err = checkApiResponse(req.path, statusCode, response.apiResponse()); err != nil {
+		bybit.rateLimiter.backOffOn(group, err)
+		bybit.logger.Error(fmt.Sprintf("%v failed: %v", functionName, err))
+		return err
+	}
+
+	return nil
+}
+
+func (bybit *BybitExchange) getResponseBody(functionName string, req *http.Request) (body []byte, header http.Header, statusCode int, err error) {
+	// bound requests without a caller deadline by the configured request timeout
+	if _, ok := req.Context().Deadline(); !ok && bybit.requestTimeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), bybit.requestTimeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	// make request
+	res, err := bybit.Client.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			// the connection never opened, so nothing reached bybit
+			err = fmt.Errorf("%v failed to connect: %w", functionName, &notSentError{&transportError{err}})
+		} else {
+			err = fmt.Errorf("%v failed to make request: %w", functionName, &transportError{err})
+		}
+		bybit.logger.Error(err.Error())
+		return body, header, statusCode, err
+	}
+	defer res.Body.Close()
+
+	// read response
+	body, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		err = fmt.Errorf("%v failed to read response body: %w", functionName, &transportError{err})
+		bybit.logger.Error(err.Error())
+		return body, res.Header, res.StatusCode, err
+	}
+
+	return body, res.Header, res.StatusCode, err
+}
+
+// binds the request to host and signs it with a fresh timestamp
+func (bybit *BybitExchange) buildRequest(ctx context.Context, host string, req *apiRequest) (*http.Request, error) {
+	// copy params so signing never leaks into the caller's map or a previous attempt
+	params := map[string]interface{}{}
+	for k, v := range req.params {
+		params[k] = v
+	}
+
+	if req.auth == authHeader {
+		return bybit.buildHeaderSignedRequest(ctx, host, req, params)
+	}
+	if req.auth != authNone {
+		if err := bybit.addAuthParams(ctx, params); err != nil {
+			return nil, err
+		}
+	}
+
+	param := bybit.makeParamString(params)
+
+	var fullURL string
+	var body []byte
+	switch req.auth {
+	case authNone:
+		fullURL = host + req.path + "?" + param
+	case authQuery:
+		fullURL = host + req.path + "?" + param + "&sign=" + bybit.sign(param)
+	case authSignAsABody:
+		params["sign"] = bybit.sign(param)
+		body, _ = json.Marshal(params)
+		fullURL = host + req.path
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, fullURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return httpReq, nil
+}
+
+// v5 signs timestamp + api key + recv_window + query string (GET) or json body (POST)
+func (bybit *BybitExchange) buildHeaderSignedRequest(ctx context.Context, host string, req *apiRequest, params map[string]interface{}) (*http.Request, error) {
+	timestamp, err := bybit.timeSync.Timestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var fullURL, payload string
+	var body []byte
+	if req.method == http.MethodGet {
+		payload = bybit.makeParamString(params)
+		fullURL = host + req.path + "?" + payload
+	} else {
+		if body, err = json.Marshal(params); err != nil {
+			return nil, err
+		}
+		payload = string(body)
+		fullURL = host + req.path
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, fullURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	timestampStr := strconv.FormatInt(timestamp, 10)
+	recvWindow := strconv.Itoa(bybit.recvWindow)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-BAPI-API-KEY", bybit.ApiKey)
+	httpReq.Header.Set("X-BAPI-TIMESTAMP", timestampStr)
+	httpReq.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+	httpReq.Header.Set("X-BAPI-SIGN-TYPE", "2")
+	httpReq.Header.Set("X-BAPI-SIGN", bybit.sign(timestampStr+bybit.ApiKey+recvWindow+payload))
+
+	return httpReq, nil
+}
+
+// health checks a single host through the server time endpoint
+func (bybit *BybitExchange) probeHost(ctx context.Context, host string) (rtt time.Duration, err error) {
+	functionName := "ProbeHost"
+	req := newPublicRequest(http.MethodGet, bybit.serverTimePath, map[string]interface{}{})
+	if err = bybit.rateLimiter.Wait(ctx, RATE_LIMIT_PUBLIC, PRIORITY_NORMAL); err != nil {
+		return rtt, err
+	}
+
+	httpReq, err := bybit.buildRequest(ctx, host, req)
+	if err != nil {
+		return rtt, err
+	}
+
+	start := time.Now()
+	body, _, statusCode, err := bybit.getResponseBody(functionName, httpReq)
+	rtt = time.Since(start)
+	if err != nil {
+		return rtt, err
+	}
+
+	var response = new(ApiResponse)
+	if err = json.Unmarshal(body, response); err != nil {
+		return rtt, newHTTPError(req.path, statusCode)
+	}
+	return rtt, checkApiResponse(req.path, statusCode, *response)
+}
+
+func (bybit *BybitExchange) addAuthParams(ctx context.Context, params map[string]interface{}) error {
+	timestamp, err := bybit.timeSync.Timestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	params["api_key"] = bybit.ApiKey
+	params["timestamp"] = fmt.Sprintf("%d", timestamp)
+	params["recv_window"] = bybit.recvWindow
+	return nil
+}
+
+func (bybit *BybitExchange) makeParamString(params map[string]interface{}) (param string) {
+	var keys []string
+	for k := range params {
+		keys = append(keys, k)
+	}
+	// keys must be sorted in alphabetical order when signed
+	sort.Strings(keys)
+
+	var p []string
+	for _, k := range keys {
+		p = append(p, fmt.Sprintf("%v=%v", k, params[k]))
+	}
+
+	param = strings.Join(p, "&")
+	return param
+}
+
+func (bybit *BybitExchange) sign(signaturePayload string) string {
+	mac := hmac.New(sha256.New, []byte(bybit.SecretKey))
+	mac.Write([]byte(signaturePayload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,784 @@
+package bybit_exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
+	"github.com/shopspring/decimal"
+)
+
+// Unified rest client adapting a bybit client to exchange.ExchangeRestClient.
+// Translates unified sides, order types, market types and symbols into bybit
+// shaped requests, and bybit orders and positions back into unified results.
+type BybitRestClient struct {
+	Exchange BybitClient
+}
+
+// Calls the unified rest client needs, implemented by both the legacy
+// BybitExchange and the v5 BybitV5Exchange
+type BybitClient interface {
+	GetSpotWalletBalanceForSymbolCtx(ctx context.Context, symbol string) (balance float64, err error)
+	GetTotalAcctUsdValueCtx(ctx context.Context) (balance float64, err error)
+	GetSpotOrderCtx(ctx context.Context, orderId string) (spotOrder SpotOrder, err error)
+	GetPerpOrderCtx(ctx context.Context, symbol string) (perpOrders []PerpOrder, err error)
+	GetPerpOrderByIdCtx(ctx context.Context, symbol, orderId string) (perpOrder PerpOrder, err error)
+	GetPerpPositionCtx(ctx context.Context, symbol string) (position PerpPosition, err error)
+	GetPerpPositionsCtx(ctx context.Context, symbol string) (positions []PerpPosition, err error)
+	IsInversePerpCtx(ctx context.Context, symbol string) (isInverse bool, err error)
+	GetPerpWalletBalanceCtx(ctx context.Context) (balances map[string]PerpBalance, err error)
+	GetDepositAddressCtx(ctx context.Context, symbol, network string) (address string, err error)
+	GetMarketPriceCtx(ctx context.Context, baseCurr, quoteCurr, marketType string) (marketPrice float64, err error)
+	GetMarketPriceDecimalCtx(ctx context.Context, baseCurr, quoteCurr, marketType string) (marketPrice decimal.Decimal, err error)
+	GetPerpMarketPriceDecimalCtx(ctx context.Context, symbol string) (marketPrice decimal.Decimal, err error)
+	GetSpotBookTickerCtx(ctx context.Context, symbol string) (bookTicker SpotMarketPrice, err error)
+	PlaceSpotOrderCtx(ctx context.Context, params PlaceSpotOrderParams) (orderId string, err error)
+	PlacePerpOrderCtx(ctx context.Context, params PlacePerpOrderParams) (orderId string, err error)
+	WithdrawFromExchangeCtx(ctx context.Context, coin string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error)
+	CancelSpotOrderCtx(ctx context.Context, orderId string) (status bool, err error)
+	CancelPerpOrderCtx(ctx context.Context, symbol, orderId string) (status bool, err error)
+}
+
+// fails compilation if the adapter stops satisfying the unified interfaces
+var (
+	_ exchange.ExchangeRestClient    = (*BybitRestClient)(nil)
+	_ exchange.ExchangeRestClientCtx = (*BybitRestClient)(nil)
+)
+
+// Generates new unified rest client on top of a bybit exchange client.
+// Requires: initiated bybit exchange client, legacy (NewBybitExchange) or v5 (NewBybitV5Exchange)
+func NewBybitRestClient(bybit BybitClient) *BybitRestClient {
+	return &BybitRestClient{Exchange: bybit}
+}
+
+// ---------------------------- GETTERS ----------------------------
+
+/*
+	Gets free spot balance for currency.
+
+	Requires:
+		symbol string - currency symbol, e.g. "BTC"
+
+	Returns:
+		balance float64
+		err error
+*/
+func (client *BybitRestClient) GetSpotBalance(symbol string) (balance float64, err error) {
+	return client.GetSpotBalanceCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotBalance, cancelled or timed out through ctx
+func (client *BybitRestClient) GetSpotBalanceCtx(ctx context.Context, symbol string) (balance float64, err error) {
+	return client.Exchange.GetSpotWalletBalanceForSymbolCtx(ctx, symbol)
+}
+
+/*
+	Gets the entire spot and perp wallet balance in USD.
+
+	Returns:
+		balance float64
+		err error
+*/
+func (client *BybitRestClient) GetTotalAcctUsdValue() (balance float64, err error) {
+	return client.GetTotalAcctUsdValueCtx(context.Background())
+}
+
+// Same as GetTotalAcctUsdValue, cancelled or timed out through ctx
+func (client *BybitRestClient) GetTotalAcctUsdValueCtx(ctx context.Context) (balance float64, err error) {
+	return client.Exchange.GetTotalAcctUsdValueCtx(ctx)
+}
+
+/*
+	Gets a spot or perp order.
+
+	Requires:
+		params exchange.GetOrderParams - ExchangeOrderId is used for perp orders since their ids are not numeric
+
+	Returns:
+		order exchange.Order
+		err error - wraps ErrOrderNotFound when bybit has no such perp order
+*/
+func (client *BybitRestClient) GetOrder(params exchange.GetOrderParams) (order exchange.Order, err error) {
+	return client.GetOrderCtx(context.Background(), params)
+}
+
+// Same as GetOrder, cancelled or timed out through ctx
+func (client *BybitRestClient) GetOrderCtx(ctx context.Context, params exchange.GetOrderParams) (order exchange.Order, err error) {
+	orderId := unifiedOrderId(params.OrderId, params.ExchangeOrderId)
+
+	switch strings.ToLower(params.MarketType) {
+	case exchange.MARKET_TYPE_SPOT:
+		spotOrder, err := client.Exchange.GetSpotOrderCtx(ctx, orderId)
+		if err != nil {
+			return order, err
+		}
+		return spotOrderToOrder(spotOrder), nil
+
+	case exchange.MARKET_TYPE_PERP:
+		symbol := toBybitSymbol(params.BaseCurrency, params.QuoteCurrency)
+		perpOrder, err := client.Exchange.GetPerpOrderByIdCtx(ctx, symbol, orderId)
+		if err != nil {
+			return order, err
+		}
+		isInverse, err := client.Exchange.IsInversePerpCtx(ctx, symbol)
+		if err != nil {
+			return order, err
+		}
+		return perpOrderToOrder(perpOrder, isInverse), nil
+	}
+
+	return order, unsupportedMarketType("GetOrder", params.MarketType)
+}
+
+/*
+	Gets perp position for a contract.
+
+	Requires:
+		symbol string - bybit contract symbol, e.g. "BTCUSD". A bare coin such as "BTC" resolves to its inverse contract.
+
+	Returns:
+		position exchange.Position
+		err error - wraps ErrHedgedPosition when both hedge legs are open, GetPerpPositions returns them both
+*/
+func (client *BybitRestClient) GetPerpPosition(symbol string) (position exchange.Position, err error) {
+	return client.GetPerpPositionCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpPosition, cancelled or timed out through ctx
+func (client *BybitRestClient) GetPerpPositionCtx(ctx context.Context, symbol string) (position exchange.Position, err error) {
+	symbol = toBybitContract(symbol)
+
+	perpPosition, err := client.Exchange.GetPerpPositionCtx(ctx, symbol)
+	if err != nil {
+		return position, err
+	}
+	isInverse, err := client.Exchange.IsInversePerpCtx(ctx, symbol)
+	if err != nil {
+		return position, err
+	}
+
+	return perpPositionToPosition(perpPosition, isInverse), nil
+}
+
+/*
+	Gets every leg of a perp position, in hedge mode the long leg has Side buy
+	and the short leg Side sell.
+
+	Requires:
+		symbol string - bybit contract symbol, e.g. "BTCUSDT". A bare coin such as "BTC" resolves to its inverse contract.
+
+	Returns:
+		positions []exchange.Position - flat legs included
+		err error
+*/
+func (client *BybitRestClient) GetPerpPositions(symbol string) (positions []exchange.Position, err error) {
+	return client.GetPerpPositionsCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpPositions, cancelled or timed out through ctx
+func (client *BybitRestClient) GetPerpPositionsCtx(ctx context.Context, symbol string) (positions []exchange.Position, err error) {
+	symbol = toBybitContract(symbol)
+
+	perpPositions, err := client.Exchange.GetPerpPositionsCtx(ctx, symbol)
+	if err != nil {
+		return positions, err
+	}
+	isInverse, err := client.Exchange.IsInversePerpCtx(ctx, symbol)
+	if err != nil {
+		return positions, err
+	}
+
+	for _, perpPosition := range perpPositions {
+		positions = append(positions, perpPositionToPosition(perpPosition, isInverse))
+	}
+	return positions, nil
+}
+
+/*
+	Gets perp wallet equity for a coin valued in USD.
+
+	Requires:
+		symbol string - coin symbol, e.g. "BTC" or "USDT"
+
+	Returns:
+		collateral float64
+		err error
+*/
+func (client *BybitRestClient) GetPerpMktCollateralUSD(symbol string) (collateral float64, err error) {
+	return client.GetPerpMktCollateralUSDCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpMktCollateralUSD, cancelled or timed out through ctx
+func (client *BybitRestClient) GetPerpMktCollateralUSDCtx(ctx context.Context, symbol string) (collateral float64, err error) {
+	balances, err := client.Exchange.GetPerpWalletBalanceCtx(ctx)
+	if err != nil {
+		return collateral, err
+	}
+
+	balance, ok := balances[symbol]
+	if !ok {
+		return collateral, fmt.Errorf("GetPerpMktCollateralUSD failed: no perp wallet for %v", symbol)
+	}
+
+	if isStablecoin(symbol) {
+		return balance.Equity.InexactFloat64(), nil
+	}
+
+	price, err := client.Exchange.GetMarketPriceDecimalCtx(ctx, symbol, "USD", exchange.MARKET_TYPE_PERP)
+	if err != nil {
+		return collateral, err
+	}
+
+	return balance.Equity.Mul(price).InexactFloat64(), nil
+}
+
+/*
+	Gets deposit address for currency.
+
+	Requires:
+		symbol string - currency symbol, e.g. "BTC"
+		network string - network symbol, e.g. "LTC"
+
+	Returns:
+		address string
+		err error
+*/
+func (client *BybitRestClient) GetDepositAddress(symbol, network string) (address string, err error) {
+	return client.GetDepositAddressCtx(context.Background(), symbol, network)
+}
+
+// Same as GetDepositAddress, cancelled or timed out through ctx
+func (client *BybitRestClient) GetDepositAddressCtx(ctx context.Context, symbol, network string) (address string, err error) {
+	return client.Exchange.GetDepositAddressCtx(ctx, symbol, network)
+}
+
+/*
+	Gets market price for perp or spot.
+
+	Requires:
+		baseCurr string
+		quoteCurr string
+		marketType string - "spot" or "perp"
+
+	Returns:
+		marketPrice float64
+		err error
+*/
+func (client *BybitRestClient) GetMarketPrice(baseCurr, quoteCurr, marketType string) (marketPrice float64, err error) {
+	return client.GetMarketPriceCtx(context.Background(), baseCurr, quoteCurr, marketType)
+}
+
+// Same as GetMarketPrice, cancelled or timed out through ctx
+func (client *BybitRestClient) GetMarketPriceCtx(ctx context.Context, baseCurr, quoteCurr, marketType string) (marketPrice float64, err error) {
+	return client.Exchange.GetMarketPriceCtx(ctx, baseCurr, quoteCurr, strings.ToLower(marketType))
+}
+
+// ---------------------------- POST CALLS ----------------------------
+
+/*
+	Creates a spot order. Size is always in base currency, market buys are
+	converted to the quote amount bybit expects using the best ask price.
+
+	Requires:
+		params exchange.PlaceOrderParams
+
+	Returns:
+		orderId string
+		err error
+*/
+func (client *BybitRestClient) PlaceSpotOrder(params exchange.PlaceOrderParams) (orderId string, err error) {
+	return client.PlaceSpotOrderCtx(context.Background(), params)
+}
+
+// Same as PlaceSpotOrder, cancelled or timed out through ctx
+func (client *BybitRestClient) PlaceSpotOrderCtx(ctx context.Context, params exchange.PlaceOrderParams) (orderId string, err error) {
+	spotParams, err := client.toPlaceSpotOrderParams(ctx, params)
+	if err != nil {
+		return orderId, err
+	}
+
+	return client.Exchange.PlaceSpotOrderCtx(ctx, spotParams)
+}
+
+/*
+	Creates a perp order. Size is always in base currency, inverse contracts
+	are converted to the USD quantity bybit expects. A reduce-only order in
+	hedge mode is checked against the leg it closes, which has to be open and
+	at least as large as the order.
+
+	Requires:
+		params exchange.PlaceOrderParams
+
+	Returns:
+		orderId string
+		err error - wraps ErrReduceOnlyViolation when a reduce-only order doesn't fit its leg
+*/
+func (client *BybitRestClient) PlacePerpOrder(params exchange.PlaceOrderParams) (orderId string, err error) {
+	return client.PlacePerpOrderCtx(context.Background(), params)
+}
+
+// Same as PlacePerpOrder, cancelled or timed out through ctx
+func (client *BybitRestClient) PlacePerpOrderCtx(ctx context.Context, params exchange.PlaceOrderParams) (orderId string, err error) {
+	perpParams, err := client.toPlacePerpOrderParams(ctx, params)
+	if err != nil {
+		return orderId, err
+	}
+
+	return client.Exchange.PlacePerpOrderCtx(ctx, perpParams)
+}
+
+/*
+	Withdraws from exchange. Withdrawals are always made from the SPOT wallet.
+
+	Requires:
+		symbol string - e.g. "BTC"
+		amount float64 - amount to withdraw
+		destinationAddress string - wallet address
+		network string - network name, e.g. "LTC"
+
+	Returns:
+		withdrawOrderId string
+		err error
+*/
+func (client *BybitRestClient) WithdrawFromExchange(symbol string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error) {
+	return client.WithdrawFromExchangeCtx(context.Background(), symbol, amount, destinationAddress, network)
+}
+
+// Same as WithdrawFromExchange, cancelled or timed out through ctx
+func (client *BybitRestClient) WithdrawFromExchangeCtx(ctx context.Context, symbol string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error) {
+	return client.Exchange.WithdrawFromExchangeCtx(ctx, symbol, amount, destinationAddress, network)
+}
+
+// ---------------------------- DELETE CALLS ----------------------------
+
+/*
+	Cancels a spot or perp order.
+
+	Requires:
+		params exchange.CancelOrderParams - ExchangeOrderId is used for perp orders since their ids are not numeric
+
+	Returns:
+		status bool
+		err error
+*/
+func (client *BybitRestClient) CancelOrder(params exchange.CancelOrderParams) (status bool, err error) {
+	return client.CancelOrderCtx(context.Background(), params)
+}
+
+// Same as CancelOrder, cancelled or timed out through ctx
+func (client *BybitRestClient) CancelOrderCtx(ctx context.Context, params exchange.CancelOrderParams) (status bool, err error) {
+	orderId := unifiedOrderId(params.OrderId, params.ExchangeOrderId)
+
+	switch strings.ToLower(params.MarketType) {
+	case exchange.MARKET_TYPE_SPOT:
+		return client.Exchange.CancelSpotOrderCtx(ctx, orderId)
+	case exchange.MARKET_TYPE_PERP:
+		symbol := toBybitSymbol(params.BaseCurrency, params.QuoteCurrency)
+		return client.Exchange.CancelPerpOrderCtx(ctx, symbol, orderId)
+	}
+
+	return false, unsupportedMarketType("CancelOrder", params.MarketType)
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+func (client *BybitRestClient) toPlaceSpotOrderParams(ctx context.Context, params exchange.PlaceOrderParams) (spotParams PlaceSpotOrderParams, err error) {
+	side, err := toBybitSide(params.OrderSide)
+	if err != nil {
+		return spotParams, err
+	}
+
+	spotParams = PlaceSpotOrderParams{
+		Symbol: toBybitSymbol(params.BaseCurrency, params.QuoteCurrency),
+		Qty:    params.Size,
+		Side:   side,
+		Price:  params.Price,
+	}
+	if params.ClientId != nil {
+		spotParams.OrderLinkId = *params.ClientId
+	}
+
+	switch strings.ToLower(params.OrderType) {
+	case exchange.ORDER_TYPE_MARKET:
+		spotParams.Type = SPOT_ORDER_TYPE_MARKET
+		spotParams.Price = decimal.Zero
+		// bybit expects market buy quantity in quote currency
+		if side == ORDER_SIDE_BUY {
+			bookTicker, err := client.Exchange.GetSpotBookTickerCtx(ctx, spotParams.Symbol)
+			if err != nil {
+				return spotParams, err
+			}
+			askPrice, err := decimal.NewFromString(bookTicker.AskPrice)
+			if err != nil {
+				return spotParams, err
+			}
+			spotParams.Qty = params.Size.Mul(askPrice)
+		}
+
+	case exchange.ORDER_TYPE_LIMIT:
+		spotParams.Type = SPOT_ORDER_TYPE_LIMIT
+		spotParams.TimeInForce = SPOT_GTC
+		if params.PostOnly {
+			spotParams.Type = SPOT_ORDER_TYPE_LIMIT_MAKER
+		} else if params.Ioc {
+			spotParams.TimeInForce = SPOT_IOC
+		}
+
+	default:
+		return spotParams, fmt.Errorf("PlaceSpotOrder failed: unsupported order type %v", params.OrderType)
+	}
+
+	return spotParams, nil
+}
+
+func (client *BybitRestClient) toPlacePerpOrderParams(ctx context.Context, params exchange.PlaceOrderParams) (perpParams PlacePerpOrderParams, err error) {
+	side, err := toBybitSide(params.OrderSide)
+	if err != nil {
+		return perpParams, err
+	}
+
+	perpParams = PlacePerpOrderParams{
+		Side:        side,
+		Symbol:      toBybitSymbol(params.BaseCurrency, params.QuoteCurrency),
+		Qty:         params.Size,
+		Price:       params.Price,
+		TimeInForce: PLACE_PERP_GTC,
+		ReduceOnly:  params.ReduceOnly,
+	}
+	if params.ClientId != nil {
+		perpParams.OrderLinkId = *params.ClientId
+	}
+	if perpParams.PositionIdx, err = toPositionIdx(params.PositionSide); err != nil {
+		return perpParams, err
+	}
+	// in hedge mode reduce-only is checked against the leg, not the net position
+	if params.ReduceOnly {
+		if err = checkReduceOnlyLeg(perpParams.Symbol, side == ORDER_SIDE_BUY, perpParams.PositionIdx); err != nil {
+			return perpParams, err
+		}
+	}
+
+	switch strings.ToLower(params.OrderType) {
+	case exchange.ORDER_TYPE_MARKET:
+		perpParams.OrderType = PLACE_PERP_MARKET
+		perpParams.Price = decimal.Zero
+	case exchange.ORDER_TYPE_LIMIT:
+		perpParams.OrderType = PLACE_PERP_LIMIT
+		if params.PostOnly {
+			perpParams.TimeInForce = PLACE_PERP_POST_ONLY
+		} else if params.Ioc {
+			perpParams.TimeInForce = PLACE_PERP_IMMEDIATE_OR_CANCEL
+		}
+	default:
+		return perpParams, fmt.Errorf("PlacePerpOrder failed: unsupported order type %v", params.OrderType)
+	}
+
+	// inverse contracts are quoted in whole USD
+	isInverse, err := client.Exchange.IsInversePerpCtx(ctx, perpParams.Symbol)
+	if err != nil {
+		return perpParams, err
+	}
+	if isInverse {
+		price := params.Price
+		if perpParams.OrderType == PLACE_PERP_MARKET || price.IsZero() {
+			price, err = client.Exchange.GetPerpMarketPriceDecimalCtx(ctx, perpParams.Symbol)
+			if err != nil {
+				return perpParams, err
+			}
+		}
+		perpParams.Qty = decimal.Max(decimal.NewFromInt(1), params.Size.Mul(price).Round(0))
+	}
+
+	if params.ReduceOnly && perpParams.PositionIdx != POSITION_IDX_ONE_WAY {
+		if err = client.checkReduceOnlyLegSize(ctx, perpParams); err != nil {
+			return perpParams, err
+		}
+	}
+	return perpParams, nil
+}
+
+// reads the hedge leg a reduce-only order closes, a flat leg or one smaller than the order can't take it
+func (client *BybitRestClient) checkReduceOnlyLegSize(ctx context.Context, perpParams PlacePerpOrderParams) error {
+	positions, err := client.Exchange.GetPerpPositionsCtx(ctx, perpParams.Symbol)
+	if err != nil {
+		return err
+	}
+	legSize := decimal.Zero
+	for _, leg := range positions {
+		if leg.PositionIdx == perpParams.PositionIdx {
+			legSize = leg.Size
+		}
+	}
+	if legSize.LessThan(perpParams.Qty) {
+		return fmt.Errorf("PlacePerpOrder failed: reduce-only qty %v is more than the %v position_idx %v leg holds (%v): %w", perpParams.Qty, perpParams.Symbol, perpParams.PositionIdx, legSize, ErrReduceOnlyViolation)
+	}
+	return nil
+}
+
+// Converts to the unified order
+func (spotOrder SpotOrder) ToOrder() exchange.Order {
+	return spotOrderToOrder(spotOrder)
+}
+
+// Converts to the unified fill
+func (spotTrade SpotTrade) ToFill() exchange.Fill {
+	return spotTradeToFill(spotTrade)
+}
+
+// Converts to the unified fill, sized in the base coin when listed through GetPerpExecutions
+func (execution PerpExecution) ToFill() exchange.Fill {
+	return perpExecutionToFill(execution)
+}
+
+func spotOrderToOrder(spotOrder SpotOrder) exchange.Order {
+	order := exchange.Order{
+		ClientID:        spotOrder.OrderLinkId,
+		Status:          toUnifiedOrderStatus(spotOrder.Status),
+		Type:            toUnifiedOrderType(spotOrder.Type),
+		Market:          spotOrder.Symbol,
+		Side:            strings.ToLower(spotOrder.Side),
+		Price:           parseFloat(spotOrder.Price),
+		AvgFillPrice:    parseFloat(spotOrder.AvgPrice),
+		Size:            parseFloat(spotOrder.OrigQty),
+		FilledSize:      parseFloat(spotOrder.ExecutedQty),
+		ExchangeOrderId: spotOrder.OrderId,
+		Ioc:             spotOrder.TimeInForce == SPOT_IOC,
+		PostOnly:        spotOrder.Type == SPOT_ORDER_TYPE_LIMIT_MAKER,
+		CreatedAt:       parseMillis(spotOrder.Time),
+	}
+	order.RemainingSize = order.Size - order.FilledSize
+	order.ID, _ = strconv.Atoi(spotOrder.OrderId)
+
+	return order
+}
+
+func spotTradeToFill(spotTrade SpotTrade) exchange.Fill {
+	fill := exchange.Fill{
+		Market:      spotTrade.Symbol,
+		Type:        "order",
+		Liquidity:   "taker",
+		FeeCurrency: spotTrade.CommissionAsset,
+		Side:        exchange.ORDER_SIDE_SELL,
+		Price:       spotTrade.Price.InexactFloat64(),
+		Size:        spotTrade.Qty.InexactFloat64(),
+		Fee:         spotTrade.Commission.InexactFloat64(),
+		Time:        parseMillis(spotTrade.Time),
+	}
+	if spotTrade.IsMaker {
+		fill.Liquidity = "maker"
+	}
+	if spotTrade.IsBuyer {
+		fill.Side = exchange.ORDER_SIDE_BUY
+	}
+	fill.ID = spotTrade.Id
+	fill.OrderID = spotTrade.OrderId
+	fill.TradeID = spotTrade.TicketId
+
+	return fill
+}
+
+func perpExecutionToFill(execution PerpExecution) exchange.Fill {
+	price := execution.ExecPrice.InexactFloat64()
+	fill := exchange.Fill{
+		Future:      execution.Symbol,
+		Market:      execution.Symbol,
+		Type:        strings.ToLower(execution.ExecType),
+		Liquidity:   "taker",
+		FeeCurrency: settleCoin(execution.Symbol),
+		Side:        strings.ToLower(execution.Side),
+		Price:       price,
+		Size:        contractsToBase(execution.Inverse, execution.ExecQty.InexactFloat64(), price),
+		Fee:         execution.ExecFee.InexactFloat64(),
+		FeeRate:     execution.FeeRate.InexactFloat64(),
+		Time:        time.UnixMilli(execution.TradeTimeMs),
+		ID:          execution.ExecId,
+		OrderID:     execution.OrderId,
+		TradeID:     execution.ExecId,
+	}
+	if execution.LastLiquidityInd == "AddedLiquidity" {
+		fill.Liquidity = "maker"
+	}
+
+	return fill
+}
+
+func perpOrderToOrder(perpOrder PerpOrder, isInverse bool) exchange.Order {
+	price := parseFloat(perpOrder.Price)
+	order := exchange.Order{
+		ClientID:        perpOrder.OrderLinkId,
+		Status:          toUnifiedOrderStatus(perpOrder.OrderStatus),
+		Type:            toUnifiedOrderType(perpOrder.OrderType),
+		Future:          perpOrder.Symbol,
+		Market:          perpOrder.Symbol,
+		Side:            strings.ToLower(perpOrder.Side),
+		Price:           price,
+		Size:            contractsToBase(isInverse, parseFloat(perpOrder.Qty), price),
+		RemainingSize:   contractsToBase(isInverse, parseFloat(perpOrder.LeavesQty), price),
+		FilledSize:      contractsToBase(isInverse, parseFloat(perpOrder.CumExecQty), price),
+		ExchangeOrderId: perpOrder.OrderId,
+		Ioc:             perpOrder.TimeInForce == PLACE_PERP_IMMEDIATE_OR_CANCEL,
+		PostOnly:        perpOrder.TimeInForce == PLACE_PERP_POST_ONLY,
+	}
+	order.CreatedAt, _ = time.Parse(time.RFC3339, perpOrder.CreatedAt)
+
+	return order
+}
+
+func perpPositionToPosition(perpPosition PerpPosition, isInverse bool) exchange.Position {
+	entryPrice := perpPosition.EntryPrice.InexactFloat64()
+
+	// inverse position size is in USD, its value is in the base coin
+	size := perpPosition.Size.InexactFloat64()
+	if isInverse {
+		size = perpPosition.PositionValue.InexactFloat64()
+	}
+	netSize := size
+	if perpPosition.Side == ORDER_SIDE_SELL {
+		netSize = -size
+	}
+
+	position := exchange.Position{
+		Future:                    perpPosition.Symbol,
+		Side:                      toUnifiedSide(perpPosition.Side),
+		EntryPrice:                entryPrice,
+		EstimatedLiquidationPrice: perpPosition.LiqPrice.InexactFloat64(),
+		Size:                      size,
+		NetSize:                   netSize,
+		Cost:                      netSize * entryPrice,
+		UnrealizedPnl:             perpPosition.UnrealisedPnl.InexactFloat64(),
+		RealizedPnl:               perpPosition.RealisedPnl.InexactFloat64(),
+		CollateralUsed:            perpPosition.PositionMargin.InexactFloat64(),
+	}
+	if perpPosition.Leverage.IsPositive() {
+		position.InitialMarginRequirement = decimal.NewFromInt(1).Div(perpPosition.Leverage).InexactFloat64()
+	}
+
+	return position
+}
+
+func toBybitSymbol(baseCurr, quoteCurr string) string {
+	return strings.ToUpper(baseCurr + quoteCurr)
+}
+
+func toBybitContract(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if strings.HasSuffix(symbol, quote) && symbol != quote {
+			return symbol
+		}
+	}
+	return symbol + "USD"
+}
+
+func toBybitSide(side string) (string, error) {
+	switch strings.ToLower(side) {
+	case exchange.ORDER_SIDE_BUY:
+		return ORDER_SIDE_BUY, nil
+	case exchange.ORDER_SIDE_SELL:
+		return ORDER_SIDE_SELL, nil
+	}
+	return "", fmt.Errorf("unsupported order side %v", side)
+}
+
+// one-way mode without a position side, hedge mode legs otherwise
+func toPositionIdx(positionSide string) (int, error) {
+	switch strings.ToLower(positionSide) {
+	case "":
+		return POSITION_IDX_ONE_WAY, nil
+	case exchange.POSITION_SIDE_LONG:
+		return POSITION_IDX_HEDGE_BUY, nil
+	case exchange.POSITION_SIDE_SHORT:
+		return POSITION_IDX_HEDGE_SELL, nil
+	}
+	return POSITION_IDX_ONE_WAY, fmt.Errorf("unsupported position side %v", positionSide)
+}
+
+func toUnifiedSide(side string) string {
+	switch side {
+	case ORDER_SIDE_BUY:
+		return exchange.ORDER_SIDE_BUY
+	case ORDER_SIDE_SELL:
+		return exchange.ORDER_SIDE_SELL
+	}
+	return ""
+}
+
+func toUnifiedOrderType(orderType string) string {
+	switch strings.ToUpper(orderType) {
+	case SPOT_ORDER_TYPE_MARKET:
+		return exchange.ORDER_TYPE_MARKET
+	case SPOT_ORDER_TYPE_LIMIT, SPOT_ORDER_TYPE_LIMIT_MAKER:
+		return exchange.ORDER_TYPE_LIMIT
+	}
+	return strings.ToLower(orderType)
+}
+
+// maps spot (NEW, PARTIALLY_FILLED, ...) and perp (Created, PartiallyFilled, ...) statuses
+func toUnifiedOrderStatus(status string) string {
+	switch status {
+	case "NEW", "PENDING_NEW", "PENDING_CANCEL", "Created", "New", "Untriggered", "Triggered", "Active", "PendingCancel":
+		return exchange.ORDER_STATUS_NEW
+	case "PARTIALLY_FILLED", "PartiallyFilled":
+		return exchange.ORDER_STATUS_PARTIALLY_FILLED
+	case "FILLED", "Filled":
+		return exchange.ORDER_STATUS_FILLED
+	case "CANCELED", "Cancelled", "Deactivated":
+		return exchange.ORDER_STATUS_CANCELED
+	case "REJECTED", "Rejected":
+		return exchange.ORDER_STATUS_REJECTED
+	}
+	return status
+}
+
+func unifiedOrderId(orderId int64, exchangeOrderId string) string {
+	if exchangeOrderId != "" {
+		return exchangeOrderId
+	}
+	return strconv.FormatInt(orderId, 10)
+}
+
+func unsupportedMarketType(functionName, marketType string) error {
+	return errors.New(fmt.Sprintf("%v failed: unsupported market type %v", functionName, marketType))
+}
+
+// the coin a perp is margined and charged fees in, e.g. "USDT" for BTCUSDT and "BTC" for BTCUSD or BTCUSDZ22
+func settleCoin(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	for _, quote := range []string{"USDT", "USDC"} {
+		if strings.HasSuffix(symbol, quote) {
+			return quote
+		}
+	}
+	if i := strings.Index(symbol, "USD"); i > 0 {
+		return symbol[:i]
+	}
+	return ""
+}
+
+func isStablecoin(coin string) bool {
+	return coin == "USDT" || coin == "USDC" || coin == "USD"
+}
+
+func contractsToBase(isInverse bool, qty, price float64) float64 {
+	if isInverse && price > 0 {
+		return qty / price
+	}
+	return qty
+}
+
+func parseFloat(value string) float64 {
+	f, _ := strconv.ParseFloat(value, 64)
+	return f
+}
+
+func parseMillis(value string) time.Time {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,205 @@
+package bybit_exchange
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	mathrand "math/rand"
+	"time"
+)
+
+// How failed requests are retried. Reads are retried on transport errors, 5xx
+// and transient ret_codes; order placements are resolved by order link id
+// before any resend; other writes are only resent when bybit rejected them
+// before processing (rate limited, expired timestamp).
+type RetryPolicy struct {
+	MaxAttempts int           // attempts per request including the first, 1 disables retries
+	BaseDelay   time.Duration // delay before the first retry, doubled on every following one
+	MaxDelay    time.Duration // upper bound of a single delay
+}
+
+// Returns the retry policy clients are constructed with
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: DEFAULT_RETRY_ATTEMPTS,
+		BaseDelay:   DEFAULT_RETRY_BASE_DELAY,
+		MaxDelay:    DEFAULT_RETRY_MAX_DELAY,
+	}
+}
+
+// Returns the delay before the given retry (starting at 1): exponential
+// backoff with the upper half jittered so concurrent retries spread out
+func (policy RetryPolicy) Backoff(retry int) time.Duration {
+	delay := float64(policy.BaseDelay) * math.Pow(2, float64(retry-1))
+	if policy.MaxDelay > 0 {
+		delay = math.Min(delay, float64(policy.MaxDelay))
+	}
+	return time.Duration(delay/2 + mathrand.Float64()*delay/2)
+}
+
+// Network level failure, the request may or may not have reached bybit
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// Failure before the request left the client, e.g. the clock couldn't be synced
+type notSentError struct {
+	err error
+}
+
+func (e *notSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notSentError) Unwrap() error {
+	return e.err
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// sends the request, retrying it as far as its idempotency allows
+func (bybit *BybitExchange) sendRequest(ctx context.Context, functionName string, req *apiRequest, response apiResponder) (err error) {
+	for attempt := 1; ; attempt++ {
+		err = bybit.sendOnce(ctx, functionName, req, response)
+		if err == nil || attempt >= bybit.retryPolicy.MaxAttempts {
+			return err
+		}
+		if !isSafeToResend(err) && !(req.idempotent && isAmbiguous(err)) {
+			return err
+		}
+		if bybit.waitBeforeRetry(ctx, functionName, attempt, err) != nil {
+			return err
+		}
+	}
+}
+
+// places an order at most once. A placement that failed ambiguously is looked up
+// by its order link id, after the last attempt too, and only resent once bybit
+// reports it doesn't exist. A resend rejected as a duplicate link id means the
+// lookup lagged and an earlier attempt went through, so it's looked up again.
+func (bybit *BybitExchange) placeOrder(ctx context.Context, functionName, orderLinkId string, req *apiRequest, response apiResponder,
+	placedOrderId func() string, lookup func(ctx context.Context) (orderId string, err error)) (orderId string, err error) {
+
+	for attempt := 1; ; attempt++ {
+		err = bybit.sendOnce(ctx, functionName, req, response)
+		if err == nil {
+			return placedOrderId(), nil
+		}
+		// an earlier attempt went through after all, its lookup only lagged behind
+		if attempt > 1 && errors.Is(err, ErrDuplicateOrderLinkId) {
+			if foundOrderId, lookupErr := lookup(ctx); lookupErr == nil {
+				return foundOrderId, nil
+			}
+			return orderId, fmt.Errorf("%v order was placed, check order link id %v: %w", functionName, orderLinkId, err)
+		}
+		if !isSafeToResend(err) && !isAmbiguous(err) {
+			return orderId, err
+		}
+
+		// the backoff also gives an order that did go through time to show up
+		lastAttempt := attempt >= bybit.retryPolicy.MaxAttempts
+		var waitErr error
+		if !lastAttempt {
+			waitErr = bybit.waitBeforeRetry(ctx, functionName, attempt, err)
+		} else if isAmbiguous(err) {
+			waitErr = sleepCtx(ctx, bybit.retryPolicy.Backoff(attempt))
+		}
+		if waitErr != nil {
+			return orderId, orderStateUnknown(functionName, orderLinkId, err)
+		}
+
+		if isAmbiguous(err) {
+			foundOrderId, lookupErr := lookup(ctx)
+			if lookupErr == nil {
+				return foundOrderId, nil
+			}
+			if !errors.Is(lookupErr, ErrOrderNotFound) {
+				return orderId, orderStateUnknown(functionName, orderLinkId, fmt.Errorf("lookup failed: %v, placement failed: %w", lookupErr, err))
+			}
+		}
+		if lastAttempt {
+			return orderId, orderStateUnknown(functionName, orderLinkId, err)
+		}
+	}
+}
+
+// tells the caller which order link id to check when a placement may or may not exist
+func orderStateUnknown(functionName, orderLinkId string, err error) error {
+	if !isAmbiguous(err) {
+		return err
+	}
+	return fmt.Errorf("%v order state unknown, check order link id %v: %w", functionName, orderLinkId, err)
+}
+
+func (bybit *BybitExchange) waitBeforeRetry(ctx context.Context, functionName string, attempt int, err error) error {
+	// re-measure the clock offset before signing again
+	if errors.Is(err, ErrInvalidTimestamp) {
+		if syncErr := bybit.timeSync.Sync(ctx); syncErr != nil {
+			return syncErr
+		}
+	}
+
+	delay := bybit.retryPolicy.Backoff(attempt)
+	bybit.logger.Warn(fmt.Sprintf("%v attempt %v failed, retrying in %v: %v", functionName, attempt, delay, err))
+	return sleepCtx(ctx, delay)
+}
+
+// waits for delay, or until ctx is done
+func sleepCtx(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// the request never reached bybit or was refused before bybit acted on it,
+// so resending can't duplicate it
+func isSafeToResend(err error) bool {
+	var notSentErr *notSentError
+	if errors.As(err, &notSentErr) {
+		// only worth resending when what stopped it was transient
+		return isAmbiguous(notSentErr.err) || isSafeToResend(notSentErr.err)
+	}
+
+	apiErr, ok := AsAPIError(err)
+	return ok && (errors.Is(apiErr, ErrRateLimited) || errors.Is(apiErr, ErrInvalidTimestamp))
+}
+
+// the request may have been processed, e.g. the connection dropped before the response arrived
+func isAmbiguous(err error) bool {
+	var notSentErr *notSentError
+	if errors.As(err, &notSentErr) {
+		return false
+	}
+
+	var transportErr *transportError
+	if errors.As(err, &transportErr) {
+		// our own cancellation is final, not transient
+		return !errors.Is(err, context.Canceled)
+	}
+	return errors.Is(err, ErrServerError)
+}
+
+func newOrderLinkId() string {
+	b := make([]byte, ORDER_LINK_ID_BYTES)
+	if _, err := rand.Read(b); err != nil {
+		// fall back to the clock, still unique enough for a single client
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
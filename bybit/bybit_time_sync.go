@@ -0,0 +1,222 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Keeps the offset between the local clock and bybit server time, so signed
+// request timestamps are computed locally instead of asking the server each time.
+// Safe for concurrent use.
+type TimeSync struct {
+	fetch    func(ctx context.Context) (serverTime float64, err error)
+	interval time.Duration
+	logger   *zap.Logger
+
+	// serialises syncs so concurrent signers don't all hit the server
+	syncMu sync.Mutex
+
+	mu       sync.RWMutex
+	stats    TimeSyncStats
+	cancel   context.CancelFunc
+	isSynced bool
+}
+
+// Clock offset and drift metrics of a TimeSync
+type TimeSyncStats struct {
+	Offset   time.Duration // server time minus local time
+	RTT      time.Duration // round trip of the sample the offset was taken from
+	Drift    time.Duration // offset change between the last two syncs
+	MaxDrift time.Duration // largest absolute drift seen
+	LastSync time.Time     // local time of the last successful sync
+	Syncs    int           // successful syncs
+	Failures int           // failed syncs
+}
+
+func newTimeSync(fetch func(ctx context.Context) (float64, error), interval time.Duration, logger *zap.Logger) *TimeSync {
+	return &TimeSync{
+		fetch:    fetch,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+/*
+	Measures the offset to bybit server time. Takes several samples and keeps the one
+	with the shortest round trip, assuming the server stamped it halfway through.
+
+	Requires:
+		ctx context.Context
+
+	Returns:
+		err error
+*/
+func (ts *TimeSync) Sync(ctx context.Context) error {
+	ts.syncMu.Lock()
+	defer ts.syncMu.Unlock()
+
+	return ts.sync(ctx)
+}
+
+/*
+	Starts refreshing the offset in the background every interval until ctx is done
+	or Stop is called. Calling Start on a running TimeSync is a no-op.
+
+	Requires:
+		ctx context.Context
+*/
+func (ts *TimeSync) Start(ctx context.Context) {
+	ts.mu.Lock()
+	if ts.cancel != nil {
+		ts.mu.Unlock()
+		return
+	}
+	ctx, ts.cancel = context.WithCancel(ctx)
+	ts.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(ts.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := ts.Sync(ctx); err != nil && ctx.Err() == nil {
+				ts.logger.Warn("bybit time sync failed", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stops the background refresh started by Start
+func (ts *TimeSync) Stop() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.cancel != nil {
+		ts.cancel()
+		ts.cancel = nil
+	}
+}
+
+// Returns current offset and drift metrics
+func (ts *TimeSync) Stats() TimeSyncStats {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	return ts.stats
+}
+
+// Returns estimated bybit server time, zero offset until the first sync
+func (ts *TimeSync) Now() time.Time {
+	return time.Now().Add(ts.Stats().Offset)
+}
+
+/*
+	Returns the timestamp (in milliseconds) to sign a request with. Syncs first when
+	the offset was never measured or has not been refreshed for two intervals.
+
+	Requires:
+		ctx context.Context
+
+	Returns:
+		timestamp int64
+		err error
+*/
+func (ts *TimeSync) Timestamp(ctx context.Context) (timestamp int64, err error) {
+	if ts.isStale() {
+		ts.syncMu.Lock()
+		// another signer may have synced while we waited
+		if ts.isStale() {
+			err = ts.sync(ctx)
+		}
+		ts.syncMu.Unlock()
+
+		if err != nil {
+			ts.mu.RLock()
+			isSynced := ts.isSynced
+			ts.mu.RUnlock()
+
+			// a previous offset is still better than failing the request
+			if !isSynced {
+				return timestamp, err
+			}
+			ts.logger.Warn("bybit time sync failed, using previous offset", zap.Error(err))
+		}
+	}
+
+	// timestamp must be (in milliseconds): serverTime - recvWindow (default 5,000) <= timestamp < serverTime + 1000
+	return ts.Now().Add(-TIMESTAMP_SAFETY_MARGIN).UnixMilli(), nil
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+func (ts *TimeSync) sync(ctx context.Context) error {
+	var bestOffset, bestRTT time.Duration
+	var lastErr error
+	samples := 0
+
+	for i := 0; i < TIME_SYNC_SAMPLES; i++ {
+		start := time.Now()
+		serverTime, err := ts.fetch(ctx)
+		rtt := time.Since(start)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		serverNow := time.Unix(0, int64(serverTime*float64(time.Second)))
+		offset := serverNow.Sub(start.Add(rtt / 2))
+		if samples == 0 || rtt < bestRTT {
+			bestOffset, bestRTT = offset, rtt
+		}
+		samples++
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if samples == 0 {
+		ts.stats.Failures++
+		return fmt.Errorf("bybit time sync failed: %w", lastErr)
+	}
+
+	if ts.isSynced {
+		ts.stats.Drift = bestOffset - ts.stats.Offset
+		if absDuration(ts.stats.Drift) > ts.stats.MaxDrift {
+			ts.stats.MaxDrift = absDuration(ts.stats.Drift)
+		}
+	}
+	ts.stats.Offset = bestOffset
+	ts.stats.RTT = bestRTT
+	ts.stats.LastSync = time.Now()
+	ts.stats.Syncs++
+	ts.isSynced = true
+
+	return nil
+}
+
+func (ts *TimeSync) isStale() bool {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	return !ts.isSynced || time.Since(ts.stats.LastSync) > 2*ts.interval
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
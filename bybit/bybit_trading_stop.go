@@ -0,0 +1,137 @@
+package bybit_exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+/*
+	Sets, moves or removes the take profit, stop loss and trailing stop of an
+	open perp position. Prices left nil keep their current value, zero ones
+	are removed. In TP_SL_MODE_PARTIAL the take profit and stop loss can close
+	part of the position through TpSize and SlSize.
+
+	Requires:
+		params TradingStopParams
+
+	Returns:
+		position PerpPosition - the position once updated
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-tradingstop
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/linear/#t-tradingstop
+*/
+func (bybit *BybitExchange) SetTradingStop(params TradingStopParams) (position PerpPosition, err error) {
+	return bybit.SetTradingStopCtx(context.Background(), params)
+}
+
+// Same as SetTradingStop, cancelled or timed out through ctx
+func (bybit *BybitExchange) SetTradingStopCtx(ctx context.Context, params TradingStopParams) (position PerpPosition, err error) {
+	functionName := "SetTradingStop"
+	requestParams, err := tradingStopParams(functionName, params)
+	if err != nil {
+		return position, err
+	}
+	isLinear, err := bybit.isLinear(ctx, params.Symbol)
+	if err != nil {
+		return position, err
+	}
+
+	if isLinear {
+		side := params.Side
+		if side == "" {
+			if side, err = bybit.openLinearSide(ctx, functionName, params.Symbol); err != nil {
+				return position, err
+			}
+		}
+		requestParams["side"] = side
+
+		if err = bybit.sendTradingStop(ctx, functionName, SET_LINEAR_TRADING_STOP, requestParams, new(ResponseForSetTradingStop)); err != nil {
+			return position, err
+		}
+		positions, err := bybit.getLinearPositions(ctx, functionName, params.Symbol)
+		if err != nil {
+			return position, err
+		}
+		for _, listed := range positions {
+			if listed.Side == side {
+				return listed, nil
+			}
+		}
+		return position, fmt.Errorf("%v failed: no %v %v position listed", functionName, side, params.Symbol)
+	}
+
+	var response = new(ResponseForSetTradingStop)
+	if err = bybit.sendTradingStop(ctx, functionName, SET_PERP_TRADING_STOP, requestParams, response); err != nil {
+		return position, err
+	}
+	if response.Result.Symbol == "" {
+		// already set, bybit sent no position back
+		return bybit.GetPerpPositionCtx(ctx, params.Symbol)
+	}
+	return response.Result, nil
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// the same stops can be set twice, bybit answering that they already hold counts as success
+func (bybit *BybitExchange) sendTradingStop(ctx context.Context, functionName, endpoint string, params map[string]interface{}, response *ResponseForSetTradingStop) error {
+	req := newSignedRequest(http.MethodPost, endpoint, params)
+	req.idempotent = true
+
+	if err := bybit.sendRequest(ctx, functionName, req, response); err != nil && !errors.Is(err, ErrNotModified) {
+		return err
+	}
+	return nil
+}
+
+func tradingStopParams(functionName string, params TradingStopParams) (requestParams map[string]interface{}, err error) {
+	if params.TakeProfit == nil && params.StopLoss == nil && params.TrailingStop == nil {
+		return requestParams, fmt.Errorf("%v failed: no take profit, stop loss or trailing stop", functionName)
+	}
+
+	requestParams = map[string]interface{}{}
+	requestParams["symbol"] = params.Symbol
+	stops := []struct {
+		key   string
+		price *decimal.Decimal
+	}{
+		{"take_profit", params.TakeProfit},
+		{"stop_loss", params.StopLoss},
+		{"trailing_stop", params.TrailingStop},
+	}
+	for _, stop := range stops {
+		if stop.price == nil {
+			continue
+		}
+		if stop.price.IsNegative() {
+			return requestParams, fmt.Errorf("%v failed: negative %v %v", functionName, stop.key, stop.price)
+		}
+		requestParams[stop.key] = stop.price.String()
+	}
+
+	for key, triggerBy := range map[string]string{"tp_trigger_by": params.TpTriggerBy, "sl_trigger_by": params.SlTriggerBy} {
+		switch triggerBy {
+		case "":
+		case TRIGGER_BY_LAST_PRICE, TRIGGER_BY_MARK_PRICE, TRIGGER_BY_INDEX_PRICE:
+			requestParams[key] = triggerBy
+		default:
+			return requestParams, fmt.Errorf("%v failed: invalid %v %q", functionName, key, triggerBy)
+		}
+	}
+
+	if params.TpSize.IsNegative() || params.SlSize.IsNegative() {
+		return requestParams, fmt.Errorf("%v failed: negative tp/sl size", functionName)
+	}
+	if params.TpSize.IsPositive() {
+		requestParams["tp_size"] = params.TpSize.String()
+	}
+	if params.SlSize.IsPositive() {
+		requestParams["sl_size"] = params.SlSize.String()
+	}
+	return requestParams, nil
+}
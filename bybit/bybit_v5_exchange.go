@@ -0,0 +1,1480 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
+	"github.com/fatih/structs"
+	"github.com/shopspring/decimal"
+)
+
+// Bybit V5 unified API rest client. Offers the high-level methods of
+// BybitExchange on the /v5 endpoints, returning the same types, plus
+// category-aware calls covering spot, linear, inverse and option.
+// Requests share BybitExchange's engine: host failover, rate limiting, clock
+// sync and retries. Safe for concurrent use by many goroutines.
+type BybitV5Exchange struct {
+	bybit *BybitExchange
+}
+
+// fails compilation if either client stops serving the unified rest client
+var (
+	_ BybitClient = (*BybitExchange)(nil)
+	_ BybitClient = (*BybitV5Exchange)(nil)
+)
+
+// Generates new bybit V5 exchange client, defaults to testnet.
+// Requires: Secret and Api keys
+// Optional: the options of NewBybitExchange, and WithUnifiedAccount for unified trading accounts
+func NewBybitV5Exchange(secretKey, apiKey string, opts ...Option) *BybitV5Exchange {
+	v5 := &BybitV5Exchange{bybit: NewBybitExchange(secretKey, apiKey, opts...)}
+
+	// keep the clock, host probes and instruments off the deprecated endpoints
+	bybit := v5.bybit
+	bybit.serverTimePath = V5_SERVER_TIME
+	bybit.timeSync = newTimeSync(v5.GetServerTimeCtx, bybit.timeSyncInterval, bybit.logger)
+	bybit.instruments = newInstrumentRegistry(v5.loadInstruments, bybit.instrumentTTL, bybit.logger)
+	return v5
+}
+
+// Returns the clock synchronizer used to timestamp signed requests.
+// Call Start on it to refresh the offset in the background.
+func (v5 *BybitV5Exchange) TimeSync() *TimeSync {
+	return v5.bybit.timeSync
+}
+
+// Returns the rate limiter requests wait on, e.g. to inspect a group's Status
+func (v5 *BybitV5Exchange) RateLimiter() *RateLimiter {
+	return v5.bybit.rateLimiter
+}
+
+// Returns the pool of hosts requests are routed across.
+// Call Start on it to health check and latency probe them in the background.
+func (v5 *BybitV5Exchange) HostPool() *HostPool {
+	return v5.bybit.hostPool
+}
+
+// Returns the host requests are currently sent to
+func (v5 *BybitV5Exchange) ActiveHost() string {
+	return v5.bybit.ActiveHost()
+}
+
+// Returns the cached spot and perp instruments, loaded on first use.
+// Call Start on it to refresh them in the background.
+func (v5 *BybitV5Exchange) Instruments() *InstrumentRegistry {
+	return v5.bybit.instruments
+}
+
+// ---------------------------- GETTERS ----------------------------
+
+/*
+	Gets tickers of a category.
+
+	Requires:
+		category string - CATEGORY_SPOT, CATEGORY_LINEAR, CATEGORY_INVERSE or CATEGORY_OPTION
+		symbol string - e.g. "BTCUSDT", "" for every symbol (not supported for options)
+
+	Returns:
+		tickers []V5Ticker
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/tickers
+*/
+func (v5 *BybitV5Exchange) GetTickers(category, symbol string) (tickers []V5Ticker, err error) {
+	return v5.GetTickersCtx(context.Background(), category, symbol)
+}
+
+// Same as GetTickers, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetTickersCtx(ctx context.Context, category, symbol string) (tickers []V5Ticker, err error) {
+	functionName := "GetTickers"
+	params := map[string]interface{}{}
+	params["category"] = category
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+
+	req := newPublicRequest(http.MethodGet, V5_TICKERS, params)
+	var response = new(ResponseForV5Tickers)
+	if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return tickers, err
+	}
+
+	return response.Result.List, nil
+}
+
+/*
+	Gets the trading rules of every instrument of a category, following every page.
+	Prefer Instruments() which caches them.
+
+	Requires:
+		category string - CATEGORY_SPOT, CATEGORY_LINEAR, CATEGORY_INVERSE or CATEGORY_OPTION
+
+	Returns:
+		instruments []V5InstrumentInfo
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/market/instrument
+*/
+func (v5 *BybitV5Exchange) GetInstrumentsInfo(category string) (instruments []V5InstrumentInfo, err error) {
+	return v5.GetInstrumentsInfoCtx(context.Background(), category)
+}
+
+// Same as GetInstrumentsInfo, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetInstrumentsInfoCtx(ctx context.Context, category string) (instruments []V5InstrumentInfo, err error) {
+	functionName := "GetInstrumentsInfo"
+	cursor := ""
+
+	for {
+		params := map[string]interface{}{}
+		params["category"] = category
+		params["limit"] = V5_INSTRUMENTS_PAGE_SIZE
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		req := newPublicRequest(http.MethodGet, V5_INSTRUMENTS_INFO, params)
+		var response = new(ResponseForV5InstrumentsInfo)
+		if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+			return instruments, err
+		}
+
+		instruments = append(instruments, response.Result.List...)
+		if response.Result.NextPageCursor == "" || response.Result.NextPageCursor == cursor {
+			return instruments, nil
+		}
+		cursor = response.Result.NextPageCursor
+	}
+}
+
+/*
+	Gets the open orders of a category. For derivatives recently closed orders are included.
+
+	Requires:
+		category string - CATEGORY_SPOT, CATEGORY_LINEAR, CATEGORY_INVERSE or CATEGORY_OPTION
+		symbol string - e.g. "BTCUSDT", "" for every spot symbol
+
+	Returns:
+		orders []V5Order
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/open-order
+*/
+func (v5 *BybitV5Exchange) GetOpenOrders(category, symbol string) (orders []V5Order, err error) {
+	return v5.GetOpenOrdersCtx(context.Background(), category, symbol)
+}
+
+// Same as GetOpenOrders, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetOpenOrdersCtx(ctx context.Context, category, symbol string) (orders []V5Order, err error) {
+	return v5.getOrders(ctx, "GetOpenOrders", V5_OPEN_ORDERS, category, symbol, "", "")
+}
+
+/*
+	Gets an open or closed order by order id.
+
+	Requires:
+		category string - CATEGORY_SPOT, CATEGORY_LINEAR, CATEGORY_INVERSE or CATEGORY_OPTION
+		symbol string - e.g. "BTCUSDT", optional for spot
+		orderId string
+
+	Returns:
+		order V5Order
+		err error - wraps ErrOrderNotFound when bybit has no such order
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/order-list
+*/
+func (v5 *BybitV5Exchange) GetOrder(category, symbol, orderId string) (order V5Order, err error) {
+	return v5.GetOrderCtx(context.Background(), category, symbol, orderId)
+}
+
+// Same as GetOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetOrderCtx(ctx context.Context, category, symbol, orderId string) (order V5Order, err error) {
+	return v5.findOrder(ctx, "GetOrder", category, symbol, orderId, "")
+}
+
+/*
+	Gets an open or closed order by the order link id it was placed with.
+
+	Requires:
+		category string - CATEGORY_SPOT, CATEGORY_LINEAR, CATEGORY_INVERSE or CATEGORY_OPTION
+		symbol string - e.g. "BTCUSDT", optional for spot
+		orderLinkId string
+
+	Returns:
+		order V5Order
+		err error - wraps ErrOrderNotFound when no order has this link id
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/order-list
+*/
+func (v5 *BybitV5Exchange) GetOrderByLinkId(category, symbol, orderLinkId string) (order V5Order, err error) {
+	return v5.GetOrderByLinkIdCtx(context.Background(), category, symbol, orderLinkId)
+}
+
+// Same as GetOrderByLinkId, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetOrderByLinkIdCtx(ctx context.Context, category, symbol, orderLinkId string) (order V5Order, err error) {
+	return v5.findOrder(ctx, "GetOrderByLinkId", category, symbol, "", orderLinkId)
+}
+
+/*
+	Gets the positions of a derivatives symbol, one per side in hedge mode.
+
+	Requires:
+		category string - CATEGORY_LINEAR, CATEGORY_INVERSE or CATEGORY_OPTION
+		symbol string - e.g. "BTCUSDT"
+
+	Returns:
+		positions []V5Position
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/position
+*/
+func (v5 *BybitV5Exchange) GetPositions(category, symbol string) (positions []V5Position, err error) {
+	return v5.GetPositionsCtx(context.Background(), category, symbol)
+}
+
+// Same as GetPositions, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPositionsCtx(ctx context.Context, category, symbol string) (positions []V5Position, err error) {
+	functionName := "GetPositions"
+	params := map[string]interface{}{}
+	params["category"] = category
+	params["symbol"] = symbol
+
+	req := newHeaderSignedRequest(http.MethodGet, V5_POSITIONS, params)
+	var response = new(ResponseForV5Positions)
+	if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return positions, err
+	}
+
+	return response.Result.List, nil
+}
+
+/*
+	Gets the balance of a wallet.
+
+	Requires:
+		accountType string - ACCOUNT_TYPE_UNIFIED, ACCOUNT_TYPE_CONTRACT or ACCOUNT_TYPE_SPOT
+
+	Returns:
+		wallet V5WalletBalance
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/account/wallet-balance
+*/
+func (v5 *BybitV5Exchange) GetWalletBalance(accountType string) (wallet V5WalletBalance, err error) {
+	return v5.GetWalletBalanceCtx(context.Background(), accountType)
+}
+
+// Same as GetWalletBalance, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetWalletBalanceCtx(ctx context.Context, accountType string) (wallet V5WalletBalance, err error) {
+	functionName := "GetWalletBalance"
+	params := map[string]interface{}{}
+	params["accountType"] = accountType
+
+	req := newHeaderSignedRequest(http.MethodGet, V5_WALLET_BALANCE, params)
+	var response = new(ResponseForV5WalletBalance)
+	if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return wallet, err
+	}
+
+	if len(response.Result.List) == 0 {
+		return wallet, fmt.Errorf("%v failed: no %v wallet", functionName, accountType)
+	}
+	return response.Result.List[0], nil
+}
+
+/*
+	Gets a spot order
+
+	Requires:
+		orderId string
+
+	Returns:
+		spotOrder SpotOrder
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/order-list
+*/
+func (v5 *BybitV5Exchange) GetSpotOrder(orderId string) (spotOrder SpotOrder, err error) {
+	return v5.GetSpotOrderCtx(context.Background(), orderId)
+}
+
+// Same as GetSpotOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetSpotOrderCtx(ctx context.Context, orderId string) (spotOrder SpotOrder, err error) {
+	order, err := v5.findOrder(ctx, "GetSpotOrder", CATEGORY_SPOT, "", orderId, "")
+	if err != nil {
+		return spotOrder, err
+	}
+	return v5OrderToSpotOrder(order), nil
+}
+
+/*
+	Gets a spot order by the order link id it was placed with
+
+	Requires:
+		orderLinkId string
+
+	Returns:
+		spotOrder SpotOrder
+		err error - wraps ErrOrderNotFound when no order has this link id
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/order-list
+*/
+func (v5 *BybitV5Exchange) GetSpotOrderByLinkId(orderLinkId string) (spotOrder SpotOrder, err error) {
+	return v5.GetSpotOrderByLinkIdCtx(context.Background(), orderLinkId)
+}
+
+// Same as GetSpotOrderByLinkId, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetSpotOrderByLinkIdCtx(ctx context.Context, orderLinkId string) (spotOrder SpotOrder, err error) {
+	order, err := v5.findOrder(ctx, "GetSpotOrderByLinkId", CATEGORY_SPOT, "", "", orderLinkId)
+	if err != nil {
+		return spotOrder, err
+	}
+	return v5OrderToSpotOrder(order), nil
+}
+
+/*
+	Gets the open and recently closed orders of a perp.
+
+	Requires:
+		symbol string - e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+
+	Returns:
+		perpOrders []PerpOrder
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/open-order
+*/
+func (v5 *BybitV5Exchange) GetPerpOrder(symbol string) (perpOrders []PerpOrder, err error) {
+	return v5.GetPerpOrderCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpOrderCtx(ctx context.Context, symbol string) (perpOrders []PerpOrder, err error) {
+	category, err := v5.perpCategory(ctx, symbol)
+	if err != nil {
+		return perpOrders, err
+	}
+	orders, err := v5.getOrders(ctx, "GetPerpOrder", V5_OPEN_ORDERS, category, symbol, "", "")
+	if err != nil {
+		return perpOrders, err
+	}
+
+	for _, order := range orders {
+		perpOrders = append(perpOrders, v5OrderToPerpOrder(order))
+	}
+	return perpOrders, nil
+}
+
+/*
+	Gets a perp order by the order link id it was placed with
+
+	Requires:
+		symbol string
+		orderLinkId string
+
+	Returns:
+		perpOrder PerpOrder
+		err error - wraps ErrOrderNotFound when no order has this link id
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/order-list
+*/
+func (v5 *BybitV5Exchange) GetPerpOrderByLinkId(symbol, orderLinkId string) (perpOrder PerpOrder, err error) {
+	return v5.GetPerpOrderByLinkIdCtx(context.Background(), symbol, orderLinkId)
+}
+
+// Same as GetPerpOrderByLinkId, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpOrderByLinkIdCtx(ctx context.Context, symbol, orderLinkId string) (perpOrder PerpOrder, err error) {
+	category, err := v5.perpCategory(ctx, symbol)
+	if err != nil {
+		return perpOrder, err
+	}
+	order, err := v5.findOrder(ctx, "GetPerpOrderByLinkId", category, symbol, "", orderLinkId)
+	if err != nil {
+		return perpOrder, err
+	}
+	return v5OrderToPerpOrder(order), nil
+}
+
+/*
+	Gets a perp order by its id, whether open or closed
+
+	Requires:
+		symbol string
+		orderId string
+
+	Returns:
+		perpOrder PerpOrder
+		err error - wraps ErrOrderNotFound when no order has this id
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/order-list
+*/
+func (v5 *BybitV5Exchange) GetPerpOrderById(symbol, orderId string) (perpOrder PerpOrder, err error) {
+	return v5.GetPerpOrderByIdCtx(context.Background(), symbol, orderId)
+}
+
+// Same as GetPerpOrderById, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpOrderByIdCtx(ctx context.Context, symbol, orderId string) (perpOrder PerpOrder, err error) {
+	category, err := v5.perpCategory(ctx, symbol)
+	if err != nil {
+		return perpOrder, err
+	}
+	order, err := v5.findOrder(ctx, "GetPerpOrderById", category, symbol, orderId, "")
+	if err != nil {
+		return perpOrder, err
+	}
+	return v5OrderToPerpOrder(order), nil
+}
+
+/*
+	Gets spot wallet balance for a particular symbol.
+
+	Requires:
+		symbol string
+
+	Returns:
+		balance float64
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/account/wallet-balance
+*/
+func (v5 *BybitV5Exchange) GetSpotWalletBalanceForSymbol(symbol string) (balance float64, err error) {
+	return v5.GetSpotWalletBalanceForSymbolCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotWalletBalanceForSymbol, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetSpotWalletBalanceForSymbolCtx(ctx context.Context, symbol string) (balance float64, err error) {
+	free, err := v5.GetSpotWalletBalanceForSymbolDecimalCtx(ctx, symbol)
+	return free.InexactFloat64(), err
+}
+
+// Same as GetSpotWalletBalanceForSymbol, returning the exact balance
+func (v5 *BybitV5Exchange) GetSpotWalletBalanceForSymbolDecimal(symbol string) (balance decimal.Decimal, err error) {
+	return v5.GetSpotWalletBalanceForSymbolDecimalCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotWalletBalanceForSymbolDecimal, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetSpotWalletBalanceForSymbolDecimalCtx(ctx context.Context, symbol string) (balance decimal.Decimal, err error) {
+	balances, err := v5.GetSpotWalletBalanceCtx(ctx)
+
+	for _, coin := range balances {
+		if coin.Coin == symbol {
+			return coin.Free, err
+		}
+	}
+
+	return balance, err
+}
+
+/*
+	Gets the entire spot wallet balance, the unified wallet with WithUnifiedAccount.
+
+	Returns:
+		balances []SpotBalance
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/account/wallet-balance
+*/
+func (v5 *BybitV5Exchange) GetSpotWalletBalance() (balances []SpotBalance, err error) {
+	return v5.GetSpotWalletBalanceCtx(context.Background())
+}
+
+// Same as GetSpotWalletBalance, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetSpotWalletBalanceCtx(ctx context.Context) (balances []SpotBalance, err error) {
+	wallet, err := v5.GetWalletBalanceCtx(ctx, v5.accountType(ACCOUNT_TYPE_SPOT))
+	if err != nil {
+		return balances, err
+	}
+
+	for _, coin := range wallet.Coin {
+		free := parseDecimal(coin.Free)
+		if coin.Free == "" {
+			free = parseDecimal(coin.AvailableToWithdraw)
+		}
+		balances = append(balances, SpotBalance{
+			Coin:     coin.Coin,
+			CoinName: coin.Coin,
+			Total:    parseDecimal(coin.WalletBalance),
+			Free:     free,
+			Locked:   parseDecimal(coin.Locked),
+		})
+	}
+	return balances, nil
+}
+
+/*
+	Gets the entire derivatives wallet balance, the unified wallet with WithUnifiedAccount.
+
+	Returns:
+		a map of PerpBalances, each one keyed to its token name (e.g. "BTC", "USDT")
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/account/wallet-balance
+*/
+func (v5 *BybitV5Exchange) GetPerpWalletBalance() (balances map[string]PerpBalance, err error) {
+	return v5.GetPerpWalletBalanceCtx(context.Background())
+}
+
+// Same as GetPerpWalletBalance, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpWalletBalanceCtx(ctx context.Context) (balances map[string]PerpBalance, err error) {
+	wallet, err := v5.GetWalletBalanceCtx(ctx, v5.accountType(ACCOUNT_TYPE_CONTRACT))
+	if err != nil {
+		return balances, err
+	}
+
+	balances = make(map[string]PerpBalance, len(wallet.Coin))
+	for _, coin := range wallet.Coin {
+		orderMargin := parseDecimal(coin.TotalOrderIM)
+		positionMargin := parseDecimal(coin.TotalPositionIM)
+		balances[coin.Coin] = PerpBalance{
+			Equity:           parseDecimal(coin.Equity),
+			AvailableBalance: parseDecimal(coin.AvailableToWithdraw),
+			UsedMargin:       orderMargin.Add(positionMargin),
+			OrderMargin:      orderMargin,
+			PositionMargin:   positionMargin,
+			WalletBalance:    parseDecimal(coin.WalletBalance),
+			UnrealisedPnl:    parseDecimal(coin.UnrealisedPnl),
+			CumRealisedPnl:   parseDecimal(coin.CumRealisedPnl),
+		}
+	}
+	return balances, nil
+}
+
+/*
+	Gets the entire spot and perp wallet balance in USD.
+
+	Returns:
+		balance float64
+		err error - a coin whose price can't be fetched fails the total rather than counting as zero
+
+	Refs: https://bybit-exchange.github.io/docs/v5/account/wallet-balance
+*/
+func (v5 *BybitV5Exchange) GetTotalAcctUsdValue() (balance float64, err error) {
+	return v5.GetTotalAcctUsdValueCtx(context.Background())
+}
+
+// Same as GetTotalAcctUsdValue, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetTotalAcctUsdValueCtx(ctx context.Context) (balance float64, err error) {
+	total, err := v5.GetTotalAcctUsdValueDecimalCtx(ctx)
+	return total.InexactFloat64(), err
+}
+
+// Same as GetTotalAcctUsdValue, returning the exact value
+func (v5 *BybitV5Exchange) GetTotalAcctUsdValueDecimal() (balance decimal.Decimal, err error) {
+	return v5.GetTotalAcctUsdValueDecimalCtx(context.Background())
+}
+
+// Same as GetTotalAcctUsdValueDecimal, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetTotalAcctUsdValueDecimalCtx(ctx context.Context) (balance decimal.Decimal, err error) {
+	// the unified account values itself
+	if v5.bybit.unifiedAccount {
+		wallet, err := v5.GetWalletBalanceCtx(ctx, ACCOUNT_TYPE_UNIFIED)
+		if err != nil {
+			return balance, err
+		}
+		return decimal.NewFromString(wallet.TotalEquity)
+	}
+
+	return totalUsdValue(ctx, v5.GetSpotWalletBalanceCtx, v5.GetPerpWalletBalanceCtx, v5.GetMarketPriceDecimalCtx)
+}
+
+/*
+	Gets perp position of symbol, the open leg in hedge mode, or a "None" side
+	position when flat. GetPerpPositions returns both legs.
+
+	Requires:
+		symbol string - e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+
+	Returns:
+		position PerpPosition
+		err error - wraps ErrHedgedPosition when both hedge legs are open
+
+	Refs: https://bybit-exchange.github.io/docs/v5/position
+*/
+func (v5 *BybitV5Exchange) GetPerpPosition(symbol string) (position PerpPosition, err error) {
+	return v5.GetPerpPositionCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpPosition, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpPositionCtx(ctx context.Context, symbol string) (position PerpPosition, err error) {
+	positions, err := v5.GetPerpPositionsCtx(ctx, symbol)
+	if err != nil {
+		return position, err
+	}
+	return openLinearPosition("GetPerpPosition", symbol, positions)
+}
+
+/*
+	Gets every leg of a perp position, both sides in hedge mode, told apart by
+	PositionIdx.
+
+	Requires:
+		symbol string - e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+
+	Returns:
+		positions []PerpPosition
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/position
+*/
+func (v5 *BybitV5Exchange) GetPerpPositions(symbol string) (positions []PerpPosition, err error) {
+	return v5.GetPerpPositionsCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpPositions, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpPositionsCtx(ctx context.Context, symbol string) (positions []PerpPosition, err error) {
+	category, err := v5.perpCategory(ctx, symbol)
+	if err != nil {
+		return positions, err
+	}
+	v5Positions, err := v5.GetPositionsCtx(ctx, category, symbol)
+	if err != nil {
+		return positions, err
+	}
+
+	for _, position := range v5Positions {
+		positions = append(positions, v5PositionToPerpPosition(position))
+	}
+	return positions, nil
+}
+
+/*
+	Gets deposit address for symbol.
+
+	Requires:
+		symbol string - currency symbol, e.g. "BTC"
+		network string - chain type, e.g. "ETH", "" for the first chain
+
+	Returns:
+		address string
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/asset/master-deposit-addr
+*/
+func (v5 *BybitV5Exchange) GetDepositAddress(symbol, network string) (address string, err error) {
+	return v5.GetDepositAddressCtx(context.Background(), symbol, network)
+}
+
+// Same as GetDepositAddress, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetDepositAddressCtx(ctx context.Context, symbol, network string) (address string, err error) {
+	functionName := "GetDepositAddress"
+	params := map[string]interface{}{}
+	params["coin"] = symbol
+	if network != "" {
+		params["chainType"] = network
+	}
+
+	req := newHeaderSignedRequest(http.MethodGet, V5_DEPOSIT_ADDRESS, params)
+	var response = new(ResponseForV5DepositAddress)
+	if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return address, err
+	}
+
+	if len(response.Result.Chains) == 0 {
+		err = fmt.Errorf("%v failed: no deposit address for %v", functionName, symbol)
+		v5.bybit.logger.Error(err.Error())
+		return address, err
+	}
+
+	return response.Result.Chains[0].AddressDeposit, nil
+}
+
+/*
+	Get market price for perp or spot.
+
+	Requires:
+		baseCurr string
+		quoteCurr string
+		marketType string
+
+	Returns:
+		marketPrice float64
+		err error
+*/
+func (v5 *BybitV5Exchange) GetMarketPrice(baseCurr, quoteCurr, marketType string) (marketPrice float64, err error) {
+	return v5.GetMarketPriceCtx(context.Background(), baseCurr, quoteCurr, marketType)
+}
+
+// Same as GetMarketPrice, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetMarketPriceCtx(ctx context.Context, baseCurr, quoteCurr, marketType string) (marketPrice float64, err error) {
+	price, err := v5.GetMarketPriceDecimalCtx(ctx, baseCurr, quoteCurr, marketType)
+	return price.InexactFloat64(), err
+}
+
+// Same as GetMarketPrice, returning the exact price
+func (v5 *BybitV5Exchange) GetMarketPriceDecimal(baseCurr, quoteCurr, marketType string) (marketPrice decimal.Decimal, err error) {
+	return v5.GetMarketPriceDecimalCtx(context.Background(), baseCurr, quoteCurr, marketType)
+}
+
+// Same as GetMarketPriceDecimal, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetMarketPriceDecimalCtx(ctx context.Context, baseCurr, quoteCurr, marketType string) (marketPrice decimal.Decimal, err error) {
+	symbol := baseCurr + quoteCurr
+	switch marketType {
+	case "perp":
+		return v5.GetPerpMarketPriceDecimalCtx(ctx, symbol)
+	case "spot":
+		return v5.GetSpotMarketPriceDecimalCtx(ctx, symbol)
+	}
+	return marketPrice, unsupportedMarketType("GetMarketPrice", marketType)
+}
+
+/*
+	Gets spot market price using best bid price.
+
+	Requires:
+		symbol (baseCurr + quoteCurr) string
+
+	Returns:
+		marketPrice float64
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/market/tickers
+*/
+func (v5 *BybitV5Exchange) GetSpotMarketPrice(symbol string) (marketPrice float64, err error) {
+	return v5.GetSpotMarketPriceCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotMarketPrice, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetSpotMarketPriceCtx(ctx context.Context, symbol string) (marketPrice float64, err error) {
+	price, err := v5.GetSpotMarketPriceDecimalCtx(ctx, symbol)
+	return price.InexactFloat64(), err
+}
+
+// Same as GetSpotMarketPrice, returning the exact price
+func (v5 *BybitV5Exchange) GetSpotMarketPriceDecimal(symbol string) (marketPrice decimal.Decimal, err error) {
+	return v5.GetSpotMarketPriceDecimalCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotMarketPriceDecimal, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetSpotMarketPriceDecimalCtx(ctx context.Context, symbol string) (marketPrice decimal.Decimal, err error) {
+	bookTicker, err := v5.GetSpotBookTickerCtx(ctx, symbol)
+	if err != nil {
+		return marketPrice, err
+	}
+
+	return decimal.NewFromString(bookTicker.BidPrice)
+}
+
+/*
+	Gets spot best bid and ask price and quantity.
+
+	Requires:
+		symbol (baseCurr + quoteCurr) string
+
+	Returns:
+		bookTicker SpotMarketPrice
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/market/tickers
+*/
+func (v5 *BybitV5Exchange) GetSpotBookTicker(symbol string) (bookTicker SpotMarketPrice, err error) {
+	return v5.GetSpotBookTickerCtx(context.Background(), symbol)
+}
+
+// Same as GetSpotBookTicker, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetSpotBookTickerCtx(ctx context.Context, symbol string) (bookTicker SpotMarketPrice, err error) {
+	functionName := "GetSpotBookTicker"
+	ticker, err := v5.ticker(ctx, functionName, CATEGORY_SPOT, symbol)
+	if err != nil {
+		return bookTicker, err
+	}
+
+	if ticker.Bid1Price == "" {
+		err = fmt.Errorf("%v failed: empty order book for %v", functionName, symbol)
+		v5.bybit.logger.Error(err.Error())
+		return bookTicker, err
+	}
+
+	return SpotMarketPrice{
+		BidPrice: ticker.Bid1Price,
+		BidQty:   ticker.Bid1Size,
+		AskPrice: ticker.Ask1Price,
+		AskQty:   ticker.Ask1Size,
+		Time:     time.Now().UnixMilli(),
+	}, nil
+}
+
+/*
+	Gets Perp Market Price using mark price
+
+	Requires:
+		symbol (baseCurr + quoteCurr) string - e.g. "BTCUSD" (inverse) or "BTCUSDT" (linear)
+
+	Returns:
+		marketPrice float64
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/market/tickers
+*/
+func (v5 *BybitV5Exchange) GetPerpMarketPrice(symbol string) (marketPrice float64, err error) {
+	return v5.GetPerpMarketPriceCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpMarketPrice, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpMarketPriceCtx(ctx context.Context, symbol string) (marketPrice float64, err error) {
+	price, err := v5.GetPerpMarketPriceDecimalCtx(ctx, symbol)
+	return price.InexactFloat64(), err
+}
+
+// Same as GetPerpMarketPrice, returning the exact price
+func (v5 *BybitV5Exchange) GetPerpMarketPriceDecimal(symbol string) (marketPrice decimal.Decimal, err error) {
+	return v5.GetPerpMarketPriceDecimalCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpMarketPriceDecimal, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpMarketPriceDecimalCtx(ctx context.Context, symbol string) (marketPrice decimal.Decimal, err error) {
+	category, err := v5.perpCategory(ctx, symbol)
+	if err != nil {
+		return marketPrice, err
+	}
+	ticker, err := v5.ticker(ctx, "GetPerpMarketPrice", category, symbol)
+	if err != nil {
+		return marketPrice, err
+	}
+
+	return decimal.NewFromString(ticker.MarkPrice)
+}
+
+/*
+	Gets Perp Tick Size. Useful for calculating things like the allowed bid price range for order
+
+	Requires:
+		symbol (baseCurr + quoteCurr) string - e.g. "BTCUSD"
+
+	Returns:
+		maxPrice float64
+		tickSize float64
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/market/instrument
+*/
+func (v5 *BybitV5Exchange) GetPerpTickSize(symbol string) (maxPrice, tickSize float64, err error) {
+	return v5.GetPerpTickSizeCtx(context.Background(), symbol)
+}
+
+// Same as GetPerpTickSize, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetPerpTickSizeCtx(ctx context.Context, symbol string) (maxPrice, tickSize float64, err error) {
+	return v5.bybit.GetPerpTickSizeCtx(ctx, symbol)
+}
+
+/*
+	Tells an inverse perp from a linear one by the contract type in the
+	instrument registry.
+
+	Requires:
+		symbol string - e.g. "BTCUSD"
+
+	Returns:
+		isInverse bool
+		err error
+*/
+func (v5 *BybitV5Exchange) IsInversePerp(symbol string) (isInverse bool, err error) {
+	return v5.IsInversePerpCtx(context.Background(), symbol)
+}
+
+// Same as IsInversePerp, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) IsInversePerpCtx(ctx context.Context, symbol string) (isInverse bool, err error) {
+	return v5.bybit.IsInversePerpCtx(ctx, symbol)
+}
+
+/*
+	Get Bybit Server Time.
+
+	Returns:
+		serverTime float64 - seconds
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/market/time
+*/
+func (v5 *BybitV5Exchange) GetServerTime() (serverTime float64, err error) {
+	return v5.GetServerTimeCtx(context.Background())
+}
+
+// Same as GetServerTime, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) GetServerTimeCtx(ctx context.Context) (serverTime float64, err error) {
+	functionName := "GetServerTime"
+
+	req := newPublicRequest(http.MethodGet, V5_SERVER_TIME, nil)
+	var response = new(ResponseForV5ServerTime)
+	if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return serverTime, err
+	}
+
+	nanos, err := strconv.ParseInt(response.Result.TimeNano, 10, 64)
+	if err != nil {
+		return strconv.ParseFloat(response.Result.TimeSecond, 64)
+	}
+	return float64(nanos) / float64(time.Second), nil
+}
+
+// Same as BybitExchange.ValidateSpotOrder, against the V5 instruments
+func (v5 *BybitV5Exchange) ValidateSpotOrder(params PlaceSpotOrderParams) (validParams PlaceSpotOrderParams, err error) {
+	return v5.bybit.ValidateSpotOrderCtx(context.Background(), params)
+}
+
+// Same as ValidateSpotOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) ValidateSpotOrderCtx(ctx context.Context, params PlaceSpotOrderParams) (validParams PlaceSpotOrderParams, err error) {
+	return v5.bybit.ValidateSpotOrderCtx(ctx, params)
+}
+
+// Same as BybitExchange.ValidatePerpOrder, against the V5 instruments
+func (v5 *BybitV5Exchange) ValidatePerpOrder(params PlacePerpOrderParams) (validParams PlacePerpOrderParams, err error) {
+	return v5.bybit.ValidatePerpOrderCtx(context.Background(), params)
+}
+
+// Same as ValidatePerpOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) ValidatePerpOrderCtx(ctx context.Context, params PlacePerpOrderParams) (validParams PlacePerpOrderParams, err error) {
+	return v5.bybit.ValidatePerpOrderCtx(ctx, params)
+}
+
+// ---------------------------- POST CALLS ----------------------------
+
+/*
+	Creates an order of any category at most once. Generates an OrderLinkId when
+	none is set, and looks the order up by it before resending an ambiguous placement.
+
+	Requires:
+		params V5PlaceOrderParams
+
+	Returns:
+		orderId string
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/create-order
+*/
+func (v5 *BybitV5Exchange) PlaceOrder(params V5PlaceOrderParams) (orderId string, err error) {
+	return v5.PlaceOrderCtx(context.Background(), params)
+}
+
+// Same as PlaceOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) PlaceOrderCtx(ctx context.Context, params V5PlaceOrderParams) (orderId string, err error) {
+	return v5.placeOrder(ctx, "PlaceOrder", params)
+}
+
+/*
+	Creates a spot order at most once. Generates an OrderLinkId when none is set,
+	and looks the order up by it before resending an ambiguous placement.
+	With WithOrderValidation the order is first checked against the instrument
+	filters and a *ValidationError returned without sending it.
+
+	Requires:
+		params PlaceSpotOrderParams - market buys are sized in the quote coin
+
+	Returns:
+		orderId string
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/create-order
+*/
+func (v5 *BybitV5Exchange) PlaceSpotOrder(params PlaceSpotOrderParams) (orderId string, err error) {
+	return v5.PlaceSpotOrderCtx(context.Background(), params)
+}
+
+// Same as PlaceSpotOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) PlaceSpotOrderCtx(ctx context.Context, params PlaceSpotOrderParams) (orderId string, err error) {
+	if v5.bybit.orderValidation != nil {
+		if params, err = v5.bybit.ValidateSpotOrderCtx(ctx, params); err != nil {
+			return orderId, err
+		}
+	}
+
+	v5Params := V5PlaceOrderParams{
+		Category:    CATEGORY_SPOT,
+		Symbol:      params.Symbol,
+		Side:        params.Side,
+		OrderType:   V5_ORDER_TYPE_LIMIT,
+		Qty:         params.Qty,
+		Price:       params.Price,
+		TimeInForce: params.TimeInForce,
+		OrderLinkId: params.OrderLinkId,
+	}
+	switch strings.ToUpper(params.Type) {
+	case SPOT_ORDER_TYPE_MARKET:
+		v5Params.OrderType = V5_ORDER_TYPE_MARKET
+		v5Params.Price = decimal.Decimal{}
+		if strings.EqualFold(params.Side, ORDER_SIDE_BUY) {
+			v5Params.MarketUnit = "quoteCoin"
+		}
+	case SPOT_ORDER_TYPE_LIMIT_MAKER:
+		v5Params.TimeInForce = V5_POST_ONLY
+	}
+
+	return v5.placeOrder(ctx, "PlaceSpotOrder", v5Params)
+}
+
+/*
+	Creates a perp order at most once, routed to the linear or inverse category
+	by symbol. Generates an OrderLinkId when none is set, and looks the order up
+	by it before resending an ambiguous placement.
+	With WithOrderValidation the order is first checked against the instrument
+	filters and a *ValidationError returned without sending it.
+
+	Requires:
+		params PlacePerpOrderParams - qty in USD for inverse, in the base coin for linear
+
+	Returns:
+		orderId string
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/create-order
+*/
+func (v5 *BybitV5Exchange) PlacePerpOrder(params PlacePerpOrderParams) (orderId string, err error) {
+	return v5.PlacePerpOrderCtx(context.Background(), params)
+}
+
+// Same as PlacePerpOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) PlacePerpOrderCtx(ctx context.Context, params PlacePerpOrderParams) (orderId string, err error) {
+	if v5.bybit.orderValidation != nil {
+		if params, err = v5.bybit.ValidatePerpOrderCtx(ctx, params); err != nil {
+			return orderId, err
+		}
+	}
+
+	category, err := v5.perpCategory(ctx, params.Symbol)
+	if err != nil {
+		return orderId, err
+	}
+
+	v5Params := V5PlaceOrderParams{
+		Category:       category,
+		Symbol:         params.Symbol,
+		Side:           params.Side,
+		OrderType:      params.OrderType,
+		Qty:            params.Qty,
+		Price:          params.Price,
+		TimeInForce:    toV5TimeInForce(params.TimeInForce),
+		OrderLinkId:    params.OrderLinkId,
+		ReduceOnly:     params.ReduceOnly,
+		CloseOnTrigger: params.CloseOnTrigger,
+		TakeProfit:     params.TakeProfit,
+		StopLoss:       params.StopLoss,
+		TpTriggerBy:    params.TpTriggerBy,
+		SlTriggerBy:    params.SlTriggerBy,
+		PositionIdx:    params.PositionIdx,
+	}
+	if strings.EqualFold(params.OrderType, PLACE_PERP_MARKET) {
+		v5Params.Price = decimal.Decimal{}
+	}
+
+	return v5.placeOrder(ctx, "PlacePerpOrder", v5Params)
+}
+
+/*
+	Withdrawls from exchange. Withdrawals are made from the funding wallet.
+
+	Requires:
+		coin string - e.g. "USDT"
+		amount float64
+		destinationAddress string
+		network string - chain, e.g. "ETH"
+
+	Returns:
+		withdrawOrderId string
+		err error
+
+	Refs: https://bybit-exchange.github.io/docs/v5/asset/withdraw
+*/
+func (v5 *BybitV5Exchange) WithdrawFromExchange(coin string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error) {
+	return v5.WithdrawFromExchangeCtx(context.Background(), coin, amount, destinationAddress, network)
+}
+
+// Same as WithdrawFromExchange, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) WithdrawFromExchangeCtx(ctx context.Context, coin string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error) {
+	return v5.WithdrawFromExchangeDecimalCtx(ctx, coin, decimal.NewFromFloat(amount), destinationAddress, network)
+}
+
+// Same as WithdrawFromExchange, with an exact amount
+func (v5 *BybitV5Exchange) WithdrawFromExchangeDecimal(coin string, amount decimal.Decimal, destinationAddress, network string) (withdrawOrderId string, err error) {
+	return v5.WithdrawFromExchangeDecimalCtx(context.Background(), coin, amount, destinationAddress, network)
+}
+
+// Same as WithdrawFromExchangeDecimal, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) WithdrawFromExchangeDecimalCtx(ctx context.Context, coin string, amount decimal.Decimal, destinationAddress, network string) (withdrawOrderId string, err error) {
+	functionName := "WithdrawFromExchange"
+
+	// bybit wants its own request time in the body as well
+	timestamp, err := v5.bybit.timeSync.Timestamp(ctx)
+	if err != nil {
+		return withdrawOrderId, err
+	}
+
+	params := map[string]interface{}{}
+	params["coin"] = coin
+	params["amount"] = amount.String()
+	params["chain"] = network
+	params["address"] = destinationAddress
+	params["timestamp"] = timestamp
+
+	req := newHeaderSignedRequest(http.MethodPost, V5_WITHDRAW, params)
+	var response = new(ResponseForV5Withdraw)
+	if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return withdrawOrderId, err
+	}
+
+	return response.Result.Id, nil
+}
+
+// ---------------------------- DELETE CALLS ----------------------------
+
+/*
+	Cancels an order of any category
+
+	Requires:
+		category string - CATEGORY_SPOT, CATEGORY_LINEAR, CATEGORY_INVERSE or CATEGORY_OPTION
+		symbol string
+		orderId string
+
+	Returns:
+		status bool
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/cancel-order
+*/
+func (v5 *BybitV5Exchange) CancelOrder(category, symbol, orderId string) (status bool, err error) {
+	return v5.CancelOrderCtx(context.Background(), category, symbol, orderId)
+}
+
+// Same as CancelOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) CancelOrderCtx(ctx context.Context, category, symbol, orderId string) (status bool, err error) {
+	functionName := "CancelOrder"
+	params := map[string]interface{}{}
+	params["category"] = category
+	params["symbol"] = symbol
+	params["orderId"] = orderId
+
+	req := newHeaderSignedRequest(http.MethodPost, V5_CANCEL_ORDER, params)
+	var response = new(ResponseForV5Order)
+	if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+/*
+	Cancels a spot order. V5 cancels need the symbol, so the order is looked up first.
+
+	Requires:
+		orderId string
+
+	Returns:
+		status bool
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/cancel-order
+*/
+func (v5 *BybitV5Exchange) CancelSpotOrder(orderId string) (status bool, err error) {
+	return v5.CancelSpotOrderCtx(context.Background(), orderId)
+}
+
+// Same as CancelSpotOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) CancelSpotOrderCtx(ctx context.Context, orderId string) (status bool, err error) {
+	order, err := v5.findOrder(ctx, "CancelSpotOrder", CATEGORY_SPOT, "", orderId, "")
+	if err != nil {
+		return false, err
+	}
+
+	return v5.CancelOrderCtx(ctx, CATEGORY_SPOT, order.Symbol, orderId)
+}
+
+/*
+	Cancels a perp order
+
+	Requires:
+		symbol string
+		orderId string
+
+	Returns:
+		status bool
+		err error
+
+	Ref: https://bybit-exchange.github.io/docs/v5/order/cancel-order
+*/
+func (v5 *BybitV5Exchange) CancelPerpOrder(symbol, orderId string) (status bool, err error) {
+	return v5.CancelPerpOrderCtx(context.Background(), symbol, orderId)
+}
+
+// Same as CancelPerpOrder, cancelled or timed out through ctx
+func (v5 *BybitV5Exchange) CancelPerpOrderCtx(ctx context.Context, symbol, orderId string) (status bool, err error) {
+	category, err := v5.perpCategory(ctx, symbol)
+	if err != nil {
+		return status, err
+	}
+	return v5.CancelOrderCtx(ctx, category, symbol, orderId)
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+func (v5 *BybitV5Exchange) placeOrder(ctx context.Context, functionName string, params V5PlaceOrderParams) (orderId string, err error) {
+	if params.OrderLinkId == "" {
+		params.OrderLinkId = newOrderLinkId()
+	}
+
+	req := newHeaderSignedRequest(http.MethodPost, V5_PLACE_ORDER, structs.Map(&params))
+	var response = new(ResponseForV5Order)
+	return v5.bybit.placeOrder(ctx, functionName, params.OrderLinkId, req, response,
+		func() string { return response.Result.OrderId },
+		func(ctx context.Context) (string, error) {
+			order, err := v5.GetOrderByLinkIdCtx(ctx, params.Category, params.Symbol, params.OrderLinkId)
+			return order.OrderId, err
+		})
+}
+
+func (v5 *BybitV5Exchange) getOrders(ctx context.Context, functionName, path, category, symbol, orderId, orderLinkId string) (orders []V5Order, err error) {
+	params := map[string]interface{}{}
+	params["category"] = category
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+	if orderId != "" {
+		params["orderId"] = orderId
+	}
+	if orderLinkId != "" {
+		params["orderLinkId"] = orderLinkId
+	}
+
+	req := newHeaderSignedRequest(http.MethodGet, path, params)
+	var response = new(ResponseForV5Orders)
+	if err = v5.bybit.sendRequest(ctx, functionName, req, response); err != nil {
+		return orders, err
+	}
+
+	return response.Result.List, nil
+}
+
+// looks an order up among the open orders first, then the order history
+func (v5 *BybitV5Exchange) findOrder(ctx context.Context, functionName, category, symbol, orderId, orderLinkId string) (order V5Order, err error) {
+	for _, path := range []string{V5_OPEN_ORDERS, V5_ORDER_HISTORY} {
+		orders, err := v5.getOrders(ctx, functionName, path, category, symbol, orderId, orderLinkId)
+		if err != nil {
+			return order, err
+		}
+		if len(orders) > 0 {
+			return orders[0], nil
+		}
+	}
+
+	return order, fmt.Errorf("%v found no %v order %v%v: %w", functionName, category, orderId, orderLinkId, ErrOrderNotFound)
+}
+
+func (v5 *BybitV5Exchange) ticker(ctx context.Context, functionName, category, symbol string) (ticker V5Ticker, err error) {
+	tickers, err := v5.GetTickersCtx(ctx, category, symbol)
+	if err != nil {
+		return ticker, err
+	}
+
+	if len(tickers) == 0 {
+		err = fmt.Errorf("%v failed: no %v ticker for %v", functionName, category, symbol)
+		v5.bybit.logger.Error(err.Error())
+		return ticker, err
+	}
+	return tickers[0], nil
+}
+
+// the classic account type, or the unified account when configured
+func (v5 *BybitV5Exchange) accountType(classicAccountType string) string {
+	if v5.bybit.unifiedAccount {
+		return ACCOUNT_TYPE_UNIFIED
+	}
+	return classicAccountType
+}
+
+// loads spot, linear and inverse instruments
+func (v5 *BybitV5Exchange) loadInstruments(ctx context.Context) (instruments []Instrument, err error) {
+	for _, category := range []string{CATEGORY_SPOT, CATEGORY_LINEAR, CATEGORY_INVERSE} {
+		infos, err := v5.GetInstrumentsInfoCtx(ctx, category)
+		if err != nil {
+			return instruments, err
+		}
+		for _, info := range infos {
+			instruments = append(instruments, v5InstrumentToInstrument(category, info))
+		}
+	}
+	return instruments, nil
+}
+
+func v5InstrumentToInstrument(category string, info V5InstrumentInfo) Instrument {
+	status := INSTRUMENT_STATUS_CLOSED
+	if info.Status == INSTRUMENT_STATUS_TRADING {
+		status = INSTRUMENT_STATUS_TRADING
+	}
+
+	instrument := Instrument{
+		Symbol:    info.Symbol,
+		BaseCoin:  info.BaseCoin,
+		QuoteCoin: info.QuoteCoin,
+		Status:    status,
+		TickSize:  parseDecimal(info.PriceFilter.TickSize),
+		MinQty:    parseDecimal(info.LotSizeFilter.MinOrderQty),
+		MaxQty:    parseDecimal(info.LotSizeFilter.MaxOrderQty),
+	}
+
+	if category == CATEGORY_SPOT {
+		instrument.MarketType = exchange.MARKET_TYPE_SPOT
+		instrument.ContractType = CONTRACT_TYPE_SPOT
+		instrument.QtyStep = parseDecimal(info.LotSizeFilter.BasePrecision)
+		instrument.MinNotional = parseDecimal(info.LotSizeFilter.MinOrderAmt)
+		instrument.MaxNotional = parseDecimal(info.LotSizeFilter.MaxOrderAmt)
+		return instrument
+	}
+
+	instrument.MarketType = exchange.MARKET_TYPE_PERP
+	instrument.ContractType = info.ContractType
+	instrument.MinPrice = parseDecimal(info.PriceFilter.MinPrice)
+	instrument.MaxPrice = parseDecimal(info.PriceFilter.MaxPrice)
+	instrument.QtyStep = parseDecimal(info.LotSizeFilter.QtyStep)
+	instrument.MinNotional = parseDecimal(info.LotSizeFilter.MinNotionalValue)
+	instrument.MinLeverage = parseDecimal(info.LeverageFilter.MinLeverage)
+	instrument.MaxLeverage = parseDecimal(info.LeverageFilter.MaxLeverage)
+	instrument.LeverageStep = parseDecimal(info.LeverageFilter.LeverageStep)
+	return instrument
+}
+
+func v5OrderToSpotOrder(order V5Order) SpotOrder {
+	orderType := strings.ToUpper(order.OrderType)
+	if order.TimeInForce == V5_POST_ONLY {
+		orderType = SPOT_ORDER_TYPE_LIMIT_MAKER
+	}
+
+	return SpotOrder{
+		Symbol:              order.Symbol,
+		SymbolName:          order.Symbol,
+		OrderLinkId:         order.OrderLinkId,
+		OrderId:             order.OrderId,
+		Price:               order.Price,
+		OrigQty:             order.Qty,
+		ExecutedQty:         order.CumExecQty,
+		CummulativeQuoteQty: order.CumExecValue,
+		AvgPrice:            order.AvgPrice,
+		Status:              toSpotOrderStatus(order.OrderStatus),
+		TimeInForce:         order.TimeInForce,
+		Type:                orderType,
+		Side:                strings.ToUpper(order.Side),
+		StopPrice:           order.TriggerPrice,
+		Time:                order.CreatedTime,
+		UpdateTime:          order.UpdatedTime,
+		IsWorking:           order.OrderStatus == "New" || order.OrderStatus == "PartiallyFilled",
+	}
+}
+
+func v5OrderToPerpOrder(order V5Order) PerpOrder {
+	return PerpOrder{
+		PositionIdx:  order.PositionIdx,
+		OrderStatus:  order.OrderStatus,
+		Symbol:       order.Symbol,
+		Side:         order.Side,
+		OrderType:    order.OrderType,
+		Price:        order.Price,
+		Qty:          order.Qty,
+		TimeInForce:  toPerpTimeInForce(order.TimeInForce),
+		OrderLinkId:  order.OrderLinkId,
+		OrderId:      order.OrderId,
+		CreatedAt:    millisToRFC3339(order.CreatedTime),
+		UpdatedAt:    millisToRFC3339(order.UpdatedTime),
+		LeavesQty:    order.LeavesQty,
+		LeavesValue:  order.LeavesValue,
+		CumExecQty:   order.CumExecQty,
+		CumExecFee:   order.CumExecFee,
+		RejectReason: order.RejectReason,
+		TakeProfit:   order.TakeProfit,
+		StopLoss:     order.StopLoss,
+		TpTriggerBy:  order.TpTriggerBy,
+		SlTriggerBy:  order.SlTriggerBy,
+	}
+}
+
+func v5PositionToPerpPosition(position V5Position) PerpPosition {
+	return PerpPosition{
+		Symbol:         position.Symbol,
+		Side:           position.Side,
+		Size:           parseDecimal(position.Size),
+		PositionValue:  parseDecimal(position.PositionValue),
+		EntryPrice:     parseDecimal(position.AvgPrice),
+		IsIsolated:     position.TradeMode == 1,
+		AutoAddMargin:  position.AutoAddMargin,
+		Leverage:       parseDecimal(position.Leverage),
+		PositionMargin: parseDecimal(position.PositionIM),
+		LiqPrice:       parseDecimal(position.LiqPrice),
+		BustPrice:      parseDecimal(position.BustPrice),
+		TakeProfit:     parseDecimal(position.TakeProfit),
+		StopLoss:       parseDecimal(position.StopLoss),
+		TrailingStop:   parseDecimal(position.TrailingStop),
+		PositionStatus: position.PositionStatus,
+		UnrealisedPnl:  parseDecimal(position.UnrealisedPnl),
+		CumRealisedPnl: parseDecimal(position.CumRealisedPnl),
+		CreatedAt:      millisToRFC3339(position.CreatedTime),
+		UpdatedAt:      millisToRFC3339(position.UpdatedTime),
+		TpSlMode:       position.TpslMode,
+		PositionIdx:    position.PositionIdx,
+	}
+}
+
+// linear or inverse, from the contract type in the instrument registry like the legacy client
+func (v5 *BybitV5Exchange) perpCategory(ctx context.Context, symbol string) (string, error) {
+	isLinear, err := v5.bybit.isLinear(ctx, symbol)
+	if err != nil {
+		return "", err
+	}
+	if isLinear {
+		return CATEGORY_LINEAR, nil
+	}
+	return CATEGORY_INVERSE, nil
+}
+
+func toV5TimeInForce(timeInForce string) string {
+	switch timeInForce {
+	case PLACE_PERP_GTC:
+		return V5_GTC
+	case PLACE_PERP_IMMEDIATE_OR_CANCEL:
+		return V5_IOC
+	case PLACE_PERP_FILL_OR_KILL:
+		return V5_FOK
+	}
+	return timeInForce
+}
+
+func toPerpTimeInForce(timeInForce string) string {
+	switch timeInForce {
+	case V5_GTC:
+		return PLACE_PERP_GTC
+	case V5_IOC:
+		return PLACE_PERP_IMMEDIATE_OR_CANCEL
+	case V5_FOK:
+		return PLACE_PERP_FILL_OR_KILL
+	}
+	return timeInForce
+}
+
+// maps v5 statuses to the spot v1 ones
+func toSpotOrderStatus(status string) string {
+	switch status {
+	case "New", "Untriggered", "Triggered":
+		return "NEW"
+	case "PartiallyFilled":
+		return "PARTIALLY_FILLED"
+	case "Filled":
+		return "FILLED"
+	case "Cancelled", "PartiallyFilledCanceled", "Deactivated":
+		return "CANCELED"
+	case "Rejected":
+		return "REJECTED"
+	}
+	return status
+}
+
+func millisToRFC3339(value string) string {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+// bybit leaves unset numbers empty, they read as zero
+func parseDecimal(value string) decimal.Decimal {
+	d, _ := decimal.NewFromString(value)
+	return d
+}
@@ -0,0 +1,355 @@
+package bybit_exchange
+
+import "github.com/shopspring/decimal"
+
+// V5 response envelope, numbers are returned as strings and "" when unset
+type V5ApiResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Time    int64  `json:"time"`
+}
+
+func (response V5ApiResponse) apiResponse() ApiResponse {
+	return ApiResponse{RetCode: response.RetCode, RetMsg: response.RetMsg}
+}
+
+type ResponseForV5ServerTime struct {
+	V5ApiResponse
+	Result V5ServerTime `json:"result"`
+}
+
+type V5ServerTime struct {
+	TimeSecond string `json:"timeSecond"`
+	TimeNano   string `json:"timeNano"`
+}
+
+type ResponseForV5Tickers struct {
+	V5ApiResponse
+	Result V5Tickers `json:"result"`
+}
+
+type V5Tickers struct {
+	Category string     `json:"category"`
+	List     []V5Ticker `json:"list"`
+}
+
+type V5Ticker struct {
+	Symbol          string `json:"symbol"`
+	LastPrice       string `json:"lastPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	MarkPrice       string `json:"markPrice"`
+	Bid1Price       string `json:"bid1Price"`
+	Bid1Size        string `json:"bid1Size"`
+	Ask1Price       string `json:"ask1Price"`
+	Ask1Size        string `json:"ask1Size"`
+	Volume24h       string `json:"volume24h"`
+	Turnover24h     string `json:"turnover24h"`
+	OpenInterest    string `json:"openInterest"`
+	FundingRate     string `json:"fundingRate"`
+	NextFundingTime string `json:"nextFundingTime"`
+}
+
+type ResponseForV5FundingHistory struct {
+	V5ApiResponse
+	Result V5FundingHistory `json:"result"`
+}
+
+type V5FundingHistory struct {
+	Category string          `json:"category"`
+	List     []V5FundingRate `json:"list"`
+}
+
+type V5FundingRate struct {
+	Symbol               string `json:"symbol"`
+	FundingRate          string `json:"fundingRate"`
+	FundingRateTimestamp string `json:"fundingRateTimestamp"` // unix millis
+}
+
+type ResponseForV5Klines struct {
+	V5ApiResponse
+	Result V5Klines `json:"result"`
+}
+
+type V5Klines struct {
+	Category string `json:"category"`
+	Symbol   string `json:"symbol"`
+	// newest first, [startTime, open, high, low, close, volume, turnover]
+	// mark, index and premium index klines stop at close
+	List [][]string `json:"list"`
+}
+
+type ResponseForV5Orderbook struct {
+	V5ApiResponse
+	Result V5Orderbook `json:"result"`
+}
+
+type V5Orderbook struct {
+	Symbol   string     `json:"s"`
+	Bids     [][]string `json:"b"` // [price, size]
+	Asks     [][]string `json:"a"`
+	Time     int64      `json:"ts"` // unix millis
+	UpdateId int64      `json:"u"`
+	Seq      int64      `json:"seq"`
+}
+
+type ResponseForV5RecentTrades struct {
+	V5ApiResponse
+	Result V5RecentTrades `json:"result"`
+}
+
+type V5RecentTrades struct {
+	Category string          `json:"category"`
+	List     []V5RecentTrade `json:"list"`
+}
+
+type V5RecentTrade struct {
+	ExecId       string `json:"execId"`
+	Symbol       string `json:"symbol"`
+	Price        string `json:"price"`
+	Size         string `json:"size"` // base coin for spot and linear, contracts for inverse
+	Side         string `json:"side"` // taker side
+	Time         string `json:"time"` // unix millis
+	IsBlockTrade bool   `json:"isBlockTrade"`
+}
+
+type ResponseForV5OpenInterest struct {
+	V5ApiResponse
+	Result V5OpenInterests `json:"result"`
+}
+
+type V5OpenInterests struct {
+	Category       string           `json:"category"`
+	Symbol         string           `json:"symbol"`
+	List           []V5OpenInterest `json:"list"`
+	NextPageCursor string           `json:"nextPageCursor"`
+}
+
+type V5OpenInterest struct {
+	OpenInterest string `json:"openInterest"`
+	Timestamp    string `json:"timestamp"` // unix millis
+}
+
+type ResponseForV5AccountRatio struct {
+	V5ApiResponse
+	Result V5AccountRatios `json:"result"`
+}
+
+type V5AccountRatios struct {
+	List           []V5AccountRatio `json:"list"`
+	NextPageCursor string           `json:"nextPageCursor"`
+}
+
+type V5AccountRatio struct {
+	Symbol    string `json:"symbol"`
+	BuyRatio  string `json:"buyRatio"`
+	SellRatio string `json:"sellRatio"`
+	Timestamp string `json:"timestamp"` // unix millis
+}
+
+type ResponseForV5InstrumentsInfo struct {
+	V5ApiResponse
+	Result V5InstrumentsInfo `json:"result"`
+}
+
+type V5InstrumentsInfo struct {
+	Category       string             `json:"category"`
+	List           []V5InstrumentInfo `json:"list"`
+	NextPageCursor string             `json:"nextPageCursor"`
+}
+
+type V5InstrumentInfo struct {
+	Symbol         string           `json:"symbol"`
+	ContractType   string           `json:"contractType"`
+	Status         string           `json:"status"`
+	BaseCoin       string           `json:"baseCoin"`
+	QuoteCoin      string           `json:"quoteCoin"`
+	SettleCoin     string           `json:"settleCoin"`
+	PriceFilter    V5PriceFilter    `json:"priceFilter"`
+	LotSizeFilter  V5LotSizeFilter  `json:"lotSizeFilter"`
+	LeverageFilter V5LeverageFilter `json:"leverageFilter"`
+}
+
+type V5PriceFilter struct {
+	MinPrice string `json:"minPrice"`
+	MaxPrice string `json:"maxPrice"`
+	TickSize string `json:"tickSize"`
+}
+
+type V5LotSizeFilter struct {
+	BasePrecision    string `json:"basePrecision"`  // spot
+	QuotePrecision   string `json:"quotePrecision"` // spot
+	MinOrderQty      string `json:"minOrderQty"`
+	MaxOrderQty      string `json:"maxOrderQty"`
+	MinOrderAmt      string `json:"minOrderAmt"` // spot
+	MaxOrderAmt      string `json:"maxOrderAmt"` // spot
+	QtyStep          string `json:"qtyStep"`
+	MinNotionalValue string `json:"minNotionalValue"`
+}
+
+type V5LeverageFilter struct {
+	MinLeverage  string `json:"minLeverage"`
+	MaxLeverage  string `json:"maxLeverage"`
+	LeverageStep string `json:"leverageStep"`
+}
+
+type V5PlaceOrderParams struct {
+	Category       string          `structs:"category"`  //required
+	Symbol         string          `structs:"symbol"`    //required
+	Side           string          `structs:"side"`      //required
+	OrderType      string          `structs:"orderType"` //required
+	Qty            decimal.Decimal `structs:"qty,omitnested"`
+	Price          decimal.Decimal `structs:"price,omitnested,omitempty"`
+	TimeInForce    string          `structs:"timeInForce,omitempty"`
+	OrderLinkId    string          `structs:"orderLinkId,omitempty"`
+	ReduceOnly     bool            `structs:"reduceOnly,omitempty"`
+	CloseOnTrigger bool            `structs:"closeOnTrigger,omitempty"`
+	PositionIdx    int             `structs:"positionIdx,omitempty"`
+	MarketUnit     string          `structs:"marketUnit,omitempty"` // spot market orders, "baseCoin" or "quoteCoin"
+	TakeProfit     decimal.Decimal `structs:"takeProfit,omitnested,omitempty"`
+	StopLoss       decimal.Decimal `structs:"stopLoss,omitnested,omitempty"`
+	TpTriggerBy    string          `structs:"tpTriggerBy,omitempty"`
+	SlTriggerBy    string          `structs:"slTriggerBy,omitempty"`
+}
+
+type ResponseForV5Order struct {
+	V5ApiResponse
+	Result V5OrderIds `json:"result"`
+}
+
+type V5OrderIds struct {
+	OrderId     string `json:"orderId"`
+	OrderLinkId string `json:"orderLinkId"`
+}
+
+type ResponseForV5Orders struct {
+	V5ApiResponse
+	Result V5Orders `json:"result"`
+}
+
+type V5Orders struct {
+	Category       string    `json:"category"`
+	List           []V5Order `json:"list"`
+	NextPageCursor string    `json:"nextPageCursor"`
+}
+
+type V5Order struct {
+	OrderId      string `json:"orderId"`
+	OrderLinkId  string `json:"orderLinkId"`
+	Symbol       string `json:"symbol"`
+	Side         string `json:"side"`
+	OrderType    string `json:"orderType"`
+	Price        string `json:"price"`
+	Qty          string `json:"qty"`
+	TimeInForce  string `json:"timeInForce"`
+	OrderStatus  string `json:"orderStatus"`
+	AvgPrice     string `json:"avgPrice"`
+	LeavesQty    string `json:"leavesQty"`
+	LeavesValue  string `json:"leavesValue"`
+	CumExecQty   string `json:"cumExecQty"`
+	CumExecValue string `json:"cumExecValue"`
+	CumExecFee   string `json:"cumExecFee"`
+	RejectReason string `json:"rejectReason"`
+	TriggerPrice string `json:"triggerPrice"`
+	TakeProfit   string `json:"takeProfit"`
+	StopLoss     string `json:"stopLoss"`
+	TpTriggerBy  string `json:"tpTriggerBy"`
+	SlTriggerBy  string `json:"slTriggerBy"`
+	ReduceOnly   bool   `json:"reduceOnly"`
+	PositionIdx  int    `json:"positionIdx"`
+	CreatedTime  string `json:"createdTime"`
+	UpdatedTime  string `json:"updatedTime"`
+}
+
+type ResponseForV5Positions struct {
+	V5ApiResponse
+	Result V5Positions `json:"result"`
+}
+
+type V5Positions struct {
+	Category       string       `json:"category"`
+	List           []V5Position `json:"list"`
+	NextPageCursor string       `json:"nextPageCursor"`
+}
+
+type V5Position struct {
+	PositionIdx    int    `json:"positionIdx"`
+	Symbol         string `json:"symbol"`
+	Side           string `json:"side"`
+	Size           string `json:"size"`
+	AvgPrice       string `json:"avgPrice"`
+	PositionValue  string `json:"positionValue"`
+	TradeMode      int    `json:"tradeMode"` // 0 cross margin, 1 isolated margin
+	AutoAddMargin  int    `json:"autoAddMargin"`
+	PositionStatus string `json:"positionStatus"`
+	Leverage       string `json:"leverage"`
+	MarkPrice      string `json:"markPrice"`
+	LiqPrice       string `json:"liqPrice"`
+	BustPrice      string `json:"bustPrice"`
+	PositionIM     string `json:"positionIM"`
+	PositionMM     string `json:"positionMM"`
+	TpslMode       string `json:"tpslMode"`
+	TakeProfit     string `json:"takeProfit"`
+	StopLoss       string `json:"stopLoss"`
+	TrailingStop   string `json:"trailingStop"`
+	UnrealisedPnl  string `json:"unrealisedPnl"`
+	CumRealisedPnl string `json:"cumRealisedPnl"`
+	CreatedTime    string `json:"createdTime"`
+	UpdatedTime    string `json:"updatedTime"`
+}
+
+type ResponseForV5WalletBalance struct {
+	V5ApiResponse
+	Result V5WalletBalances `json:"result"`
+}
+
+type V5WalletBalances struct {
+	List []V5WalletBalance `json:"list"`
+}
+
+type V5WalletBalance struct {
+	AccountType           string          `json:"accountType"`
+	TotalEquity           string          `json:"totalEquity"` // USD, unified account only
+	TotalWalletBalance    string          `json:"totalWalletBalance"`
+	TotalAvailableBalance string          `json:"totalAvailableBalance"`
+	Coin                  []V5CoinBalance `json:"coin"`
+}
+
+type V5CoinBalance struct {
+	Coin                string `json:"coin"`
+	Equity              string `json:"equity"`
+	UsdValue            string `json:"usdValue"`
+	WalletBalance       string `json:"walletBalance"`
+	Free                string `json:"free"` // classic spot account only
+	Locked              string `json:"locked"`
+	AvailableToWithdraw string `json:"availableToWithdraw"`
+	TotalOrderIM        string `json:"totalOrderIM"`
+	TotalPositionIM     string `json:"totalPositionIM"`
+	UnrealisedPnl       string `json:"unrealisedPnl"`
+	CumRealisedPnl      string `json:"cumRealisedPnl"`
+}
+
+type ResponseForV5DepositAddress struct {
+	V5ApiResponse
+	Result V5DepositAddress `json:"result"`
+}
+
+type V5DepositAddress struct {
+	Coin   string           `json:"coin"`
+	Chains []V5DepositChain `json:"chains"`
+}
+
+type V5DepositChain struct {
+	ChainType      string `json:"chainType"`
+	AddressDeposit string `json:"addressDeposit"`
+	TagDeposit     string `json:"tagDeposit"`
+	Chain          string `json:"chain"`
+}
+
+type ResponseForV5Withdraw struct {
+	V5ApiResponse
+	Result V5WithdrawId `json:"result"`
+}
+
+type V5WithdrawId struct {
+	Id string `json:"id"`
+}
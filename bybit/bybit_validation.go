@@ -0,0 +1,380 @@
+package bybit_exchange
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	exchange "github.com/0xSaiki/pawo-exchange-wrappers/interfaces"
+	"github.com/shopspring/decimal"
+)
+
+// How a price or quantity off the instrument's tick or lot grid is handled
+type RoundingMode string
+
+const (
+	ROUNDING_REJECT  RoundingMode = "reject"  // fail validation, the default
+	ROUNDING_DOWN    RoundingMode = "down"    // floor to the grid
+	ROUNDING_UP      RoundingMode = "up"      // ceil to the grid
+	ROUNDING_NEAREST RoundingMode = "nearest" // round half away from zero
+	ROUNDING_PASSIVE RoundingMode = "passive" // prices down for buys and up for sells, quantities down
+)
+
+// Pre-trade checks run before an order is sent. Orders are checked against the
+// instrument's status, tick size, price range, lot size, quantity range and
+// minimum notional; misaligned prices and quantities are rounded or rejected.
+type OrderValidation struct {
+	PriceRounding RoundingMode // also applied to take profit and stop loss
+	QtyRounding   RoundingMode
+}
+
+// An order that would be rejected by bybit's filters, detected before sending it.
+// Matches ErrInvalidOrder with errors.Is.
+type ValidationError struct {
+	Symbol string
+	Field  string // "symbol", "qty", "price", "stop_px", "take_profit", "stop_loss", "notional" or "position_idx"
+	Value  decimal.Decimal
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("bybit: invalid %v order: %v %v %v", e.Symbol, e.Field, e.Value, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidOrder
+}
+
+/*
+	Checks a spot order against the pair's filters without sending it, rounding
+	its price and quantity as configured with WithOrderValidation (rejecting
+	misaligned values otherwise). Market buys are sized in the quote coin, so
+	their quantity is checked against the notional limits instead.
+
+	Requires:
+		params PlaceSpotOrderParams
+
+	Returns:
+		validParams PlaceSpotOrderParams - params with the price and quantity rounded
+		err error - a *ValidationError when the order breaks a filter
+
+	Ref: https://bybit-exchange.github.io/docs/spot/v1/#t-querysymbol
+*/
+func (bybit *BybitExchange) ValidateSpotOrder(params PlaceSpotOrderParams) (validParams PlaceSpotOrderParams, err error) {
+	return bybit.ValidateSpotOrderCtx(context.Background(), params)
+}
+
+// Same as ValidateSpotOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) ValidateSpotOrderCtx(ctx context.Context, params PlaceSpotOrderParams) (validParams PlaceSpotOrderParams, err error) {
+	instrument, err := bybit.instruments.BySymbol(ctx, exchange.MARKET_TYPE_SPOT, params.Symbol)
+	if err != nil {
+		return params, err
+	}
+	validator := bybit.orderValidator(instrument, params.Side)
+
+	if err = validator.checkTrading(); err != nil {
+		return params, err
+	}
+
+	if strings.EqualFold(params.Type, SPOT_ORDER_TYPE_MARKET) {
+		if strings.EqualFold(params.Side, ORDER_SIDE_BUY) {
+			return params, validator.checkNotional(params.Qty)
+		}
+		params.Qty, err = validator.qty(params.Qty)
+		return params, err
+	}
+
+	if params.Price, err = validator.price("price", params.Price); err != nil {
+		return params, err
+	}
+	if params.Qty, err = validator.qty(params.Qty); err != nil {
+		return params, err
+	}
+	return params, validator.checkNotional(params.Qty.Mul(params.Price))
+}
+
+/*
+	Checks a perp order against the contract's filters without sending it,
+	rounding its prices and quantity as configured with WithOrderValidation
+	(rejecting misaligned values otherwise).
+
+	Requires:
+		params PlacePerpOrderParams
+
+	Returns:
+		validParams PlacePerpOrderParams - params with the prices and quantity rounded
+		err error - a *ValidationError when the order breaks a filter, wraps ErrReduceOnlyViolation when a reduce-only order would add to its hedge leg
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-querysymbol
+*/
+func (bybit *BybitExchange) ValidatePerpOrder(params PlacePerpOrderParams) (validParams PlacePerpOrderParams, err error) {
+	return bybit.ValidatePerpOrderCtx(context.Background(), params)
+}
+
+// Same as ValidatePerpOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) ValidatePerpOrderCtx(ctx context.Context, params PlacePerpOrderParams) (validParams PlacePerpOrderParams, err error) {
+	instrument, err := bybit.instruments.BySymbol(ctx, exchange.MARKET_TYPE_PERP, params.Symbol)
+	if err != nil {
+		return params, err
+	}
+	validator := bybit.orderValidator(instrument, params.Side)
+
+	if err = validator.checkTrading(); err != nil {
+		return params, err
+	}
+	if !strings.EqualFold(params.OrderType, PLACE_PERP_MARKET) {
+		if params.Price, err = validator.price("price", params.Price); err != nil {
+			return params, err
+		}
+	}
+	if params.Qty, err = validator.qty(params.Qty); err != nil {
+		return params, err
+	}
+	if err = validator.positionIdx(params.PositionIdx, params.ReduceOnly); err != nil {
+		return params, err
+	}
+
+	// take profit and stop loss sit on both sides of the entry, so passive has no direction
+	validator.sideless = true
+	if params.TakeProfit, err = validator.price("take_profit", params.TakeProfit); err != nil {
+		return params, err
+	}
+	params.StopLoss, err = validator.price("stop_loss", params.StopLoss)
+	return params, err
+}
+
+/*
+	Checks a conditional perp order against the contract's filters without
+	sending it, rounding its prices and quantity like ValidatePerpOrder.
+
+	Requires:
+		params PlaceConditionalOrderParams
+
+	Returns:
+		validParams PlaceConditionalOrderParams - params with the prices and quantity rounded
+		err error - a *ValidationError when the order breaks a filter, wraps ErrReduceOnlyViolation when a reduce-only order would add to its hedge leg
+
+	Ref: https://bybit-exchange.github.io/docs/futuresV2/inverse/#t-querysymbol
+*/
+func (bybit *BybitExchange) ValidateConditionalOrder(params PlaceConditionalOrderParams) (validParams PlaceConditionalOrderParams, err error) {
+	return bybit.ValidateConditionalOrderCtx(context.Background(), params)
+}
+
+// Same as ValidateConditionalOrder, cancelled or timed out through ctx
+func (bybit *BybitExchange) ValidateConditionalOrderCtx(ctx context.Context, params PlaceConditionalOrderParams) (validParams PlaceConditionalOrderParams, err error) {
+	perpParams, err := bybit.ValidatePerpOrderCtx(ctx, PlacePerpOrderParams{
+		Side:        params.Side,
+		Symbol:      params.Symbol,
+		OrderType:   params.OrderType,
+		Qty:         params.Qty,
+		Price:       params.Price,
+		TakeProfit:  params.TakeProfit,
+		StopLoss:    params.StopLoss,
+		ReduceOnly:  params.ReduceOnly,
+		PositionIdx: params.PositionIdx,
+	})
+	if err != nil {
+		return params, err
+	}
+	params.Qty, params.Price = perpParams.Qty, perpParams.Price
+	params.TakeProfit, params.StopLoss = perpParams.TakeProfit, perpParams.StopLoss
+
+	instrument, err := bybit.instruments.BySymbol(ctx, exchange.MARKET_TYPE_PERP, params.Symbol)
+	if err != nil {
+		return params, err
+	}
+	validator := bybit.orderValidator(instrument, params.Side)
+	// the trigger can sit on either side of the market, so passive has no direction
+	validator.sideless = true
+	if !params.StopPx.IsPositive() {
+		return params, validator.invalid("stop_px", params.StopPx, "is not positive")
+	}
+	params.StopPx, err = validator.price("stop_px", params.StopPx)
+	return params, err
+}
+
+// ---------------------------- HELPERS ----------------------------
+
+// rounds the fields an amend changes, the side isn't known so passive rounds to nearest
+func (bybit *BybitExchange) validatePerpAmend(ctx context.Context, params AmendPerpOrderParams) (validParams AmendPerpOrderParams, err error) {
+	instrument, err := bybit.instruments.BySymbol(ctx, exchange.MARKET_TYPE_PERP, params.Symbol)
+	if err != nil {
+		return params, err
+	}
+	validator := bybit.orderValidator(instrument, "")
+	validator.sideless = true
+
+	if err = validator.checkTrading(); err != nil {
+		return params, err
+	}
+	if !params.Qty.IsZero() {
+		if params.Qty, err = validator.qty(params.Qty); err != nil {
+			return params, err
+		}
+	}
+	if params.Price, err = validator.price("price", params.Price); err != nil {
+		return params, err
+	}
+	if params.TakeProfit, err = validator.price("take_profit", params.TakeProfit); err != nil {
+		return params, err
+	}
+	params.StopLoss, err = validator.price("stop_loss", params.StopLoss)
+	return params, err
+}
+
+type orderValidator struct {
+	instrument Instrument
+	validation OrderValidation
+	isBuy      bool
+	sideless   bool
+}
+
+func (bybit *BybitExchange) orderValidator(instrument Instrument, side string) *orderValidator {
+	validator := &orderValidator{
+		instrument: instrument,
+		isBuy:      strings.EqualFold(side, ORDER_SIDE_BUY),
+	}
+	if bybit.orderValidation != nil {
+		validator.validation = *bybit.orderValidation
+	}
+	return validator
+}
+
+func (validator *orderValidator) invalid(field string, value decimal.Decimal, reason string, args ...interface{}) error {
+	return &ValidationError{
+		Symbol: validator.instrument.Symbol,
+		Field:  field,
+		Value:  value,
+		Reason: fmt.Sprintf(reason, args...),
+	}
+}
+
+func (validator *orderValidator) checkTrading() error {
+	if !validator.instrument.IsTrading() {
+		return validator.invalid("symbol", decimal.Zero, "is not trading (status %q)", validator.instrument.Status)
+	}
+	return nil
+}
+
+// checks the hedge mode leg, a reduce-only order has to trade against it
+func (validator *orderValidator) positionIdx(positionIdx int, reduceOnly bool) error {
+	switch positionIdx {
+	case POSITION_IDX_ONE_WAY:
+		return nil
+	case POSITION_IDX_HEDGE_BUY, POSITION_IDX_HEDGE_SELL:
+		if reduceOnly {
+			return checkReduceOnlyLeg(validator.instrument.Symbol, validator.isBuy, positionIdx)
+		}
+		return nil
+	}
+	return validator.invalid("position_idx", decimal.NewFromInt(int64(positionIdx)), "is not one-way (%v) or a hedge leg (%v, %v)", POSITION_IDX_ONE_WAY, POSITION_IDX_HEDGE_BUY, POSITION_IDX_HEDGE_SELL)
+}
+
+// rounds a limit, take profit or stop loss price to the tick size and checks its range, zero means unset
+func (validator *orderValidator) price(field string, price decimal.Decimal) (decimal.Decimal, error) {
+	if price.IsZero() {
+		return price, nil
+	}
+	if price.IsNegative() {
+		return price, validator.invalid(field, price, "is negative")
+	}
+
+	instrument := validator.instrument
+	mode := validator.validation.PriceRounding
+	if mode == ROUNDING_PASSIVE {
+		switch {
+		case validator.sideless:
+			mode = ROUNDING_NEAREST
+		case validator.isBuy:
+			mode = ROUNDING_DOWN
+		default:
+			mode = ROUNDING_UP
+		}
+	}
+	rounded, ok := roundToStep(price, instrument.TickSize, mode)
+	if !ok {
+		return price, validator.invalid(field, price, "is not a multiple of tick size %v", instrument.TickSize)
+	}
+
+	if instrument.MinPrice.IsPositive() && rounded.LessThan(instrument.MinPrice) {
+		return price, validator.invalid(field, price, "is below minimum price %v", instrument.MinPrice)
+	}
+	if instrument.MaxPrice.IsPositive() && rounded.GreaterThan(instrument.MaxPrice) {
+		return price, validator.invalid(field, price, "is above maximum price %v", instrument.MaxPrice)
+	}
+	return rounded, nil
+}
+
+// rounds a quantity to the lot size and checks its range
+func (validator *orderValidator) qty(qty decimal.Decimal) (decimal.Decimal, error) {
+	if !qty.IsPositive() {
+		return qty, validator.invalid("qty", qty, "is not positive")
+	}
+
+	instrument := validator.instrument
+	mode := validator.validation.QtyRounding
+	if mode == ROUNDING_PASSIVE {
+		// never trade more than asked for
+		mode = ROUNDING_DOWN
+	}
+	rounded, ok := roundToStep(qty, instrument.QtyStep, mode)
+	if !ok {
+		return qty, validator.invalid("qty", qty, "is not a multiple of lot size %v", instrument.QtyStep)
+	}
+
+	if !rounded.IsPositive() || (instrument.MinQty.IsPositive() && rounded.LessThan(instrument.MinQty)) {
+		return qty, validator.invalid("qty", qty, "is below minimum quantity %v", instrument.MinQty)
+	}
+	if instrument.MaxQty.IsPositive() && rounded.GreaterThan(instrument.MaxQty) {
+		return qty, validator.invalid("qty", qty, "is above maximum quantity %v", instrument.MaxQty)
+	}
+	return rounded, nil
+}
+
+// checks an order value in the quote coin against the notional range
+func (validator *orderValidator) checkNotional(notional decimal.Decimal) error {
+	instrument := validator.instrument
+	if !notional.IsPositive() || (instrument.MinNotional.IsPositive() && notional.LessThan(instrument.MinNotional)) {
+		return validator.invalid("notional", notional, "is below minimum order value %v", instrument.MinNotional)
+	}
+	if instrument.MaxNotional.IsPositive() && notional.GreaterThan(instrument.MaxNotional) {
+		return validator.invalid("notional", notional, "is above maximum order value %v", instrument.MaxNotional)
+	}
+	return nil
+}
+
+// returns value on the step grid, false when it's off the grid and mode rejects it
+func roundToStep(value, step decimal.Decimal, mode RoundingMode) (decimal.Decimal, bool) {
+	if !step.IsPositive() || value.Mod(step).IsZero() {
+		return value, true
+	}
+
+	steps := value.Div(step)
+	switch mode {
+	case ROUNDING_DOWN:
+		steps = steps.Floor()
+	case ROUNDING_UP:
+		steps = steps.Ceil()
+	case ROUNDING_NEAREST:
+		steps = steps.Round(0)
+	default:
+		return value, false
+	}
+	return steps.Mul(step), true
+}
+
+// checks a reduce-only order trades against its hedge leg, sells reduce the long leg and buys the short one.
+// Only the direction is checked, the unified client's PlacePerpOrder also reads the leg's size.
+func checkReduceOnlyLeg(symbol string, isBuy bool, positionIdx int) error {
+	if (positionIdx == POSITION_IDX_HEDGE_BUY && isBuy) || (positionIdx == POSITION_IDX_HEDGE_SELL && !isBuy) {
+		return fmt.Errorf("bybit: reduce-only %v %v order would add to position_idx %v: %w", symbol, sideName(isBuy), positionIdx, ErrReduceOnlyViolation)
+	}
+	return nil
+}
+
+func sideName(isBuy bool) string {
+	if isBuy {
+		return ORDER_SIDE_BUY
+	}
+	return ORDER_SIDE_SELL
+}
@@ -7,11 +7,21 @@ const (
 
 // ORDERS
 const (
-	ORDER_STATUS_FILLED = "FILLED"
-	ORDER_TYPE_MARKET   = "market"
-	ORDER_TYPE_LIMIT    = "limit"
-	ORDER_SIDE_BUY      = "buy"
-	ORDER_SIDE_SELL     = "sell"
+	ORDER_STATUS_NEW              = "NEW"
+	ORDER_STATUS_PARTIALLY_FILLED = "PARTIALLY_FILLED"
+	ORDER_STATUS_FILLED           = "FILLED"
+	ORDER_STATUS_CANCELED         = "CANCELED"
+	ORDER_STATUS_REJECTED         = "REJECTED"
+	ORDER_TYPE_MARKET             = "market"
+	ORDER_TYPE_LIMIT              = "limit"
+	ORDER_SIDE_BUY                = "buy"
+	ORDER_SIDE_SELL               = "sell"
+)
+
+// POSITIONS
+const (
+	POSITION_SIDE_LONG  = "long"
+	POSITION_SIDE_SHORT = "short"
 )
 
 // MARKETS
@@ -3,6 +3,8 @@
 */
 package exchange
 
+import "context"
+
 type ExchangeRestClient interface {
 
 	/*
@@ -83,9 +85,9 @@ type ExchangeRestClient interface {
 				BaseCurrency string - base currency symbol
 				QuoteCurrency string - quote currency symbol
 				Side string - SELL or BUY
-				Price string - decimal price of base currency - required for all LIMIT orders
+				Price decimal.Decimal - price of base currency - required for all LIMIT orders
 				Type string - Market or Limit order
-				Size float64 - decimal quantity of base currency to buy or sell
+				Size decimal.Decimal - quantity of base currency to buy or sell
 				ReduceOnly bool - reduce only order
 				Ioc        bool -  immediate or cancel
 				PostOnly   bool - postonly order
@@ -103,9 +105,10 @@ type ExchangeRestClient interface {
 				BaseCurrency string - base currency symbol
 				QuoteCurrency string - quote currency symbol
 				Side string - SELL or BUY
-				Price string - decimal price of base currency - required for all LIMIT orders
+				Price decimal.Decimal - price of base currency - required for all LIMIT orders
 				Type string - Market or Limit order
-				Size float64 - decimal quantity of base currency to buy or sell
+				Size decimal.Decimal - quantity of base currency to buy or sell
+				PositionSide string - hedge mode only, POSITION_SIDE_LONG or POSITION_SIDE_SHORT, the leg the order opens or reduces
 				ReduceOnly bool - reduce only order
 				Ioc        bool -  immediate or cancel
 				PostOnly   bool - postonly order
@@ -142,3 +145,33 @@ type ExchangeRestClient interface {
 	*/
 	WithdrawFromExchange(symbol string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error)
 }
+
+/*
+	Context-aware Exchange Rest Api interface. Every call is cancelled or
+	timed out through ctx, including signing and the http round trip.
+*/
+type ExchangeRestClientCtx interface {
+	ExchangeRestClient
+
+	GetSpotBalanceCtx(ctx context.Context, symbol string) (balance float64, err error)
+
+	GetTotalAcctUsdValueCtx(ctx context.Context) (float64, error)
+
+	GetOrderCtx(ctx context.Context, orderRequest GetOrderParams) (Order, error)
+
+	GetPerpPositionCtx(ctx context.Context, symbol string) (Position, error)
+
+	GetPerpMktCollateralUSDCtx(ctx context.Context, symbol string) (float64, error)
+
+	GetDepositAddressCtx(ctx context.Context, symbol, network string) (address string, err error)
+
+	GetMarketPriceCtx(ctx context.Context, baseCurr, quoteCurr, marketType string) (float64, error)
+
+	PlaceSpotOrderCtx(ctx context.Context, params PlaceOrderParams) (orderId string, err error)
+
+	PlacePerpOrderCtx(ctx context.Context, params PlaceOrderParams) (orderId string, err error)
+
+	CancelOrderCtx(ctx context.Context, params CancelOrderParams) (status bool, err error)
+
+	WithdrawFromExchangeCtx(ctx context.Context, symbol string, amount float64, destinationAddress, network string) (withdrawOrderId string, err error)
+}
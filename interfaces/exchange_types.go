@@ -5,36 +5,39 @@ import (
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/shopspring/decimal"
 )
 
 // -------------------------- FUNCTION PARAMS --------------------------
 type GetOrderParams struct {
-	OrderId       int64
-	BaseCurrency  string
-	QuoteCurrency string
-	MarketType    string
+	OrderId         int64
+	ExchangeOrderId string // for exchanges with non-numeric order ids, takes precedence over OrderId
+	BaseCurrency    string
+	QuoteCurrency   string
+	MarketType      string
 }
 
 type CancelOrderParams struct {
-	OrderId       int64
-	BaseCurrency  string
-	QuoteCurrency string
-	MarketType    string
+	OrderId         int64
+	ExchangeOrderId string // for exchanges with non-numeric order ids, takes precedence over OrderId
+	BaseCurrency    string
+	QuoteCurrency   string
+	MarketType      string
 }
 
 type PlaceOrderParams struct {
-	BaseCurrency  string  `json:"baseCurrency"`
-	QuoteCurrency string  `json:"quoteCurrency"`
-	Market        string  `json:"market"`
-	OrderSide     string  `json:"orderSide"`
-	PositionSide  string  `json:"positionSide"`
-	Price         float64 `json:"price"`
-	OrderType     string  `json:"type"`
-	Size          float64 `json:"size"`
-	ReduceOnly    bool    `json:"reduceOnly"`
-	Ioc           bool    `json:"ioc"`
-	PostOnly      bool    `json:"postOnly"`
-	ClientId      *string `json:"clientId"`
+	BaseCurrency  string          `json:"baseCurrency"`
+	QuoteCurrency string          `json:"quoteCurrency"`
+	Market        string          `json:"market"`
+	OrderSide     string          `json:"orderSide"`
+	PositionSide  string          `json:"positionSide"`
+	Price         decimal.Decimal `json:"price"`
+	OrderType     string          `json:"type"`
+	Size          decimal.Decimal `json:"size"`
+	ReduceOnly    bool            `json:"reduceOnly"`
+	Ioc           bool            `json:"ioc"`
+	PostOnly      bool            `json:"postOnly"`
+	ClientId      *string         `json:"clientId"`
 }
 
 // -------------------------- REST API --------------------------
@@ -116,9 +119,9 @@ type Fill struct {
 
 	Time time.Time `json:"time"`
 
-	ID      int `json:"id"`
-	OrderID int `json:"orderId"`
-	TradeID int `json:"tradeId"`
+	ID      string `json:"id"`
+	OrderID string `json:"orderId"`
+	TradeID string `json:"tradeId"`
 }
 
 // POSITIONS
@@ -156,8 +159,11 @@ type ResponseForPositionsAll struct {
 
 // MARKETS
 type Orderbook struct {
-	Asks [][]float64 `json:"asks"`
-	Bids [][]float64 `json:"bids"`
+	Asks [][]float64 `json:"asks"` // [price, size], best (lowest) first
+	Bids [][]float64 `json:"bids"` // [price, size], best (highest) first
+
+	Time     time.Time `json:"time"`
+	UpdateId int64     `json:"updateId"` // zero when the exchange doesn't number its snapshots
 }
 type ResponseForMarketDepth struct {
 	Result Orderbook `json:"result,omitempty"`
@@ -179,11 +185,12 @@ type Order struct {
 	RemainingSize float64 `json:"remainingSize"`
 	FilledSize    float64 `json:"filledSize"`
 
-	ID          int  `json:"id"`
-	Ioc         bool `json:"ioc"`
-	PostOnly    bool `json:"postOnly"`
-	ReduceOnly  bool `json:"reduceOnly"`
-	Liquidation bool `json:"liquidation"`
+	ID              int    `json:"id"`
+	ExchangeOrderId string `json:"exchangeOrderId,omitempty"`
+	Ioc             bool   `json:"ioc"`
+	PostOnly        bool   `json:"postOnly"`
+	ReduceOnly      bool   `json:"reduceOnly"`
+	Liquidation     bool   `json:"liquidation"`
 
 	CreatedAt time.Time `json:"createdAt"`
 }
@@ -195,7 +202,7 @@ type ResponseForOrder struct {
 
 // TRADES
 type Trade struct {
-	ID          int64     `json:"id"`
+	ID          string    `json:"id"`
 	Liquidation bool      `json:"liquidation"`
 	Price       float64   `json:"price"`
 	Side        string    `json:"side"`